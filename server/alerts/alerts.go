@@ -0,0 +1,282 @@
+package alerts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/saqibroy/web-crawler-dashboard/server/models"
+	"github.com/saqibroy/web-crawler-dashboard/server/repository"
+)
+
+// Delivery statuses of an alert.
+const (
+	DeliveryPending = "pending"
+	DeliverySent    = "sent"
+	DeliveryFailed  = "failed"
+)
+
+// maxDeliveries caps the alerts delivered at once.
+const maxDeliveries = 4
+
+// Notifier delivers a new alert through its rule's channel.
+type Notifier interface {
+	Notify(ctx context.Context, rule *models.AlertRule, alert *models.Alert) error
+}
+
+// Engine evaluates alert rules against finished analyses. New alerts are
+// delivered in the background, so a slow mail server or webhook holds up
+// neither the worker nor other evaluations.
+type Engine struct {
+	store    repository.AlertRepository
+	notifier Notifier
+
+	// mu serialises evaluations, so two analyses of one URL finishing
+	// together can't both open an alert.
+	mu sync.Mutex
+
+	deliveries chan struct{}
+	pending    sync.WaitGroup
+}
+
+func NewEngine(store repository.AlertRepository, notifier Notifier) *Engine {
+	return &Engine{store: store, notifier: notifier, deliveries: make(chan struct{}, maxDeliveries)}
+}
+
+// delivery is a new alert waiting to be sent through its rule's channel.
+type delivery struct {
+	rule  models.AlertRule
+	alert models.Alert
+}
+
+// outcome is what a rule makes of an analysis.
+type outcome struct {
+	// known is false when the analysis says nothing about the condition,
+	// such as a failed analysis about broken links, leaving any open alert
+	// as it is.
+	known  bool
+	firing bool
+	// message describes the problem, and fingerprint tells it apart from
+	// others the rule raises for the URL.
+	message     string
+	fingerprint string
+}
+
+// Evaluate checks every enabled rule in scope of an analysis that completed
+// or failed. A rule whose condition starts holding opens an alert and
+// delivers it; while it keeps holding, the alert only counts occurrences,
+// and once an analysis finds it cleared, the alert is resolved.
+func (e *Engine) Evaluate(ctx context.Context, a *models.Analysis) error {
+	if a.Status != models.Completed && a.Status != models.Failed {
+		return nil
+	}
+	rules, err := e.store.ListRules(ctx)
+	if err != nil {
+		return fmt.Errorf("load alert rules: %w", err)
+	}
+
+	var deliveries []delivery
+	var errs []error
+	e.mu.Lock()
+	for i := range rules {
+		rule := &rules[i]
+		if !rule.Enabled || !applies(rule, a) {
+			continue
+		}
+		if err := e.evaluate(ctx, rule, a, &deliveries); err != nil {
+			errs = append(errs, fmt.Errorf("alert rule %s: %w", rule.ID, err))
+		}
+	}
+	e.mu.Unlock()
+
+	for _, d := range deliveries {
+		e.pending.Add(1)
+		go e.deliver(d)
+	}
+	return errors.Join(errs...)
+}
+
+// Wait blocks until the alerts raised so far are delivered.
+func (e *Engine) Wait() {
+	e.pending.Wait()
+}
+
+func (e *Engine) evaluate(ctx context.Context, rule *models.AlertRule, a *models.Analysis, deliveries *[]delivery) error {
+	state, err := e.store.State(ctx, rule.ID, a.URL)
+	if err != nil {
+		return err
+	}
+	result := check(rule, a, state)
+	if !result.known {
+		return nil
+	}
+
+	var open *models.Alert
+	if state.AlertID != nil {
+		open, err = e.store.GetAlert(ctx, *state.AlertID)
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return err
+		}
+	}
+
+	now := time.Now()
+	switch {
+	case result.firing && open != nil && open.Fingerprint == result.fingerprint:
+		open.Occurrences++
+		open.LastSeenAt = now
+		open.AnalysisID = a.ID
+		if err := e.store.SaveAlert(ctx, open); err != nil {
+			return err
+		}
+	case result.firing:
+		if open != nil {
+			if err := e.resolve(ctx, open, now); err != nil {
+				return err
+			}
+		}
+		alert, err := e.raise(ctx, rule, a, result, now)
+		if err != nil {
+			return err
+		}
+		*deliveries = append(*deliveries, delivery{rule: *rule, alert: *alert})
+		state.AlertID = &alert.ID
+	case open != nil:
+		if err := e.resolve(ctx, open, now); err != nil {
+			return err
+		}
+		state.AlertID = nil
+	default:
+		state.AlertID = nil
+	}
+	return e.store.SaveState(ctx, state)
+}
+
+// raise stores a new alert, pending delivery.
+func (e *Engine) raise(ctx context.Context, rule *models.AlertRule, a *models.Analysis, result outcome, now time.Time) (*models.Alert, error) {
+	alert := &models.Alert{
+		RuleID:         rule.ID,
+		RuleName:       rule.Name,
+		AnalysisID:     a.ID,
+		URL:            a.URL,
+		Domain:         a.Domain,
+		Type:           rule.Type,
+		Message:        result.message,
+		Fingerprint:    result.fingerprint,
+		Occurrences:    1,
+		Channel:        rule.Channel,
+		DeliveryStatus: DeliveryPending,
+		CreatedAt:      now,
+		LastSeenAt:     now,
+	}
+	if err := e.store.CreateAlert(ctx, alert); err != nil {
+		return nil, err
+	}
+	return alert, nil
+}
+
+// deliver sends an alert, a few at a time, and records whether it worked.
+func (e *Engine) deliver(d delivery) {
+	defer e.pending.Done()
+	e.deliveries <- struct{}{}
+	defer func() { <-e.deliveries }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), deliveryTimeout)
+	defer cancel()
+	status, message := DeliverySent, ""
+	if err := e.notifier.Notify(ctx, &d.rule, &d.alert); err != nil {
+		status, message = DeliveryFailed, err.Error()
+	}
+
+	// The alert may have seen more occurrences since it was raised
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	alert, err := e.store.GetAlert(context.Background(), d.alert.ID)
+	if err == nil {
+		alert.DeliveryStatus = status
+		alert.DeliveryError = message
+		err = e.store.SaveAlert(context.Background(), alert)
+	}
+	if err != nil {
+		log.Printf("Failed to record delivery of alert %d: %v", d.alert.ID, err)
+	}
+}
+
+func (e *Engine) resolve(ctx context.Context, alert *models.Alert, now time.Time) error {
+	alert.ResolvedAt = &now
+	return e.store.SaveAlert(ctx, alert)
+}
+
+// check evaluates a rule's condition, updating what the state remembers
+// about the URL.
+func check(rule *models.AlertRule, a *models.Analysis, state *models.AlertState) outcome {
+	completed := a.Status == models.Completed
+
+	switch rule.Type {
+	case models.AlertBrokenLinks:
+		if !completed {
+			return outcome{}
+		}
+		return outcome{
+			known:   true,
+			firing:  a.BrokenLinkCount > rule.Threshold,
+			message: fmt.Sprintf("%s has %d broken links, more than %d", a.URL, a.BrokenLinkCount, rule.Threshold),
+		}
+
+	case models.AlertConsecutiveFailures:
+		if completed {
+			state.Failures = 0
+			return outcome{known: true}
+		}
+		state.Failures++
+		message := fmt.Sprintf("%s failed %d times in a row", a.URL, state.Failures)
+		if a.FailureReason != "" {
+			message += " (" + a.FailureReason + ")"
+		}
+		return outcome{known: true, firing: state.Failures >= rule.Threshold, message: message}
+
+	case models.AlertTitleChanged:
+		if !completed {
+			return outcome{}
+		}
+		previous := state.LastTitle
+		title := a.Title
+		state.LastTitle = &title
+		// The first title is the baseline for later ones
+		if previous == nil || *previous == title {
+			return outcome{known: true}
+		}
+		return outcome{
+			known:       true,
+			firing:      true,
+			message:     fmt.Sprintf("The title of %s changed from %q to %q", a.URL, *previous, title),
+			fingerprint: truncate(title, 255),
+		}
+
+	case models.AlertCertExpiry:
+		tls := a.Security.TLS
+		if !completed || tls == nil || len(tls.Chain) == 0 {
+			return outcome{}
+		}
+		expires := tls.Chain[0].NotAfter
+		return outcome{
+			known:  true,
+			firing: tls.DaysUntilExpiry < rule.Threshold,
+			message: fmt.Sprintf("The TLS certificate of %s expires in %d days, on %s",
+				a.URL, tls.DaysUntilExpiry, expires.UTC().Format("2006-01-02")),
+			// A renewed certificate that expires soon too is a new problem
+			fingerprint: expires.UTC().Format(time.RFC3339),
+		}
+	}
+	return outcome{}
+}
+
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
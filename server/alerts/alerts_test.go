@@ -0,0 +1,237 @@
+package alerts
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/saqibroy/web-crawler-dashboard/server/models"
+	"github.com/saqibroy/web-crawler-dashboard/server/repository"
+)
+
+// recorder collects the alerts it's asked to deliver, failing when err is
+// set, and waiting for release to be closed when that's set.
+type recorder struct {
+	mu      sync.Mutex
+	alerts  []models.Alert
+	err     error
+	release chan struct{}
+}
+
+func (r *recorder) Notify(ctx context.Context, rule *models.AlertRule, alert *models.Alert) error {
+	if r.release != nil {
+		<-r.release
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.alerts = append(r.alerts, *alert)
+	return r.err
+}
+
+func newEngine(t *testing.T, rules ...*models.AlertRule) (*Engine, *repository.MemoryAlertRepository, *recorder) {
+	t.Helper()
+	store := repository.NewMemoryAlertRepository()
+	for _, rule := range rules {
+		rule.Channel = models.AlertChannelWebhook
+		rule.Destination = "https://hooks.example.com"
+		rule.Enabled = true
+		if err := ValidateRule(rule); err != nil {
+			t.Fatalf("invalid rule: %v", err)
+		}
+		if err := store.CreateRule(context.Background(), rule); err != nil {
+			t.Fatal(err)
+		}
+	}
+	notifier := &recorder{}
+	return NewEngine(store, notifier), store, notifier
+}
+
+func completed(url string, broken int) *models.Analysis {
+	return &models.Analysis{ID: "a-" + url, URL: url, Domain: models.URLDomain(url), Status: models.Completed, BrokenLinkCount: broken}
+}
+
+func failed(url string) *models.Analysis {
+	return &models.Analysis{ID: "a-" + url, URL: url, Domain: models.URLDomain(url), Status: models.Failed, FailureReason: "timeout"}
+}
+
+func evaluate(t *testing.T, e *Engine, analyses ...*models.Analysis) {
+	t.Helper()
+	for _, a := range analyses {
+		if err := e.Evaluate(context.Background(), a); err != nil {
+			t.Fatalf("evaluate: %v", err)
+		}
+		e.Wait()
+	}
+}
+
+func TestBrokenLinksDeduplicated(t *testing.T) {
+	rule := &models.AlertRule{Name: "broken", Scope: models.AlertScopeDomain, Target: "Example.com", Type: models.AlertBrokenLinks, Threshold: 5}
+	e, store, notifier := newEngine(t, rule)
+	ctx := context.Background()
+
+	evaluate(t, e,
+		completed("https://example.com", 3),
+		completed("https://example.com", 6),
+		completed("https://example.com", 8),
+		// Failures say nothing about broken links
+		failed("https://example.com"),
+		// Out of scope
+		completed("https://blog.example.com", 50),
+	)
+	if len(notifier.alerts) != 1 || notifier.alerts[0].Message != "https://example.com has 6 broken links, more than 5" {
+		t.Fatalf("expected one delivery, got %+v", notifier.alerts)
+	}
+	list, _, _ := store.ListAlerts(ctx, repository.AlertFilter{})
+	if len(list) != 1 || list[0].Occurrences != 2 || list[0].ResolvedAt != nil || list[0].DeliveryStatus != DeliverySent {
+		t.Fatalf("unexpected alerts: %+v", list)
+	}
+
+	// Clearing resolves the alert, and a relapse raises a new one
+	evaluate(t, e, completed("https://example.com", 0), completed("https://example.com", 9))
+	list, _, _ = store.ListAlerts(ctx, repository.AlertFilter{})
+	if len(list) != 2 || list[1].ResolvedAt == nil || list[0].ResolvedAt != nil || len(notifier.alerts) != 2 {
+		t.Errorf("unexpected alerts: %+v", list)
+	}
+}
+
+func TestConsecutiveFailures(t *testing.T) {
+	rule := &models.AlertRule{Name: "down", Scope: models.AlertScopeURL, Target: "https://example.com", Type: models.AlertConsecutiveFailures}
+	e, _, notifier := newEngine(t, rule)
+	if rule.Threshold != defaultFailures {
+		t.Fatalf("expected the default threshold, got %d", rule.Threshold)
+	}
+
+	evaluate(t, e, failed("https://example.com"), completed("https://example.com", 0), failed("https://example.com"))
+	if len(notifier.alerts) != 0 {
+		t.Fatalf("a completed analysis should reset the count, got %+v", notifier.alerts)
+	}
+	evaluate(t, e, failed("https://example.com"), failed("https://example.com"))
+	if len(notifier.alerts) != 1 || notifier.alerts[0].Message != "https://example.com failed 2 times in a row (timeout)" {
+		t.Errorf("unexpected alerts: %+v", notifier.alerts)
+	}
+}
+
+func TestTitleChanged(t *testing.T) {
+	rule := &models.AlertRule{Name: "title", Scope: models.AlertScopeAll, Type: models.AlertTitleChanged}
+	e, store, notifier := newEngine(t, rule)
+	titled := func(title string) *models.Analysis {
+		a := completed("https://example.com", 0)
+		a.Title = title
+		return a
+	}
+
+	evaluate(t, e, titled("Home"), titled("Home"), titled("Welcome"), titled("Hello"), titled("Hello"))
+	if len(notifier.alerts) != 2 || notifier.alerts[1].Message != `The title of https://example.com changed from "Welcome" to "Hello"` {
+		t.Fatalf("expected an alert per change, got %+v", notifier.alerts)
+	}
+	open := true
+	if list, _, _ := store.ListAlerts(context.Background(), repository.AlertFilter{Open: &open}); len(list) != 0 {
+		t.Errorf("expected every alert to be resolved, got %+v", list)
+	}
+}
+
+func TestCertExpiry(t *testing.T) {
+	rule := &models.AlertRule{Name: "cert", Scope: models.AlertScopeAll, Type: models.AlertCertExpiry}
+	e, _, notifier := newEngine(t, rule)
+	withCert := func(days int) *models.Analysis {
+		a := completed("https://example.com", 0)
+		notAfter := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC).AddDate(0, 0, days)
+		a.Security.TLS = &models.TLSInfo{DaysUntilExpiry: days, Chain: []models.CertificateInfo{{NotAfter: notAfter}}}
+		return a
+	}
+
+	evaluate(t, e, completed("http://example.com", 0), withCert(30), withCert(10), withCert(9), withCert(5))
+	if len(notifier.alerts) != 3 {
+		t.Fatalf("expected an alert per expiring certificate, got %+v", notifier.alerts)
+	}
+	if want := "The TLS certificate of https://example.com expires in 10 days, on 2025-03-11"; notifier.alerts[0].Message != want {
+		t.Errorf("got %q, want %q", notifier.alerts[0].Message, want)
+	}
+}
+
+func TestDeliveryFailureRecorded(t *testing.T) {
+	rule := &models.AlertRule{Name: "broken", Scope: models.AlertScopeAll, Type: models.AlertBrokenLinks}
+	e, store, notifier := newEngine(t, rule)
+	notifier.err = errors.New("connection refused")
+
+	evaluate(t, e, completed("https://example.com", 1))
+	list, _, _ := store.ListAlerts(context.Background(), repository.AlertFilter{})
+	if len(list) != 1 || list[0].DeliveryStatus != DeliveryFailed || list[0].DeliveryError != "connection refused" {
+		t.Errorf("unexpected alerts: %+v", list)
+	}
+}
+
+func TestDeliveryDoesNotBlockEvaluation(t *testing.T) {
+	rule := &models.AlertRule{Name: "broken", Scope: models.AlertScopeAll, Type: models.AlertBrokenLinks}
+	e, store, notifier := newEngine(t, rule)
+	notifier.release = make(chan struct{})
+	ctx := context.Background()
+
+	// The second evaluation would wait for the lock if the first held it
+	// while delivering
+	for _, a := range []*models.Analysis{completed("https://example.com", 1), completed("https://example.com", 2)} {
+		if err := e.Evaluate(ctx, a); err != nil {
+			t.Fatal(err)
+		}
+	}
+	list, _, _ := store.ListAlerts(ctx, repository.AlertFilter{})
+	if len(list) != 1 || list[0].DeliveryStatus != DeliveryPending {
+		t.Fatalf("expected a pending alert, got %+v", list)
+	}
+
+	close(notifier.release)
+	e.Wait()
+	list, _, _ = store.ListAlerts(ctx, repository.AlertFilter{})
+	if list[0].DeliveryStatus != DeliverySent || list[0].Occurrences != 2 {
+		t.Errorf("expected the delivery recorded without losing occurrences, got %+v", list[0])
+	}
+}
+
+func TestDisabledRulesSkipped(t *testing.T) {
+	rule := &models.AlertRule{Name: "broken", Scope: models.AlertScopeAll, Type: models.AlertBrokenLinks}
+	e, store, notifier := newEngine(t, rule)
+	rule.Enabled = false
+	if err := store.SaveRule(context.Background(), rule); err != nil {
+		t.Fatal(err)
+	}
+
+	evaluate(t, e, completed("https://example.com", 1))
+	if len(notifier.alerts) != 0 {
+		t.Errorf("expected no alerts, got %+v", notifier.alerts)
+	}
+}
+
+func TestValidateRule(t *testing.T) {
+	valid := func() models.AlertRule {
+		return models.AlertRule{Name: "rule", Scope: models.AlertScopeAll, Type: models.AlertBrokenLinks,
+			Channel: models.AlertChannelEmail, Destination: "ops@example.com, Dev <dev@example.com>"}
+	}
+	rule := valid()
+	if err := ValidateRule(&rule); err != nil {
+		t.Fatalf("expected a valid rule, got %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		change func(*models.AlertRule)
+	}{
+		{"name", func(r *models.AlertRule) { r.Name = " " }},
+		{"scope", func(r *models.AlertRule) { r.Scope = "schedule" }},
+		{"url target", func(r *models.AlertRule) { r.Scope, r.Target = models.AlertScopeURL, "example.com" }},
+		{"domain target", func(r *models.AlertRule) { r.Scope, r.Target = models.AlertScopeDomain, "https://example.com" }},
+		{"type", func(r *models.AlertRule) { r.Type = "status" }},
+		{"threshold", func(r *models.AlertRule) { r.Threshold = -1 }},
+		{"channel", func(r *models.AlertRule) { r.Channel = "sms" }},
+		{"email", func(r *models.AlertRule) { r.Destination = "not an address" }},
+		{"webhook", func(r *models.AlertRule) { r.Channel = models.AlertChannelSlack }},
+	}
+	for _, tt := range tests {
+		rule := valid()
+		tt.change(&rule)
+		if err := ValidateRule(&rule); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
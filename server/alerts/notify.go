@@ -0,0 +1,196 @@
+package alerts
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/mail"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/saqibroy/web-crawler-dashboard/server/models"
+)
+
+const deliveryTimeout = 10 * time.Second
+
+var ErrSMTPNotConfigured = errors.New("SMTP is not configured")
+
+// SMTPConfig is the mail server alerts are emailed through. Username and
+// Password are optional.
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+// Dispatcher delivers alerts by email, to Slack-compatible webhooks, which
+// take a {"text": ...} message, and to generic webhooks, which are sent the
+// alert and its rule as JSON.
+type Dispatcher struct {
+	smtp   SMTPConfig
+	client *http.Client
+	// sendMail is replaced in tests.
+	sendMail func(ctx context.Context, addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+func NewDispatcher(smtpConfig SMTPConfig) *Dispatcher {
+	return &Dispatcher{
+		smtp:     smtpConfig,
+		client:   &http.Client{Timeout: deliveryTimeout},
+		sendMail: sendMail,
+	}
+}
+
+func (d *Dispatcher) Notify(ctx context.Context, rule *models.AlertRule, alert *models.Alert) error {
+	switch rule.Channel {
+	case models.AlertChannelEmail:
+		return d.email(ctx, rule, alert)
+	case models.AlertChannelSlack:
+		return d.post(ctx, rule.Destination, map[string]string{"text": fmt.Sprintf("*%s*: %s", rule.Name, alert.Message)})
+	case models.AlertChannelWebhook:
+		return d.post(ctx, rule.Destination, WebhookPayload{Event: "alert", Alert: alert, Rule: rule})
+	}
+	return fmt.Errorf("unknown channel %q", rule.Channel)
+}
+
+// WebhookPayload is the body generic webhooks receive.
+type WebhookPayload struct {
+	Event string            `json:"event"`
+	Alert *models.Alert     `json:"alert"`
+	Rule  *models.AlertRule `json:"rule"`
+}
+
+func (d *Dispatcher) post(ctx context.Context, target string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (d *Dispatcher) email(ctx context.Context, rule *models.AlertRule, alert *models.Alert) error {
+	if d.smtp.Host == "" || d.smtp.From == "" {
+		return ErrSMTPNotConfigured
+	}
+	addresses, err := mail.ParseAddressList(rule.Destination)
+	if err != nil {
+		return err
+	}
+	to := make([]string, len(addresses))
+	headers := make([]string, len(addresses))
+	for i, address := range addresses {
+		to[i] = address.Address
+		headers[i] = address.String()
+	}
+
+	var auth smtp.Auth
+	if d.smtp.Username != "" {
+		auth = smtp.PlainAuth("", d.smtp.Username, d.smtp.Password, d.smtp.Host)
+	}
+	port := d.smtp.Port
+	if port == "" {
+		port = "587"
+	}
+	return d.sendMail(ctx, net.JoinHostPort(d.smtp.Host, port), auth, d.smtp.From, to, emailMessage(d.smtp.From, headers, rule, alert))
+}
+
+// sendMail is smtp.SendMail with a deadline: dialing and the whole
+// conversation must finish within deliveryTimeout, or ctx's deadline if
+// that's sooner, so a server that stops responding can't hold delivery up.
+func sendMail(ctx context.Context, addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return err
+	}
+	deadline := time.Now().Add(deliveryTimeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	dialer := net.Dialer{Deadline: deadline}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		conn.Close()
+		return err
+	}
+
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+	if a != nil {
+		if ok, _ := c.Extension("AUTH"); !ok {
+			return errors.New("SMTP server doesn't support AUTH")
+		}
+		if err := c.Auth(a); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(from); err != nil {
+		return err
+	}
+	for _, recipient := range to {
+		if err := c.Rcpt(recipient); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+func emailMessage(from string, to []string, rule *models.AlertRule, alert *models.Alert) []byte {
+	subject := mime.QEncoding.Encode("utf-8", fmt.Sprintf("[Alert] %s: %s", rule.Name, alert.URL))
+
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", from)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", subject)
+	fmt.Fprintf(&msg, "Date: %s\r\n", alert.CreatedAt.Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	fmt.Fprintf(&msg, "%s\r\n\r\nRule: %s\r\nAnalysis: %s\r\n", alert.Message, rule.Name, alert.AnalysisID)
+	return []byte(msg.String())
+}
@@ -0,0 +1,107 @@
+package alerts
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/saqibroy/web-crawler-dashboard/server/models"
+)
+
+func TestNotifyWebhooks(t *testing.T) {
+	var bodies []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		bodies = append(bodies, body)
+		if r.URL.Path == "/down" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	d := NewDispatcher(SMTPConfig{})
+	alert := &models.Alert{ID: 7, URL: "https://example.com", Message: "https://example.com has 6 broken links, more than 5"}
+	slack := &models.AlertRule{Name: "broken", Channel: models.AlertChannelSlack, Destination: server.URL}
+	webhook := &models.AlertRule{Name: "broken", Channel: models.AlertChannelWebhook, Destination: server.URL}
+
+	for _, rule := range []*models.AlertRule{slack, webhook} {
+		if err := d.Notify(context.Background(), rule, alert); err != nil {
+			t.Fatalf("notify %s: %v", rule.Channel, err)
+		}
+	}
+	if text := bodies[0]["text"]; text != "*broken*: "+alert.Message {
+		t.Errorf("unexpected Slack message: %v", text)
+	}
+	if bodies[1]["event"] != "alert" || bodies[1]["alert"].(map[string]interface{})["id"] != float64(7) {
+		t.Errorf("unexpected webhook payload: %v", bodies[1])
+	}
+
+	webhook.Destination = server.URL + "/down"
+	if err := d.Notify(context.Background(), webhook, alert); err == nil || !strings.Contains(err.Error(), "503") {
+		t.Errorf("expected the status to fail delivery, got %v", err)
+	}
+}
+
+func TestNotifyEmail(t *testing.T) {
+	rule := &models.AlertRule{Name: "broken", Channel: models.AlertChannelEmail, Destination: "ops@example.com, Dev <dev@example.com>"}
+	alert := &models.Alert{URL: "https://example.com", Message: "https://example.com has 6 broken links, more than 5", CreatedAt: time.Now()}
+
+	if err := NewDispatcher(SMTPConfig{}).Notify(context.Background(), rule, alert); !errors.Is(err, ErrSMTPNotConfigured) {
+		t.Errorf("expected ErrSMTPNotConfigured, got %v", err)
+	}
+
+	d := NewDispatcher(SMTPConfig{Host: "mail.example.com", Username: "user", Password: "secret", From: "alerts@example.com"})
+	var addr string
+	var to []string
+	var msg []byte
+	d.sendMail = func(ctx context.Context, a string, auth smtp.Auth, from string, recipients []string, body []byte) error {
+		addr, to, msg = a, recipients, body
+		return nil
+	}
+	if err := d.Notify(context.Background(), rule, alert); err != nil {
+		t.Fatalf("notify: %v", err)
+	}
+	if addr != "mail.example.com:587" || len(to) != 2 || to[1] != "dev@example.com" {
+		t.Errorf("unexpected delivery to %s: %v", addr, to)
+	}
+	if !strings.Contains(string(msg), "Subject: [Alert] broken: https://example.com\r\n") || !strings.Contains(string(msg), alert.Message) {
+		t.Errorf("unexpected message:\n%s", msg)
+	}
+}
+
+func TestSendMailTimesOut(t *testing.T) {
+	// A server that accepts connections but never greets
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	started := time.Now()
+	err = sendMail(ctx, listener.Addr().String(), nil, "alerts@example.com", []string{"ops@example.com"}, []byte("Subject: test\r\n\r\nbody"))
+	if err == nil {
+		t.Fatal("expected the stalled server to fail delivery")
+	}
+	if elapsed := time.Since(started); elapsed > 2*time.Second {
+		t.Errorf("delivery took %s despite the deadline", elapsed)
+	}
+}
@@ -0,0 +1,97 @@
+package alerts
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"strings"
+
+	"github.com/saqibroy/web-crawler-dashboard/server/models"
+)
+
+// Default thresholds, for rules created without one.
+const (
+	defaultFailures   = 2
+	defaultExpiryDays = 14
+)
+
+var (
+	Types    = []string{models.AlertBrokenLinks, models.AlertConsecutiveFailures, models.AlertTitleChanged, models.AlertCertExpiry}
+	Scopes   = []string{models.AlertScopeURL, models.AlertScopeDomain, models.AlertScopeAll}
+	Channels = []string{models.AlertChannelEmail, models.AlertChannelSlack, models.AlertChannelWebhook}
+)
+
+// ValidateRule checks a rule and normalises it in place: names and targets
+// are trimmed, domains lower-cased and missing thresholds defaulted.
+func ValidateRule(rule *models.AlertRule) error {
+	rule.Name = strings.TrimSpace(rule.Name)
+	if rule.Name == "" {
+		return errors.New("name is required")
+	}
+
+	rule.Target = strings.TrimSpace(rule.Target)
+	switch rule.Scope {
+	case models.AlertScopeURL:
+		if !isHTTPURL(rule.Target) {
+			return errors.New("target must be an http or https URL")
+		}
+	case models.AlertScopeDomain:
+		rule.Target = strings.ToLower(rule.Target)
+		if rule.Target == "" || strings.ContainsAny(rule.Target, "/:?# ") {
+			return errors.New("target must be a domain name, such as example.com")
+		}
+	case models.AlertScopeAll:
+		rule.Target = ""
+	default:
+		return fmt.Errorf("scope must be one of %s", strings.Join(Scopes, ", "))
+	}
+
+	if rule.Threshold < 0 {
+		return errors.New("threshold must not be negative")
+	}
+	switch rule.Type {
+	case models.AlertBrokenLinks, models.AlertTitleChanged:
+	case models.AlertConsecutiveFailures:
+		if rule.Threshold == 0 {
+			rule.Threshold = defaultFailures
+		}
+	case models.AlertCertExpiry:
+		if rule.Threshold == 0 {
+			rule.Threshold = defaultExpiryDays
+		}
+	default:
+		return fmt.Errorf("type must be one of %s", strings.Join(Types, ", "))
+	}
+
+	rule.Destination = strings.TrimSpace(rule.Destination)
+	switch rule.Channel {
+	case models.AlertChannelEmail:
+		if _, err := mail.ParseAddressList(rule.Destination); err != nil {
+			return fmt.Errorf("destination must be a comma-separated list of email addresses: %v", err)
+		}
+	case models.AlertChannelSlack, models.AlertChannelWebhook:
+		if !isHTTPURL(rule.Destination) {
+			return errors.New("destination must be an http or https URL")
+		}
+	default:
+		return fmt.Errorf("channel must be one of %s", strings.Join(Channels, ", "))
+	}
+	return nil
+}
+
+func isHTTPURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// applies reports whether a rule is evaluated for an analysis.
+func applies(rule *models.AlertRule, a *models.Analysis) bool {
+	switch rule.Scope {
+	case models.AlertScopeURL:
+		return a.URL == rule.Target
+	case models.AlertScopeDomain:
+		return a.Domain == rule.Target
+	}
+	return true
+}
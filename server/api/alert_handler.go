@@ -0,0 +1,164 @@
+package api
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/saqibroy/web-crawler-dashboard/server/alerts"
+	"github.com/saqibroy/web-crawler-dashboard/server/models"
+	"github.com/saqibroy/web-crawler-dashboard/server/repository"
+)
+
+const maxAlertLimit = 100
+
+// AlertRuleRequest creates or replaces an alert rule. Rules are enabled
+// unless Enabled is false.
+type AlertRuleRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Scope       string `json:"scope" binding:"required"`
+	Target      string `json:"target"`
+	Type        string `json:"type" binding:"required"`
+	Threshold   int    `json:"threshold"`
+	Channel     string `json:"channel" binding:"required"`
+	Destination string `json:"destination" binding:"required"`
+	Enabled     *bool  `json:"enabled"`
+}
+
+// apply copies the request onto a rule and validates it.
+func (req *AlertRuleRequest) apply(rule *models.AlertRule) error {
+	rule.Name = req.Name
+	rule.Scope = req.Scope
+	rule.Target = req.Target
+	rule.Type = req.Type
+	rule.Threshold = req.Threshold
+	rule.Channel = req.Channel
+	rule.Destination = req.Destination
+	rule.Enabled = req.Enabled == nil || *req.Enabled
+	return alerts.ValidateRule(rule)
+}
+
+func (h *Handler) CreateAlertRule(c *gin.Context) {
+	var req AlertRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errorResponse(c, 400, "invalid_request", "Invalid request format", err.Error())
+		return
+	}
+
+	var rule models.AlertRule
+	if err := req.apply(&rule); err != nil {
+		errorResponse(c, 400, "invalid_rule", err.Error())
+		return
+	}
+	if err := h.alerts.CreateRule(c.Request.Context(), &rule); err != nil {
+		errorResponse(c, 500, "db_create_failed", "Failed to save alert rule", err.Error())
+		return
+	}
+
+	c.JSON(201, rule)
+}
+
+func (h *Handler) GetAlertRules(c *gin.Context) {
+	rules, err := h.alerts.ListRules(c.Request.Context())
+	if err != nil {
+		errorResponse(c, 500, "db_query_failed", "Failed to load alert rules", err.Error())
+		return
+	}
+
+	c.JSON(200, gin.H{"data": rules})
+}
+
+func (h *Handler) GetAlertRule(c *gin.Context) {
+	rule, err := h.alerts.GetRule(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		errorResponse(c, 404, "not_found", "Alert rule not found")
+		return
+	}
+
+	c.JSON(200, rule)
+}
+
+// UpdateAlertRule replaces a rule. Its open alerts stay open until an
+// analysis finds their condition cleared.
+func (h *Handler) UpdateAlertRule(c *gin.Context) {
+	rule, err := h.alerts.GetRule(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		errorResponse(c, 404, "not_found", "Alert rule not found")
+		return
+	}
+
+	var req AlertRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errorResponse(c, 400, "invalid_request", "Invalid request format", err.Error())
+		return
+	}
+	if err := req.apply(rule); err != nil {
+		errorResponse(c, 400, "invalid_rule", err.Error())
+		return
+	}
+	if err := h.alerts.SaveRule(c.Request.Context(), rule); err != nil {
+		errorResponse(c, 500, "db_update_failed", "Failed to save alert rule", err.Error())
+		return
+	}
+
+	c.JSON(200, rule)
+}
+
+// DeleteAlertRule removes a rule, keeping the alerts it raised.
+func (h *Handler) DeleteAlertRule(c *gin.Context) {
+	err := h.alerts.DeleteRule(c.Request.Context(), c.Param("id"))
+	if errors.Is(err, repository.ErrNotFound) {
+		errorResponse(c, 404, "not_found", "Alert rule not found")
+		return
+	} else if err != nil {
+		errorResponse(c, 500, "db_delete_failed", "Failed to delete alert rule", err.Error())
+		return
+	}
+
+	c.JSON(200, gin.H{"deleted": 1})
+}
+
+// GetAlerts lists the alerts rules raised, newest first, optionally for one
+// rule_id, url or domain (excluding its subdomains), and open (true) or
+// resolved (false).
+func (h *Handler) GetAlerts(c *gin.Context) {
+	page, err := parseIntParam(c, "page", 1)
+	if err != nil {
+		errorResponse(c, 400, "invalid_page", "Invalid page number")
+		return
+	}
+
+	limit, err := parseIntParam(c, "limit", 20)
+	if err != nil || limit > maxAlertLimit {
+		errorResponse(c, 400, "invalid_limit", "Limit must be between 1 and 100")
+		return
+	}
+
+	filter := repository.AlertFilter{
+		RuleID: c.Query("rule_id"),
+		URL:    c.Query("url"),
+		Domain: strings.ToLower(c.Query("domain")),
+		Offset: (page - 1) * limit,
+		Limit:  limit,
+	}
+	if value := c.Query("open"); value != "" {
+		open, err := strconv.ParseBool(value)
+		if err != nil {
+			errorResponse(c, 400, "invalid_filter", "open must be true or false")
+			return
+		}
+		filter.Open = &open
+	}
+
+	list, total, err := h.alerts.ListAlerts(c.Request.Context(), filter)
+	if err != nil {
+		errorResponse(c, 500, "db_query_failed", "Failed to query alerts", err.Error())
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"data":        list,
+		"total_count": total,
+	})
+}
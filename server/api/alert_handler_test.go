@@ -0,0 +1,133 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/saqibroy/web-crawler-dashboard/server/alerts"
+	"github.com/saqibroy/web-crawler-dashboard/server/models"
+)
+
+func TestAlertRules(t *testing.T) {
+	s := newTestServer(t)
+
+	w := s.do("POST", "/api/alert-rules", gin.H{
+		"name":        "Down twice",
+		"scope":       "domain",
+		"target":      "Example.COM",
+		"type":        "consecutive_failures",
+		"channel":     "email",
+		"destination": "ops@example.com",
+	})
+	if w.Code != 201 {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body)
+	}
+	var rule models.AlertRule
+	decode(t, w, &rule)
+	if rule.Target != "example.com" || rule.Threshold != 2 || !rule.Enabled {
+		t.Errorf("expected the rule to be normalised, got %+v", rule)
+	}
+
+	w = s.do("PUT", "/api/alert-rules/"+rule.ID, gin.H{
+		"name":        "Down three times",
+		"scope":       "domain",
+		"target":      "example.com",
+		"type":        "consecutive_failures",
+		"threshold":   3,
+		"channel":     "slack",
+		"destination": "https://hooks.slack.com/services/T0/B0/x",
+		"enabled":     false,
+	})
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body)
+	}
+
+	var list struct{ Data []models.AlertRule }
+	decode(t, s.do("GET", "/api/alert-rules", nil), &list)
+	if len(list.Data) != 1 || list.Data[0].Threshold != 3 || list.Data[0].Channel != "slack" || list.Data[0].Enabled {
+		t.Errorf("unexpected rules: %+v", list.Data)
+	}
+
+	if w := s.do("DELETE", "/api/alert-rules/"+rule.ID, nil); w.Code != 200 {
+		t.Errorf("expected 200, got %d", w.Code)
+	}
+	expectError(t, s.do("GET", "/api/alert-rules/"+rule.ID, nil), 404, "not_found")
+	expectError(t, s.do("DELETE", "/api/alert-rules/"+rule.ID, nil), 404, "not_found")
+}
+
+func TestAlertRuleValidation(t *testing.T) {
+	s := newTestServer(t)
+	valid := gin.H{"name": "broken", "scope": "all", "type": "broken_links", "channel": "webhook", "destination": "https://hooks.example.com"}
+	with := func(key string, value interface{}) gin.H {
+		body := gin.H{}
+		for k, v := range valid {
+			body[k] = v
+		}
+		body[key] = value
+		return body
+	}
+
+	expectError(t, s.do("POST", "/api/alert-rules", gin.H{"name": "missing"}), 400, "invalid_request")
+	expectError(t, s.do("POST", "/api/alert-rules", with("scope", "schedule")), 400, "invalid_rule")
+	expectError(t, s.do("POST", "/api/alert-rules", with("type", "status")), 400, "invalid_rule")
+	expectError(t, s.do("POST", "/api/alert-rules", with("destination", "ftp://example.com")), 400, "invalid_rule")
+	expectError(t, s.do("PUT", "/api/alert-rules/nope", valid), 404, "not_found")
+	expectError(t, s.do("GET", "/api/alerts?open=maybe", nil), 400, "invalid_filter")
+	expectError(t, s.do("GET", "/api/alerts?limit=101", nil), 400, "invalid_limit")
+}
+
+func TestAlertsRaisedByAnalyses(t *testing.T) {
+	s := newTestServer(t)
+	target := crawlTarget(t)
+
+	var mu sync.Mutex
+	var delivered []alerts.WebhookPayload
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload alerts.WebhookPayload
+		json.NewDecoder(r.Body).Decode(&payload)
+		mu.Lock()
+		delivered = append(delivered, payload)
+		mu.Unlock()
+	}))
+	defer hook.Close()
+
+	w := s.do("POST", "/api/alert-rules", gin.H{
+		"name":        "Broken links",
+		"scope":       "url",
+		"target":      target.URL,
+		"type":        "broken_links",
+		"channel":     "webhook",
+		"destination": hook.URL,
+	})
+	if w.Code != 201 {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body)
+	}
+
+	// The page has a broken link every time, so only the first analysis
+	// delivers an alert
+	s.submit(gin.H{"url": target.URL})
+	s.submit(gin.H{"url": target.URL})
+	s.submit(gin.H{"url": target.URL + "/about"})
+	s.process()
+
+	if len(delivered) != 1 || delivered[0].Alert.URL != target.URL || delivered[0].Rule.Name != "Broken links" {
+		t.Fatalf("expected one delivery, got %+v", delivered)
+	}
+
+	var history struct {
+		Data       []models.Alert `json:"data"`
+		TotalCount int64          `json:"total_count"`
+	}
+	decode(t, s.do("GET", "/api/alerts?open=true&url="+target.URL, nil), &history)
+	if history.TotalCount != 1 || history.Data[0].Occurrences != 2 || history.Data[0].DeliveryStatus != alerts.DeliverySent {
+		t.Errorf("unexpected alerts: %+v", history)
+	}
+	decode(t, s.do("GET", "/api/alerts?open=false", nil), &history)
+	if history.TotalCount != 0 {
+		t.Errorf("expected no resolved alerts, got %+v", history)
+	}
+}
@@ -0,0 +1,180 @@
+package api
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/saqibroy/web-crawler-dashboard/server/models"
+	"github.com/saqibroy/web-crawler-dashboard/server/repository"
+)
+
+const maxSortFields = 5
+
+// analysisFilter reads the filter and sort query parameters shared by the
+// list and export endpoints. On invalid input it writes the error response
+// and returns false.
+//
+// List parameters (status, html_version) take comma-separated values or can
+// be repeated. Date ranges take a date or an RFC 3339 timestamp; a date as
+// the upper bound includes that whole day. sort is a comma-separated list of
+// columns, each prefixed with "-" to sort descending; sort_by and sort_order
+// remain for sorting by a single column.
+func analysisFilter(c *gin.Context) (repository.AnalysisFilter, bool) {
+	filter := repository.AnalysisFilter{
+		Search:       c.DefaultQuery("search", ""),
+		HTMLVersions: listParam(c, "html_version"),
+	}
+
+	for _, status := range listParam(c, "status") {
+		if !models.AnalysisStatus(status).Valid() {
+			errorResponse(c, 400, "invalid_status_filter", "Invalid status filter", models.Statuses)
+			return filter, false
+		}
+		filter.Statuses = append(filter.Statuses, models.AnalysisStatus(status))
+	}
+
+	if err := parseFilterParams(c, &filter); err != nil {
+		errorResponse(c, 400, "invalid_filter", err.Error())
+		return filter, false
+	}
+
+	return filter, parseSort(c, &filter)
+}
+
+func parseFilterParams(c *gin.Context, filter *repository.AnalysisFilter) error {
+	if value := c.Query("has_login_form"); value != "" {
+		hasLoginForm, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("has_login_form must be true or false")
+		}
+		filter.HasLoginForm = &hasLoginForm
+	}
+
+	if value := strings.TrimSpace(c.Query("domain")); value != "" {
+		if strings.Contains(value, "://") {
+			value = models.URLDomain(value)
+		}
+		filter.Domain = strings.TrimPrefix(strings.ToLower(value), "*.")
+		if filter.Domain == "" || strings.ContainsAny(filter.Domain, "/:?#@ ") {
+			return fmt.Errorf("domain must be a host name such as example.com")
+		}
+	}
+
+	var err error
+	for _, bound := range []struct {
+		param string
+		end   bool
+		dest  *time.Time
+	}{
+		{"created_from", false, &filter.CreatedFrom},
+		{"created_to", true, &filter.CreatedTo},
+		{"completed_from", false, &filter.CompletedFrom},
+		{"completed_to", true, &filter.CompletedTo},
+	} {
+		if value := c.Query(bound.param); value != "" {
+			if *bound.dest, err = parseTimeBound(value, bound.end); err != nil {
+				return fmt.Errorf("%s must be a date (YYYY-MM-DD) or RFC 3339 timestamp", bound.param)
+			}
+		}
+	}
+
+	if filter.MinBrokenLinks, err = countParam(c, "min_broken_links"); err != nil {
+		return err
+	}
+	if filter.MaxBrokenLinks, err = countParam(c, "max_broken_links"); err != nil {
+		return err
+	}
+	if filter.MinBrokenLinks != nil && filter.MaxBrokenLinks != nil && *filter.MinBrokenLinks > *filter.MaxBrokenLinks {
+		return fmt.Errorf("min_broken_links must not exceed max_broken_links")
+	}
+	return nil
+}
+
+func parseSort(c *gin.Context, filter *repository.AnalysisFilter) bool {
+	sortParam, sortBy := c.Query("sort"), c.Query("sort_by")
+	if sortParam != "" && sortBy != "" {
+		errorResponse(c, 400, "invalid_sort", "Use either sort or sort_by, not both")
+		return false
+	}
+
+	if sortParam != "" {
+		seen := make(map[string]bool)
+		for _, field := range strings.Split(sortParam, ",") {
+			field = strings.TrimSpace(field)
+			desc := strings.HasPrefix(field, "-")
+			column := strings.TrimPrefix(field, "-")
+			if !repository.IsSortColumn(column) || seen[column] {
+				errorResponse(c, 400, "invalid_sort", fmt.Sprintf("Invalid or repeated sort column %q", column), repository.SortColumns)
+				return false
+			}
+			seen[column] = true
+			filter.Sort = append(filter.Sort, repository.SortField{Column: column, Desc: desc})
+		}
+		if len(filter.Sort) > maxSortFields {
+			errorResponse(c, 400, "invalid_sort", fmt.Sprintf("At most %d sort columns are allowed", maxSortFields))
+			return false
+		}
+		return true
+	}
+
+	if sortBy != "" && !repository.IsSortColumn(sortBy) {
+		errorResponse(c, 400, "invalid_sort_by", "Invalid sort column", repository.SortColumns)
+		return false
+	}
+	field := repository.SortField{Column: sortBy}
+	switch c.DefaultQuery("sort_order", "desc") {
+	case "asc":
+	case "desc":
+		field.Desc = true
+	default:
+		errorResponse(c, 400, "invalid_sort_order", "Sort order must be asc or desc")
+		return false
+	}
+	if sortBy != "" {
+		filter.Sort = []repository.SortField{field}
+	}
+	return true
+}
+
+// listParam collects a parameter's values, whether repeated or separated by
+// commas.
+func listParam(c *gin.Context, name string) []string {
+	var values []string
+	for _, param := range c.QueryArray(name) {
+		for _, value := range strings.Split(param, ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				values = append(values, value)
+			}
+		}
+	}
+	return values
+}
+
+func parseTimeBound(value string, end bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	day, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if end {
+		return day.AddDate(0, 0, 1).Add(-time.Microsecond), nil
+	}
+	return day, nil
+}
+
+func countParam(c *gin.Context, name string) (*int, error) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return nil, fmt.Errorf("%s must be a non-negative integer", name)
+	}
+	return &n, nil
+}
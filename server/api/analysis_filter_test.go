@@ -0,0 +1,105 @@
+package api
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/saqibroy/web-crawler-dashboard/server/models"
+)
+
+func TestGetAnalysesFilters(t *testing.T) {
+	s := newTestServer(t)
+	day := func(d int) time.Time { return time.Date(2025, 3, d, 12, 0, 0, 0, time.UTC) }
+	completed := day(2)
+	s.seed(
+		&models.Analysis{URL: "https://example.com/a", Status: models.Completed, HTMLVersion: "HTML5", HasLoginForm: true,
+			BrokenLinks: models.JSONMap{"https://example.com/x": "Broken", "https://example.com/y": "Broken"}, CreatedAt: day(1), CompletedAt: &completed},
+		&models.Analysis{URL: "https://blog.example.com/b", Status: models.Failed, HTMLVersion: "XHTML 1.1", CreatedAt: day(3)},
+		&models.Analysis{URL: "https://other.org/c", Status: models.Queued, CreatedAt: day(4)},
+	)
+
+	urls := func(query string) string {
+		t.Helper()
+		if !strings.Contains(query, "sort=") {
+			query += "&sort=created_at"
+		}
+		w := s.do("GET", "/api/analyses?"+query, nil)
+		if w.Code != 200 {
+			t.Fatalf("%s: expected 200, got %d %s", query, w.Code, w.Body)
+		}
+		var resp struct{ Data []models.Analysis }
+		decode(t, w, &resp)
+		var paths []string
+		for _, a := range resp.Data {
+			parsed, _ := url.Parse(a.URL)
+			paths = append(paths, strings.TrimPrefix(parsed.Path, "/"))
+		}
+		return strings.Join(paths, ",")
+	}
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"status=completed,failed", "a,b"},
+		{"status=completed&status=queued", "a,c"},
+		{"html_version=XHTML+1.1", "b"},
+		{"has_login_form=false", "b,c"},
+		{"domain=example.com", "a,b"},
+		{"domain=https://blog.example.com/", "b"},
+		{"created_from=2025-03-03", "b,c"},
+		{"created_to=2025-03-03", "a,b"},
+		{"created_from=2025-03-03T13:00:00Z", "c"},
+		{"completed_to=2025-03-02", "a"},
+		{"min_broken_links=1", "a"},
+		{"max_broken_links=0", "b,c"},
+		{"domain=example.com&max_broken_links=0", "b"},
+	}
+	for _, tt := range tests {
+		if got := urls(tt.query); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.query, got, tt.want)
+		}
+	}
+
+	if got := urls("sort=-broken_link_count,-url"); got != "a,c,b" {
+		t.Errorf("unexpected multi-column order %q", got)
+	}
+
+	var resp struct{ Data []models.Analysis }
+	decode(t, s.do("GET", "/api/analyses?status=completed", nil), &resp)
+	if resp.Data[0].Domain != "example.com" || resp.Data[0].BrokenLinkCount != 2 {
+		t.Errorf("unexpected derived fields: %q, %d", resp.Data[0].Domain, resp.Data[0].BrokenLinkCount)
+	}
+}
+
+func TestGetAnalysesFilterValidation(t *testing.T) {
+	s := newTestServer(t)
+
+	tests := []struct {
+		query string
+		code  string
+	}{
+		{"status=completed,done", "invalid_status_filter"},
+		{"has_login_form=maybe", "invalid_filter"},
+		{"domain=example.com/path", "invalid_filter"},
+		{"created_from=yesterday", "invalid_filter"},
+		{"completed_to=2025-13-01", "invalid_filter"},
+		{"min_broken_links=-1", "invalid_filter"},
+		{"min_broken_links=5&max_broken_links=2", "invalid_filter"},
+		{"sort=url,-nope", "invalid_sort"},
+		{"sort=url,-url", "invalid_sort"},
+		{"sort=url%3Bdrop+table+analyses", "invalid_sort"},
+		{"sort=url&sort_by=title", "invalid_sort"},
+		{"sort=url,title,status,domain,created_at,updated_at", "invalid_sort"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			expectError(t, s.do("GET", "/api/analyses?"+tt.query, nil), 400, tt.code)
+		})
+	}
+
+	// Export shares the filters
+	expectError(t, s.do("GET", "/api/analyses/export?sort=nope", nil), 400, "invalid_sort")
+}
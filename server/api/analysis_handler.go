@@ -1,20 +1,27 @@
 package api
 
 import (
+	"errors"
 	"fmt"
 	"net/url"
 	"strconv"
 	"strings"
 
 	"github.com/gin-gonic/gin"
-	"github.com/saqibroy/web-crawler-dashboard/server/db"
 	"github.com/saqibroy/web-crawler-dashboard/server/models"
-	"github.com/saqibroy/web-crawler-dashboard/server/worker"
+	"github.com/saqibroy/web-crawler-dashboard/server/repository"
+	"github.com/saqibroy/web-crawler-dashboard/server/services"
 )
 
 // Request/Response structs
 type URLRequest struct {
-	URL string `json:"url" binding:"required"`
+	URL             string                 `json:"url" binding:"required"`
+	RenderMode      string                 `json:"render_mode"`
+	Analyzers       []string               `json:"analyzers"`
+	RuleSetID       string                 `json:"rule_set_id"`
+	ExtractionRules models.ExtractionRules `json:"extraction_rules"`
+	Fetch           *FetchRequest          `json:"fetch"`
+	Login           *LoginRequest          `json:"login"`
 }
 
 type IDsRequest struct {
@@ -61,7 +68,7 @@ func errorResponse(c *gin.Context, status int, code, message string, details ...
 }
 
 // API Handlers
-func SubmitURL(c *gin.Context) {
+func (h *Handler) SubmitURL(c *gin.Context) {
 	var req URLRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		errorResponse(c, 400, "invalid_request", "Invalid request format", err.Error())
@@ -73,8 +80,45 @@ func SubmitURL(c *gin.Context) {
 		return
 	}
 
-	analysis := models.Analysis{URL: req.URL, Status: models.Queued}
-	if err := db.DB.Create(&analysis).Error; err != nil {
+	if !services.IsValidRenderMode(req.RenderMode) {
+		errorResponse(c, 400, "invalid_render_mode", "Render mode must be http or browser")
+		return
+	}
+	if req.RenderMode == "" {
+		req.RenderMode = services.RenderHTTP
+	}
+
+	if err := services.ValidateAnalyzers(req.Analyzers); err != nil {
+		errorResponse(c, 400, "invalid_analyzers", err.Error(), services.AnalyzerNames())
+		return
+	}
+
+	rules, err := h.resolveExtractionRules(c.Request.Context(), req.RuleSetID, req.ExtractionRules)
+	if err == errRuleSetNotFound {
+		errorResponse(c, 404, "rule_set_not_found", err.Error())
+		return
+	} else if err != nil {
+		errorResponse(c, 400, "invalid_rules", err.Error())
+		return
+	}
+
+	fetchConfig, loginRecipe, fetchCredentials, err := resolveRequestSettings(req.Fetch, req.Login)
+	if err != nil {
+		errorResponse(c, 400, "invalid_fetch_config", err.Error())
+		return
+	}
+
+	analysis := models.Analysis{
+		URL:              req.URL,
+		Status:           models.Queued,
+		RenderMode:       req.RenderMode,
+		Analyzers:        req.Analyzers,
+		ExtractionRules:  rules,
+		FetchConfig:      fetchConfig,
+		LoginRecipe:      loginRecipe,
+		FetchCredentials: fetchCredentials,
+	}
+	if err := h.analyses.Create(c.Request.Context(), &analysis); err != nil {
 		errorResponse(c, 500, "db_create_failed", "Failed to save analysis", err.Error())
 		return
 	}
@@ -82,8 +126,18 @@ func SubmitURL(c *gin.Context) {
 	c.JSON(202, gin.H{"id": analysis.ID, "status": analysis.Status})
 }
 
-func GetAnalyses(c *gin.Context) {
+// GetAnalyses lists analyses a page at a time. Pages are read with the
+// opaque cursors of the previous response, which stay in place as analyses
+// are added and removed; page keeps the older numbered pages working. Either
+// way, Link and X-Total-Count headers describe the surrounding pages.
+func (h *Handler) GetAnalyses(c *gin.Context) {
 	// Parse parameters
+	_, paged := c.GetQuery("page")
+	cursor := c.Query("cursor")
+	if paged && cursor != "" {
+		errorResponse(c, 400, "invalid_pagination", "Use either page or cursor, not both")
+		return
+	}
 	page, err := parseIntParam(c, "page", 1)
 	if err != nil {
 		errorResponse(c, 400, "invalid_page", "Invalid page number")
@@ -96,64 +150,64 @@ func GetAnalyses(c *gin.Context) {
 		return
 	}
 
-	search := c.DefaultQuery("search", "")
-	sortBy := c.DefaultQuery("sort_by", "")
-	sortOrder := c.DefaultQuery("sort_order", "desc")
-	statusFilter := c.DefaultQuery("status", "")
+	filter, ok := analysisFilter(c)
+	if !ok {
+		return
+	}
 
-	// Validate status filter
-	if statusFilter != "" {
-		validStatuses := map[string]bool{
-			"queued": true, "processing": true, "completed": true,
-			"failed": true, "cancelled": true,
-		}
-		if !validStatuses[statusFilter] {
-			errorResponse(c, 400, "invalid_status_filter", "Invalid status filter")
+	// Get the page and total count
+	var analyses []models.Analysis
+	var total int64
+	var links []pageLink
+	var next, prev interface{}
+	if paged {
+		filter.Offset = (page - 1) * limit
+		filter.Limit = limit
+		analyses, total, err = h.analyses.List(c.Request.Context(), filter)
+		links = numberedPageLinks(page, limit, total)
+	} else {
+		var result *repository.AnalysisPage
+		result, err = h.analyses.ListPage(c.Request.Context(), filter, cursor, limit)
+		if errors.Is(err, repository.ErrInvalidCursor) {
+			errorResponse(c, 400, "invalid_cursor", "Invalid cursor; start again from the first page")
 			return
 		}
+		if err == nil {
+			analyses, total = result.Analyses, result.Total
+			links = cursorLinks(result)
+			next, prev = optional(result.Next), optional(result.Prev)
+		}
 	}
-
-	// Build query
-	query := db.DB.Model(&models.Analysis{})
-
-	// Apply filters
-	if search != "" {
-		query = query.Where("url LIKE ? OR title LIKE ?", "%"+search+"%", "%"+search+"%")
-	}
-	if statusFilter != "" {
-		query = query.Where("status = ?", statusFilter)
-	}
-
-	// Apply sorting
-	if sortBy != "" {
-		query = query.Order(fmt.Sprintf("%s %s", sortBy, sortOrder))
-	} else {
-		query = query.Order("CASE WHEN status IN ('queued', 'processing') THEN 0 ELSE 1 END, COALESCE(updated_at, created_at) desc")
+	if err != nil {
+		errorResponse(c, 500, "db_query_failed", "Failed to query analyses", err.Error())
+		return
 	}
 
-	// Get total count and data
-	var total int64
-	query.Count(&total)
-
-	var analyses []models.Analysis
-	query.Offset((page - 1) * limit).Limit(limit).Find(&analyses)
-
 	// Get status counts
-	statusCounts := make(map[string]int64)
-	for _, status := range []models.AnalysisStatus{models.Completed, models.Failed, models.Processing, models.Queued, models.Cancelled} {
-		var count int64
-		db.DB.Model(&models.Analysis{}).Where("status = ?", status).Count(&count)
+	counts, err := h.analyses.CountByStatus(c.Request.Context())
+	if err != nil {
+		errorResponse(c, 500, "db_query_failed", "Failed to count analyses", err.Error())
+		return
+	}
+	statusCounts := make(map[string]int64, len(counts))
+	for status, count := range counts {
 		statusCounts[string(status)] = count
 	}
 
-	c.JSON(200, gin.H{
+	setPaginationHeaders(c, total, links)
+	response := gin.H{
 		"data":          analyses,
 		"total_count":   total,
 		"status_counts": statusCounts,
-	})
+	}
+	if !paged {
+		response["next_cursor"] = next
+		response["prev_cursor"] = prev
+	}
+	c.JSON(200, response)
 }
 
-func DeleteAnalyses(c *gin.Context) {
+func (h *Handler) DeleteAnalyses(c *gin.Context) {
 	var req IDsRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		errorResponse(c, 400, "invalid_request", "Invalid request format", err.Error())
@@ -165,16 +219,16 @@ func DeleteAnalyses(c *gin.Context) {
 		return
 	}
 
-	result := db.DB.Delete(&models.Analysis{}, req.IDs)
-	if result.Error != nil {
-		errorResponse(c, 500, "db_delete_failed", "Failed to delete analyses", result.Error.Error())
+	deleted, err := h.analyses.Delete(c.Request.Context(), req.IDs)
+	if err != nil {
+		errorResponse(c, 500, "db_delete_failed", "Failed to delete analyses", err.Error())
 		return
 	}
 
-	c.JSON(200, gin.H{"deleted": result.RowsAffected})
+	c.JSON(200, gin.H{"deleted": deleted})
 }
 
-func StopAnalyses(c *gin.Context) {
+func (h *Handler) StopAnalyses(c *gin.Context) {
 	var req IDsRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		errorResponse(c, 400, "invalid_request", "Invalid request format", err.Error())
@@ -188,19 +242,20 @@ func StopAnalyses(c *gin.Context) {
 
 	var stopped int64
 	for _, id := range req.IDs {
-		var analysis models.Analysis
-		if err := db.DB.Where("id = ? AND (status = ? OR status = ?)", id, models.Queued, models.Processing).First(&analysis).Error; err == nil {
-			if analysis.MarkAsCancelled(db.DB) == nil {
+		analysis, err := h.analyses.Get(c.Request.Context(), id)
+		if err == nil && (analysis.Status == models.Queued || analysis.Status == models.Processing) {
+			analysis.MarkAsCancelled()
+			if h.analyses.Save(c.Request.Context(), analysis) == nil {
 				stopped++
 			}
 		}
-		worker.StopAnalysis(id)
+		h.worker.StopAnalysis(id)
 	}
 
 	c.JSON(200, gin.H{"stopped": stopped})
 }
 
-func RerunAnalyses(c *gin.Context) {
+func (h *Handler) RerunAnalyses(c *gin.Context) {
 	var req IDsRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		errorResponse(c, 400, "invalid_request", "Invalid request format", err.Error())
@@ -212,30 +267,32 @@ func RerunAnalyses(c *gin.Context) {
 		return
 	}
 
-	result := db.DB.Model(&models.Analysis{}).
-		Where("id IN ? AND status NOT IN (?, ?)", req.IDs, models.Processing, models.Queued).
-		Updates(map[string]interface{}{"status": models.Queued})
-
-	if result.Error != nil {
-		errorResponse(c, 500, "db_rerun_failed", "Failed to rerun analyses", result.Error.Error())
+	rerun, err := h.analyses.UpdateStatus(c.Request.Context(), req.IDs, models.Queued,
+		models.Completed, models.Failed, models.Cancelled)
+	if err != nil {
+		errorResponse(c, 500, "db_rerun_failed", "Failed to rerun analyses", err.Error())
 		return
 	}
 
-	c.JSON(200, gin.H{"rerun": result.RowsAffected})
+	c.JSON(200, gin.H{"rerun": rerun})
 }
 
-func GetSingleAnalysis(c *gin.Context) {
+func (h *Handler) GetSingleAnalysis(c *gin.Context) {
 	id := c.Param("id")
 	if len(id) != 36 {
 		errorResponse(c, 400, "invalid_id_format", "Invalid ID format")
 		return
 	}
 
-	var analysis models.Analysis
-	if err := db.DB.First(&analysis, "id = ?", id).Error; err != nil {
+	analysis, err := h.analyses.Get(c.Request.Context(), id)
+	if err != nil {
 		errorResponse(c, 404, "not_found", "Analysis not found")
 		return
 	}
 
 	c.JSON(200, analysis)
 }
+
+func ListAnalyzers(c *gin.Context) {
+	c.JSON(200, gin.H{"analyzers": services.AnalyzerNames()})
+}
@@ -0,0 +1,353 @@
+package api
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/saqibroy/web-crawler-dashboard/server/models"
+)
+
+func TestSubmitURL(t *testing.T) {
+	s := newTestServer(t)
+
+	id := s.submit(gin.H{"url": "https://example.com", "analyzers": []string{"headings"}})
+
+	a := s.get(id)
+	if a.URL != "https://example.com" || a.Status != models.Queued || a.RenderMode != "http" {
+		t.Errorf("unexpected analysis: %+v", a)
+	}
+	if len(a.Analyzers) != 1 || a.Analyzers[0] != "headings" {
+		t.Errorf("expected the analyzers to be stored, got %v", a.Analyzers)
+	}
+}
+
+func TestSubmitURLValidation(t *testing.T) {
+	s := newTestServer(t)
+
+	tests := []struct {
+		name   string
+		body   interface{}
+		status int
+		code   string
+	}{
+		{"missing url", gin.H{}, 400, "invalid_request"},
+		{"invalid url", gin.H{"url": "ftp://example.com"}, 400, "invalid_url"},
+		{"render mode", gin.H{"url": "https://example.com", "render_mode": "telepathy"}, 400, "invalid_render_mode"},
+		{"analyzers", gin.H{"url": "https://example.com", "analyzers": []string{"nope"}}, 400, "invalid_analyzers"},
+		{"rules", gin.H{"url": "https://example.com", "extraction_rules": []gin.H{{"name": "x", "selector": "["}}}, 400, "invalid_rules"},
+		{"rule set", gin.H{"url": "https://example.com", "rule_set_id": "00000000-0000-0000-0000-000000000000"}, 404, "rule_set_not_found"},
+		{"proxy", gin.H{"url": "https://example.com", "fetch": gin.H{"proxy": "ftp://proxy"}}, 400, "invalid_fetch_config"},
+		{"login", gin.H{"url": "https://example.com", "login": gin.H{"url": "not a url"}}, 400, "invalid_fetch_config"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expectError(t, s.do("POST", "/api/analyses", tt.body), tt.status, tt.code)
+		})
+	}
+}
+
+func TestSubmitURLEncryptsCredentials(t *testing.T) {
+	s := newTestServer(t)
+
+	id := s.submit(gin.H{
+		"url": "https://example.com",
+		"fetch": gin.H{
+			"headers":    gin.H{"X-Api-Key": "header-token"},
+			"cookies":    gin.H{"session": "cookie-token"},
+			"basic_auth": gin.H{"username": "user", "password": "secret"},
+		},
+	})
+
+	a := s.get(id)
+	if a.FetchCredentials == "" || !a.FetchConfig.HasCredentials ||
+		len(a.FetchConfig.HeaderNames) != 1 || a.FetchConfig.HeaderNames[0] != "X-Api-Key" ||
+		len(a.FetchConfig.CookieNames) != 1 || a.FetchConfig.CookieNames[0] != "session" {
+		t.Errorf("unexpected fetch settings: %+v", a.FetchConfig)
+	}
+
+	w := s.do("GET", "/api/analyses/"+id, nil)
+	if body := w.Body.String(); strings.Contains(body, "secret") || strings.Contains(body, "token") || strings.Contains(body, a.FetchCredentials) {
+		t.Errorf("credentials leaked in response: %s", body)
+	}
+}
+
+func TestSubmittedAnalysisIsCrawled(t *testing.T) {
+	s := newTestServer(t)
+	target := crawlTarget(t)
+
+	id := s.submit(gin.H{"url": target.URL})
+	s.process()
+
+	w := s.do("GET", "/api/analyses/"+id, nil)
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	var a models.Analysis
+	decode(t, w, &a)
+
+	if a.Status != models.Completed || a.CompletedAt == nil {
+		t.Fatalf("expected completed, got %s", a.Status)
+	}
+	if a.Title != "Test Shop" || a.HTMLVersion != "HTML5" || a.MetaDescription != "A shop for tests" {
+		t.Errorf("unexpected page details: %q %q %q", a.Title, a.HTMLVersion, a.MetaDescription)
+	}
+	if headingCount(a.Headings, "h1") != 1 || headingCount(a.Headings, "h2") != 1 {
+		t.Errorf("unexpected headings: %v", a.Headings)
+	}
+	if a.InternalLinks != 2 || len(a.BrokenLinks) != 1 {
+		t.Errorf("expected 2 internal links with 1 broken, got %d and %v", a.InternalLinks, a.BrokenLinks)
+	}
+	if !a.HasLoginForm {
+		t.Error("expected the login form to be detected")
+	}
+	if len(a.Results) == 0 {
+		t.Error("expected analyzer results")
+	}
+}
+
+func TestGetAnalyses(t *testing.T) {
+	s := newTestServer(t)
+	s.seed(
+		&models.Analysis{URL: "https://alpha.example.com", Title: "Alpha", Status: models.Completed, InternalLinks: 3},
+		&models.Analysis{URL: "https://beta.example.com", Title: "Beta", Status: models.Failed, InternalLinks: 7},
+		&models.Analysis{URL: "https://gamma.example.org", Title: "Gamma", Status: models.Queued, InternalLinks: 1},
+	)
+
+	type listResponse struct {
+		Data         []models.Analysis `json:"data"`
+		TotalCount   int64             `json:"total_count"`
+		StatusCounts map[string]int64  `json:"status_counts"`
+	}
+
+	var resp listResponse
+	decode(t, s.do("GET", "/api/analyses", nil), &resp)
+	if resp.TotalCount != 3 || len(resp.Data) != 3 {
+		t.Fatalf("expected 3 analyses, got %d (total %d)", len(resp.Data), resp.TotalCount)
+	}
+	if resp.Data[0].Status != models.Queued {
+		t.Errorf("expected queued analyses first, got %s", resp.Data[0].Status)
+	}
+	if resp.StatusCounts["completed"] != 1 || resp.StatusCounts["failed"] != 1 || resp.StatusCounts["queued"] != 1 {
+		t.Errorf("unexpected status counts: %v", resp.StatusCounts)
+	}
+
+	resp = listResponse{}
+	decode(t, s.do("GET", "/api/analyses?page=2&limit=2&sort_by=url&sort_order=asc", nil), &resp)
+	if resp.TotalCount != 3 || len(resp.Data) != 1 || resp.Data[0].Title != "Gamma" {
+		t.Errorf("unexpected second page: %+v", resp.Data)
+	}
+
+	resp = listResponse{}
+	decode(t, s.do("GET", "/api/analyses?sort_by=internal_links", nil), &resp)
+	if resp.Data[0].InternalLinks != 7 {
+		t.Errorf("expected descending order by default, got %d first", resp.Data[0].InternalLinks)
+	}
+
+	resp = listResponse{}
+	decode(t, s.do("GET", "/api/analyses?search=BETA", nil), &resp)
+	if resp.TotalCount != 1 || resp.Data[0].Title != "Beta" {
+		t.Errorf("unexpected search results: %+v", resp.Data)
+	}
+
+	resp = listResponse{}
+	decode(t, s.do("GET", "/api/analyses?status=completed", nil), &resp)
+	if resp.TotalCount != 1 || resp.Data[0].Status != models.Completed {
+		t.Errorf("unexpected status filter results: %+v", resp.Data)
+	}
+	if resp.StatusCounts["failed"] != 1 {
+		t.Errorf("status counts should ignore filters, got %v", resp.StatusCounts)
+	}
+}
+
+func TestGetAnalysesCursorPagination(t *testing.T) {
+	s := newTestServer(t)
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		s.seed(&models.Analysis{URL: "https://" + name + ".example.com", Status: models.Completed})
+	}
+
+	type pageResponse struct {
+		Data       []models.Analysis `json:"data"`
+		TotalCount int64             `json:"total_count"`
+		NextCursor *string           `json:"next_cursor"`
+		PrevCursor *string           `json:"prev_cursor"`
+	}
+	get := func(query string) (pageResponse, string) {
+		t.Helper()
+		w := s.do("GET", "/api/analyses?sort=url&limit=2"+query, nil)
+		if w.Code != 200 {
+			t.Fatalf("%s: expected 200, got %d %s", query, w.Code, w.Body)
+		}
+		if got := w.Header().Get("X-Total-Count"); got != "5" {
+			t.Errorf("%s: unexpected X-Total-Count %q", query, got)
+		}
+		var resp pageResponse
+		decode(t, w, &resp)
+		var hosts []string
+		for _, a := range resp.Data {
+			hosts = append(hosts, strings.TrimSuffix(strings.TrimPrefix(a.URL, "https://"), ".example.com"))
+		}
+		return resp, strings.Join(hosts, ",")
+	}
+
+	first, hosts := get("")
+	if hosts != "a,b" || first.TotalCount != 5 || first.NextCursor == nil || first.PrevCursor != nil {
+		t.Fatalf("unexpected first page %s: %+v", hosts, first)
+	}
+
+	// Analyses added before the cursor don't shift later pages
+	s.seed(&models.Analysis{URL: "https://0.example.com", Status: models.Completed})
+	if _, err := s.analyses.Delete(context.Background(), []string{first.Data[0].ID}); err != nil {
+		t.Fatal(err)
+	}
+
+	second, hosts := get("&cursor=" + url.QueryEscape(*first.NextCursor))
+	if hosts != "c,d" || second.PrevCursor == nil || second.NextCursor == nil {
+		t.Fatalf("unexpected second page %s: %+v", hosts, second)
+	}
+	if _, hosts := get("&cursor=" + url.QueryEscape(*second.PrevCursor)); hosts != "0,b" {
+		t.Errorf("unexpected previous page %s", hosts)
+	}
+
+	w := s.do("GET", "/api/analyses?sort=url&limit=2&cursor="+url.QueryEscape(*second.NextCursor), nil)
+	link := w.Header().Get("Link")
+	if !strings.Contains(link, `</api/analyses?limit=2&sort=url>; rel="first"`) ||
+		!strings.Contains(link, `rel="prev"`) || strings.Contains(link, `rel="next"`) {
+		t.Errorf("unexpected Link header %q", link)
+	}
+
+	// Numbered pages link by page number
+	w = s.do("GET", "/api/analyses?sort=url&limit=2&page=2", nil)
+	if want := `</api/analyses?limit=2&page=1&sort=url>; rel="first", </api/analyses?limit=2&page=1&sort=url>; rel="prev", ` +
+		`</api/analyses?limit=2&page=3&sort=url>; rel="next", </api/analyses?limit=2&page=3&sort=url>; rel="last"`; w.Header().Get("Link") != want {
+		t.Errorf("unexpected Link header %q", w.Header().Get("Link"))
+	}
+	if strings.Contains(w.Body.String(), "next_cursor") {
+		t.Error("numbered pages shouldn't include cursors")
+	}
+
+	expectError(t, s.do("GET", "/api/analyses?sort=-url&cursor="+url.QueryEscape(*first.NextCursor), nil), 400, "invalid_cursor")
+}
+
+func TestGetAnalysesValidation(t *testing.T) {
+	s := newTestServer(t)
+
+	tests := []struct {
+		query string
+		code  string
+	}{
+		{"page=0", "invalid_page"},
+		{"limit=abc", "invalid_limit"},
+		{"status=done", "invalid_status_filter"},
+		{"sort_by=url%3Bdrop+table+analyses", "invalid_sort_by"},
+		{"sort_by=url&sort_order=sideways", "invalid_sort_order"},
+		{"page=1&cursor=abc", "invalid_pagination"},
+		{"cursor=not-a-cursor", "invalid_cursor"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			expectError(t, s.do("GET", "/api/analyses?"+tt.query, nil), 400, tt.code)
+		})
+	}
+}
+
+func TestGetSingleAnalysis(t *testing.T) {
+	s := newTestServer(t)
+	a := &models.Analysis{URL: "https://example.com", Status: models.Queued}
+	s.seed(a)
+
+	w := s.do("GET", "/api/analyses/"+a.ID, nil)
+	var got models.Analysis
+	decode(t, w, &got)
+	if w.Code != 200 || got.ID != a.ID {
+		t.Errorf("expected the analysis, got %d %s", w.Code, w.Body)
+	}
+
+	expectError(t, s.do("GET", "/api/analyses/short", nil), 400, "invalid_id_format")
+	expectError(t, s.do("GET", "/api/analyses/00000000-0000-0000-0000-000000000000", nil), 404, "not_found")
+}
+
+func TestDeleteAnalyses(t *testing.T) {
+	s := newTestServer(t)
+	a := &models.Analysis{URL: "https://a.example.com", Status: models.Completed}
+	b := &models.Analysis{URL: "https://b.example.com", Status: models.Completed}
+	s.seed(a, b)
+
+	var resp struct{ Deleted int64 }
+	decode(t, s.do("DELETE", "/api/analyses", gin.H{"ids": []string{a.ID}}), &resp)
+	if resp.Deleted != 1 {
+		t.Errorf("expected 1 deleted, got %d", resp.Deleted)
+	}
+	if w := s.do("GET", "/api/analyses/"+a.ID, nil); w.Code != 404 {
+		t.Errorf("deleted analysis still returned: %d", w.Code)
+	}
+	if w := s.do("GET", "/api/analyses/"+b.ID, nil); w.Code != 200 {
+		t.Errorf("other analysis was deleted: %d", w.Code)
+	}
+
+	expectError(t, s.do("DELETE", "/api/analyses", gin.H{"ids": []string{}}), 400, "invalid_ids")
+	expectError(t, s.do("DELETE", "/api/analyses", gin.H{"ids": []string{"bad"}}), 400, "invalid_ids")
+	expectError(t, s.do("DELETE", "/api/analyses", "not an object"), 400, "invalid_request")
+}
+
+func TestStopAnalyses(t *testing.T) {
+	s := newTestServer(t)
+	queued := &models.Analysis{URL: "https://queued.example.com", Status: models.Queued}
+	done := &models.Analysis{URL: "https://done.example.com", Status: models.Completed, Title: "Done"}
+	s.seed(queued, done)
+
+	var resp struct{ Stopped int64 }
+	decode(t, s.do("POST", "/api/analyses/stop", gin.H{"ids": []string{queued.ID, done.ID}}), &resp)
+	if resp.Stopped != 1 {
+		t.Errorf("expected 1 stopped, got %d", resp.Stopped)
+	}
+	if got := s.get(queued.ID); got.Status != models.Cancelled {
+		t.Errorf("expected cancelled, got %s", got.Status)
+	}
+	if got := s.get(done.ID); got.Status != models.Completed || got.Title != "Done" {
+		t.Errorf("completed analysis changed: %+v", got)
+	}
+
+	// A cancelled analysis is never picked up by the worker
+	if s.worker.ProcessNext(context.Background()) {
+		t.Error("expected nothing left to process")
+	}
+
+	expectError(t, s.do("POST", "/api/analyses/stop", gin.H{"ids": []string{"bad"}}), 400, "invalid_ids")
+}
+
+func TestRerunAnalyses(t *testing.T) {
+	s := newTestServer(t)
+	target := crawlTarget(t)
+	failed := &models.Analysis{URL: target.URL, Status: models.Failed}
+	processing := &models.Analysis{URL: "https://busy.example.com", Status: models.Processing}
+	s.seed(failed, processing)
+
+	var resp struct{ Rerun int64 }
+	decode(t, s.do("POST", "/api/analyses/rerun", gin.H{"ids": []string{failed.ID, processing.ID}}), &resp)
+	if resp.Rerun != 1 {
+		t.Errorf("expected 1 rerun, got %d", resp.Rerun)
+	}
+	if got := s.get(processing.ID); got.Status != models.Processing {
+		t.Errorf("processing analysis changed to %s", got.Status)
+	}
+
+	s.process()
+	if got := s.get(failed.ID); got.Status != models.Completed || got.Title != "Test Shop" {
+		t.Errorf("expected the rerun to complete, got %s %q", got.Status, got.Title)
+	}
+
+	expectError(t, s.do("POST", "/api/analyses/rerun", gin.H{}), 400, "invalid_request")
+}
+
+func TestListAnalyzers(t *testing.T) {
+	s := newTestServer(t)
+
+	var resp struct{ Analyzers []string }
+	decode(t, s.do("GET", "/api/analyzers", nil), &resp)
+	if len(resp.Analyzers) == 0 || resp.Analyzers[0] != "html_version" {
+		t.Errorf("unexpected analyzers: %v", resp.Analyzers)
+	}
+}
@@ -0,0 +1,223 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/saqibroy/web-crawler-dashboard/server/models"
+	"github.com/saqibroy/web-crawler-dashboard/server/services"
+)
+
+const (
+	maxBulkURLs       = 1000
+	maxBulkUploadSize = 5 << 20
+)
+
+type BulkURLRequest struct {
+	URLs       []string `json:"urls"`
+	SitemapURL string   `json:"sitemap_url"`
+}
+
+type BulkResult struct {
+	Line   int    `json:"line"`
+	URL    string `json:"url"`
+	Status string `json:"status"`
+	ID     string `json:"id,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+type bulkEntry struct {
+	line int
+	url  string
+}
+
+// SubmitBulkURLs queues analyses for many URLs at once. The body may be a JSON
+// array of URLs, a JSON object with "urls" or "sitemap_url", or a multipart
+// upload of a CSV or plain text file with one URL per line.
+func (h *Handler) SubmitBulkURLs(c *gin.Context) {
+	entries, sitemap, err := readBulkEntries(c)
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		errorResponse(c, 413, "request_too_large", fmt.Sprintf("The request body exceeds %d bytes", tooLarge.Limit))
+		return
+	}
+	if err != nil {
+		errorResponse(c, 400, "invalid_request", "Invalid bulk request", err.Error())
+		return
+	}
+
+	if len(entries) == 0 {
+		errorResponse(c, 400, "no_urls", "No URLs provided")
+		return
+	}
+	if len(entries) > maxBulkURLs {
+		errorResponse(c, 400, "too_many_urls", fmt.Sprintf("At most %d URLs can be submitted at once", maxBulkURLs))
+		return
+	}
+
+	results := make([]BulkResult, len(entries))
+	analyses := make([]*models.Analysis, 0, len(entries))
+	accepted := make([]int, 0, len(entries))
+	seen := make(map[string]int)
+
+	for i, entry := range entries {
+		results[i] = BulkResult{Line: entry.line, URL: entry.url}
+
+		if !isValidURL(entry.url) {
+			results[i].Status = "rejected"
+			results[i].Reason = "invalid_url"
+			continue
+		}
+		if first, ok := seen[entry.url]; ok {
+			results[i].Status = "rejected"
+			results[i].Reason = fmt.Sprintf("duplicate of line %d", first)
+			continue
+		}
+		seen[entry.url] = entry.line
+
+		analyses = append(analyses, &models.Analysis{URL: entry.url, Status: models.Queued, RenderMode: services.RenderHTTP})
+		accepted = append(accepted, i)
+	}
+
+	if len(analyses) > 0 {
+		if err := h.analyses.Create(c.Request.Context(), analyses...); err != nil {
+			errorResponse(c, 500, "db_create_failed", "Failed to save analyses", err.Error())
+			return
+		}
+	}
+
+	for n, i := range accepted {
+		results[i].Status = "accepted"
+		results[i].ID = analyses[n].ID
+	}
+
+	response := gin.H{
+		"accepted": len(analyses),
+		"rejected": len(entries) - len(analyses),
+		"results":  results,
+	}
+	// Child sitemaps that couldn't be read leave URLs out of the results
+	if sitemap != nil && len(sitemap.Errors) > 0 {
+		response["sitemap_errors"] = sitemap.Errors
+	}
+	if sitemap != nil && sitemap.Truncated {
+		response["sitemap_truncated"] = true
+	}
+	c.JSON(202, response)
+}
+
+// readBulkEntries reads the submitted URLs, and what was read of the sitemap
+// when they came from one.
+func readBulkEntries(c *gin.Context) ([]bulkEntry, *services.SitemapURLs, error) {
+	if strings.HasPrefix(c.ContentType(), "multipart/form-data") {
+		entries, err := readBulkUpload(c)
+		return entries, nil, err
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxBulkUploadSize))
+	if err != nil {
+		return nil, nil, err
+	}
+	body = bytes.TrimSpace(body)
+
+	var req BulkURLRequest
+	if len(body) > 0 && body[0] == '[' {
+		if err := json.Unmarshal(body, &req.URLs); err != nil {
+			return nil, nil, err
+		}
+	} else if err := json.Unmarshal(body, &req); err != nil {
+		return nil, nil, err
+	}
+
+	var sitemap *services.SitemapURLs
+	if req.SitemapURL != "" {
+		if len(req.URLs) > 0 {
+			return nil, nil, errors.New("provide either urls or sitemap_url, not both")
+		}
+		if !isValidURL(req.SitemapURL) {
+			return nil, nil, errors.New("invalid sitemap_url")
+		}
+		sitemap, err = services.FetchSitemapURLs(c.Request.Context(), req.SitemapURL)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read sitemap: %w", err)
+		}
+		req.URLs = sitemap.URLs
+	}
+
+	entries := make([]bulkEntry, 0, len(req.URLs))
+	for i, u := range req.URLs {
+		entries = append(entries, bulkEntry{line: i + 1, url: strings.TrimSpace(u)})
+	}
+	return entries, sitemap, nil
+}
+
+func readBulkUpload(c *gin.Context) ([]bulkEntry, error) {
+	header, err := c.FormFile("file")
+	if err != nil {
+		return nil, errors.New("multipart upload must contain a \"file\" field")
+	}
+	if header.Size > maxBulkUploadSize {
+		return nil, fmt.Errorf("file exceeds %d bytes", maxBulkUploadSize)
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	if strings.EqualFold(filepath.Ext(header.Filename), ".csv") ||
+		header.Header.Get("Content-Type") == "text/csv" {
+		return readCSVEntries(file)
+	}
+	return readLineEntries(file)
+}
+
+// readCSVEntries takes the URL from the first column of each row, skipping
+// blank rows and a leading "url" header row.
+func readCSVEntries(r io.Reader) ([]bulkEntry, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var entries []bulkEntry
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := reader.FieldPos(0)
+		value := strings.TrimSpace(record[0])
+		if value == "" || (len(entries) == 0 && strings.EqualFold(value, "url")) {
+			continue
+		}
+		entries = append(entries, bulkEntry{line: line, url: value})
+	}
+	return entries, nil
+}
+
+func readLineEntries(r io.Reader) ([]bulkEntry, error) {
+	var entries []bulkEntry
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		value := strings.TrimSpace(scanner.Text())
+		if value == "" || strings.HasPrefix(value, "#") {
+			continue
+		}
+		entries = append(entries, bulkEntry{line: line, url: value})
+	}
+	return entries, scanner.Err()
+}
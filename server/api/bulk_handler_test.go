@@ -0,0 +1,115 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type bulkResponse struct {
+	Accepted         int          `json:"accepted"`
+	Rejected         int          `json:"rejected"`
+	Results          []BulkResult `json:"results"`
+	SitemapErrors    []string     `json:"sitemap_errors"`
+	SitemapTruncated bool         `json:"sitemap_truncated"`
+}
+
+func TestSubmitBulkURLs(t *testing.T) {
+	s := newTestServer(t)
+
+	w := s.do("POST", "/api/analyses/bulk", []string{
+		"https://a.example.com", "not a url", "https://b.example.com", "https://a.example.com",
+	})
+	if w.Code != 202 {
+		t.Fatalf("expected 202, got %d: %s", w.Code, w.Body)
+	}
+
+	var resp bulkResponse
+	decode(t, w, &resp)
+	if resp.Accepted != 2 || resp.Rejected != 2 || len(resp.Results) != 4 {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+	if resp.Results[1].Reason != "invalid_url" || resp.Results[3].Reason != "duplicate of line 1" {
+		t.Errorf("unexpected rejections: %+v", resp.Results)
+	}
+	for _, i := range []int{0, 2} {
+		if got := s.get(resp.Results[i].ID); got.URL != resp.Results[i].URL {
+			t.Errorf("line %d: stored %q", i+1, got.URL)
+		}
+	}
+}
+
+func TestSubmitBulkURLsUpload(t *testing.T) {
+	s := newTestServer(t)
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	file, _ := form.CreateFormFile("file", "urls.csv")
+	file.Write([]byte("url,note\nhttps://a.example.com,first\n\nhttps://b.example.com,second\n"))
+	form.Close()
+
+	req := httptest.NewRequest("POST", "/api/analyses/bulk", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+s.token)
+	w := s.serve(req)
+
+	var resp bulkResponse
+	decode(t, w, &resp)
+	if w.Code != 202 || resp.Accepted != 2 {
+		t.Fatalf("expected 2 accepted, got %d %s", w.Code, w.Body)
+	}
+	if resp.Results[0].Line != 2 || resp.Results[1].Line != 4 {
+		t.Errorf("expected file line numbers, got %+v", resp.Results)
+	}
+}
+
+func TestSubmitBulkURLsSitemap(t *testing.T) {
+	s := newTestServer(t)
+	var sitemaps *httptest.Server
+	sitemaps = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/sitemap.xml":
+			fmt.Fprintf(w, `<sitemapindex><sitemap><loc>%[1]s/pages.xml</loc></sitemap><sitemap><loc>%[1]s/gone.xml</loc></sitemap></sitemapindex>`, sitemaps.URL)
+		case "/pages.xml":
+			fmt.Fprint(w, `<urlset><url><loc>https://example.com/a</loc></url></urlset>`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer sitemaps.Close()
+
+	w := s.do("POST", "/api/analyses/bulk", map[string]string{"sitemap_url": sitemaps.URL + "/sitemap.xml"})
+	var resp bulkResponse
+	decode(t, w, &resp)
+	if w.Code != 202 || resp.Accepted != 1 {
+		t.Fatalf("expected 1 accepted, got %d %s", w.Code, w.Body)
+	}
+	if len(resp.SitemapErrors) != 1 || !strings.Contains(resp.SitemapErrors[0], "gone.xml") || resp.SitemapTruncated {
+		t.Errorf("expected the missing child sitemap to be reported, got %+v", resp)
+	}
+}
+
+func TestSubmitBulkURLsValidation(t *testing.T) {
+	s := newTestServer(t)
+
+	expectError(t, s.do("POST", "/api/analyses/bulk", []string{}), 400, "no_urls")
+	expectError(t, s.do("POST", "/api/analyses/bulk", bytes.NewReader([]byte("{"))), 400, "invalid_request")
+	expectError(t, s.do("POST", "/api/analyses/bulk", map[string]interface{}{
+		"urls": []string{"https://example.com"}, "sitemap_url": "https://example.com/sitemap.xml",
+	}), 400, "invalid_request")
+
+	urls := make([]string, maxBulkURLs+1)
+	for i := range urls {
+		urls[i] = "https://example.com"
+	}
+	expectError(t, s.do("POST", "/api/analyses/bulk", urls), 400, "too_many_urls")
+
+	// Padding past the limit, in an otherwise valid array
+	large := append([]byte(`["https://example.com"`), bytes.Repeat([]byte(" "), maxBulkUploadSize)...)
+	large = append(large, ']')
+	expectError(t, s.do("POST", "/api/analyses/bulk", bytes.NewReader(large)), 413, "request_too_large")
+}
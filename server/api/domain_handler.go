@@ -0,0 +1,99 @@
+package api
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/saqibroy/web-crawler-dashboard/server/domains"
+	"github.com/saqibroy/web-crawler-dashboard/server/repository"
+	"github.com/saqibroy/web-crawler-dashboard/server/trends"
+)
+
+const maxDomainLimit = 100
+
+// GetDomains lists the domains analyses were made for, with their page
+// counts, outcomes, broken links and when they were last analysed. sort
+// takes one of repository.DomainSortColumns, prefixed with "-" to sort
+// descending.
+func (h *Handler) GetDomains(c *gin.Context) {
+	page, err := parseIntParam(c, "page", 1)
+	if err != nil {
+		errorResponse(c, 400, "invalid_page", "Invalid page number")
+		return
+	}
+
+	limit, err := parseIntParam(c, "limit", 20)
+	if err != nil || limit > maxDomainLimit {
+		errorResponse(c, 400, "invalid_limit", "Limit must be between 1 and 100")
+		return
+	}
+
+	filter := repository.DomainFilter{
+		Search: c.Query("search"),
+		Offset: (page - 1) * limit,
+		Limit:  limit,
+	}
+	if sort := c.Query("sort"); sort != "" {
+		field := repository.SortField{Column: strings.TrimPrefix(sort, "-"), Desc: strings.HasPrefix(sort, "-")}
+		if !repository.IsDomainSortColumn(field.Column) {
+			errorResponse(c, 400, "invalid_sort", "Invalid sort column", repository.DomainSortColumns)
+			return
+		}
+		filter.Sort = &field
+	}
+
+	stats, total, err := h.analyses.Domains(c.Request.Context(), filter)
+	if err != nil {
+		errorResponse(c, 500, "db_query_failed", "Failed to query domains", err.Error())
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"data":        stats,
+		"total_count": total,
+	})
+}
+
+// GetDomain reports on the health of one domain, excluding its subdomains:
+// its statistics, the broken links on its pages, why its analyses failed and
+// a trend of analyses bucketed by period (hour, day, week or month), optionally
+// limited to analyses submitted between from and to.
+func (h *Handler) GetDomain(c *gin.Context) {
+	domain := strings.ToLower(c.Param("domain"))
+
+	opts := domains.TrendOptions{Period: trends.Period(c.DefaultQuery("period", string(trends.Day)))}
+	if !opts.Period.Valid() {
+		errorResponse(c, 400, "invalid_period", "Invalid trend period", trends.Periods)
+		return
+	}
+	var err error
+	for _, bound := range []struct {
+		param string
+		end   bool
+		dest  *time.Time
+	}{
+		{"from", false, &opts.From},
+		{"to", true, &opts.To},
+	} {
+		if value := c.Query(bound.param); value != "" {
+			if *bound.dest, err = parseTimeBound(value, bound.end); err != nil {
+				errorResponse(c, 400, "invalid_filter", bound.param+" must be a date (YYYY-MM-DD) or RFC 3339 timestamp")
+				return
+			}
+		}
+	}
+
+	report, err := domains.BuildReport(c.Request.Context(), h.analyses, domain, opts)
+	if errors.Is(err, repository.ErrNotFound) {
+		errorResponse(c, 404, "not_found", "No analyses for this domain")
+		return
+	}
+	if err != nil {
+		errorResponse(c, 500, "db_query_failed", "Failed to build domain report", err.Error())
+		return
+	}
+
+	c.JSON(200, report)
+}
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/saqibroy/web-crawler-dashboard/server/domains"
+	"github.com/saqibroy/web-crawler-dashboard/server/models"
+	"github.com/saqibroy/web-crawler-dashboard/server/repository"
+)
+
+func TestDomains(t *testing.T) {
+	s := newTestServer(t)
+	target := crawlTarget(t)
+
+	s.submit(gin.H{"url": target.URL})
+	missing := s.submit(gin.H{"url": target.URL + "/missing"})
+	s.seed(&models.Analysis{URL: "https://example.com", Status: models.Queued})
+	s.process()
+
+	type listResponse struct {
+		Data       []repository.DomainStats `json:"data"`
+		TotalCount int64                    `json:"total_count"`
+	}
+	var list listResponse
+	decode(t, s.do("GET", "/api/domains?sort=-analyses", nil), &list)
+	if list.TotalCount != 2 || list.Data[0].Domain != "127.0.0.1" {
+		t.Fatalf("unexpected domains: %+v", list)
+	}
+	if got := list.Data[0]; got.Analyses != 2 || got.Completed != 1 || got.Failed != 1 || got.BrokenLinks != 1 || got.LastAnalyzedAt == nil {
+		t.Errorf("unexpected stats: %+v", got)
+	}
+
+	list = listResponse{}
+	decode(t, s.do("GET", "/api/domains?search=EXAMPLE", nil), &list)
+	if list.TotalCount != 1 || list.Data[0].Domain != "example.com" {
+		t.Errorf("unexpected search results: %+v", list)
+	}
+
+	var report domains.Report
+	decode(t, s.do("GET", "/api/domains/127.0.0.1?period=week", nil), &report)
+	if report.UniqueBrokenLinks != 1 || len(report.TopBrokenLinks) != 1 || len(report.Trend) != 1 {
+		t.Errorf("unexpected report: %+v", report)
+	}
+	if len(report.FailureReasons) != 1 || report.FailureReasons[0] != (domains.FailureSummary{Reason: "http_404", Count: 1}) {
+		t.Errorf("unexpected failure reasons: %+v", report.FailureReasons)
+	}
+
+	if failed := s.get(missing); failed.FailureReason != "http_404" || failed.ErrorMessage == "" {
+		t.Errorf("expected the failure to be recorded, got %q: %q", failed.FailureReason, failed.ErrorMessage)
+	}
+}
+
+func TestDomainsValidation(t *testing.T) {
+	s := newTestServer(t)
+	s.seed(&models.Analysis{URL: "https://example.com", Status: models.Queued})
+
+	expectError(t, s.do("GET", "/api/domains?limit=101", nil), 400, "invalid_limit")
+	expectError(t, s.do("GET", "/api/domains?sort=-nope", nil), 400, "invalid_sort")
+	expectError(t, s.do("GET", "/api/domains/example.com?period=year", nil), 400, "invalid_period")
+	expectError(t, s.do("GET", "/api/domains/example.com?from=soon", nil), 400, "invalid_filter")
+	expectError(t, s.do("GET", "/api/domains/example.org", nil), 404, "not_found")
+}
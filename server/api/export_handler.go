@@ -0,0 +1,131 @@
+package api
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/saqibroy/web-crawler-dashboard/server/export"
+	"github.com/saqibroy/web-crawler-dashboard/server/models"
+)
+
+const exportFlushEvery = 500
+
+var analysisExportColumns = []string{
+	"id", "url", "status", "html_version", "title",
+	"h1", "h2", "h3", "h4", "h5", "h6",
+	"internal_links", "external_links", "broken_links", "has_login_form",
+	"created_at", "updated_at", "completed_at",
+}
+
+func analysisExportRow(a *models.Analysis) []interface{} {
+	row := []interface{}{a.ID, a.URL, string(a.Status), a.HTMLVersion, a.Title}
+	for i := 1; i <= 6; i++ {
+		row = append(row, headingCount(a.Headings, fmt.Sprintf("h%d", i)))
+	}
+	return append(row,
+		a.InternalLinks, a.ExternalLinks, len(a.BrokenLinks), a.HasLoginForm,
+		a.CreatedAt, a.UpdatedAt, a.CompletedAt,
+	)
+}
+
+// headingCount reads a heading count back out of the JSON column, where it is
+// decoded as float64.
+func headingCount(headings models.JSONMap, tag string) int {
+	switch v := headings[tag].(type) {
+	case float64:
+		return int(v)
+	case int:
+		return v
+	}
+	return 0
+}
+
+func startExport(c *gin.Context, name string) (export.Writer, bool) {
+	format, err := export.ParseFormat(c.DefaultQuery("format", "csv"))
+	if err != nil {
+		errorResponse(c, 400, "invalid_format", "Format must be one of csv, jsonl, xlsx")
+		return nil, false
+	}
+
+	filename := fmt.Sprintf("%s-%s.%s", name, time.Now().Format("20060102-150405"), format.Extension())
+	c.Header("Content-Type", format.ContentType())
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Status(200)
+
+	return export.NewWriter(format, c.Writer), true
+}
+
+// ExportAnalyses streams every analysis matching the list filters. Rows are
+// read with a cursor and flushed as they are written so large exports are
+// never held in memory.
+func (h *Handler) ExportAnalyses(c *gin.Context) {
+	filter, ok := analysisFilter(c)
+	if !ok {
+		return
+	}
+
+	w, ok := startExport(c, "analyses")
+	if !ok {
+		return
+	}
+
+	// Once streaming has started the status is committed, so errors can only
+	// be logged and the response cut short.
+	if err := w.Header(analysisExportColumns); err != nil {
+		log.Printf("Export failed: %v", err)
+		return
+	}
+
+	n := 0
+	err := h.analyses.Each(c.Request.Context(), filter, func(analysis *models.Analysis) error {
+		if err := w.Row(analysisExportRow(analysis)); err != nil {
+			return err
+		}
+		if n++; n%exportFlushEvery == 0 {
+			c.Writer.Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("Export failed: %v", err)
+		return
+	}
+	if err := w.Close(); err != nil {
+		log.Printf("Export failed: %v", err)
+	}
+}
+
+func (h *Handler) ExportBrokenLinks(c *gin.Context) {
+	analysis, ok := h.findAnalysis(c)
+	if !ok {
+		return
+	}
+
+	links := make([]string, 0, len(analysis.BrokenLinks))
+	for link := range analysis.BrokenLinks {
+		links = append(links, link)
+	}
+	sort.Strings(links)
+
+	w, ok := startExport(c, "broken-links-"+analysis.ID)
+	if !ok {
+		return
+	}
+
+	if err := w.Header([]string{"analysis_id", "page_url", "link", "reason"}); err != nil {
+		log.Printf("Export failed: %v", err)
+		return
+	}
+	for _, link := range links {
+		if err := w.Row([]interface{}{analysis.ID, analysis.URL, link, fmt.Sprint(analysis.BrokenLinks[link])}); err != nil {
+			log.Printf("Export failed: %v", err)
+			return
+		}
+	}
+	if err := w.Close(); err != nil {
+		log.Printf("Export failed: %v", err)
+	}
+}
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/saqibroy/web-crawler-dashboard/server/models"
+)
+
+func TestExportAnalyses(t *testing.T) {
+	s := newTestServer(t)
+	s.seed(
+		&models.Analysis{URL: "https://a.example.com", Title: "A", Status: models.Completed, Headings: models.JSONMap{"h1": 2}},
+		&models.Analysis{URL: "https://b.example.com", Title: "B", Status: models.Failed},
+		&models.Analysis{URL: "https://c.example.com", Title: `=HYPERLINK("https://evil.example.com")`, Status: models.Completed, InternalLinks: -1},
+	)
+
+	w := s.do("GET", "/api/analyses/export?status=completed", nil)
+	if w.Code != 200 || !strings.HasPrefix(w.Header().Get("Content-Type"), "text/csv") {
+		t.Fatalf("expected a CSV download, got %d %s", w.Code, w.Header().Get("Content-Type"))
+	}
+	if !strings.Contains(w.Header().Get("Content-Disposition"), `filename="analyses-`) {
+		t.Errorf("unexpected Content-Disposition %q", w.Header().Get("Content-Disposition"))
+	}
+
+	records, err := csv.NewReader(w.Body).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 3 || records[0][1] != "url" {
+		t.Fatalf("unexpected rows: %v", records)
+	}
+	rows := make(map[string][]string)
+	for _, record := range records[1:] {
+		rows[record[1]] = record
+	}
+	if a := rows["https://a.example.com"]; a == nil || a[5] != "2" {
+		t.Errorf("expected an h1 count of 2, got %v", a)
+	}
+	if c := rows["https://c.example.com"]; c == nil || c[4] != `'=HYPERLINK("https://evil.example.com")` || c[11] != "-1" {
+		t.Errorf("expected the formula escaped and numbers kept, got %v", c)
+	}
+
+	w = s.do("GET", "/api/analyses/export?format=jsonl", nil)
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 lines, got %q", w.Body)
+	}
+	var row map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &row); err != nil || row["url"] == nil {
+		t.Errorf("unexpected line %q: %v", lines[0], err)
+	}
+
+	// XLSX strings are inline and never evaluated, so they're kept as they are
+	w = s.do("GET", "/api/analyses/export?format=xlsx", nil)
+	zr, err := zip.NewReader(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sheet, err := zr.Open("xl/worksheets/sheet1.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sheet.Close()
+	body, _ := io.ReadAll(sheet)
+	if !strings.Contains(string(body), `<t xml:space="preserve">=HYPERLINK(&#34;https://evil.example.com&#34;)</t>`) || strings.Contains(string(body), "'") {
+		t.Errorf("expected the title unescaped, got %s", body)
+	}
+
+	expectError(t, s.do("GET", "/api/analyses/export?format=pdf", nil), 400, "invalid_format")
+	expectError(t, s.do("GET", "/api/analyses/export?status=nope", nil), 400, "invalid_status_filter")
+}
+
+func TestExportBrokenLinks(t *testing.T) {
+	s := newTestServer(t)
+	a := &models.Analysis{
+		URL:         "https://example.com",
+		Status:      models.Completed,
+		BrokenLinks: models.JSONMap{"https://example.com/b": 404, "https://example.com/a": 500},
+	}
+	s.seed(a)
+
+	w := s.do("GET", "/api/analyses/"+a.ID+"/broken-links/export", nil)
+	records, err := csv.NewReader(w.Body).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 3 || records[1][2] != "https://example.com/a" || records[1][3] != "500" {
+		t.Errorf("unexpected rows: %v", records)
+	}
+
+	expectError(t, s.do("GET", "/api/analyses/00000000-0000-0000-0000-000000000000/broken-links/export", nil), 404, "not_found")
+}
@@ -0,0 +1,114 @@
+package api
+
+import (
+	"net/url"
+	"sort"
+
+	"github.com/saqibroy/web-crawler-dashboard/server/auth"
+	"github.com/saqibroy/web-crawler-dashboard/server/models"
+	"github.com/saqibroy/web-crawler-dashboard/server/services"
+)
+
+// FetchRequest customises how the submitted URL is requested. Credentials in
+// BasicAuth or embedded in Proxy, and the values of Headers and Cookies, are
+// encrypted before they're stored; only the header and cookie names are
+// returned.
+type FetchRequest struct {
+	Proxy          string            `json:"proxy"`
+	Headers        map[string]string `json:"headers"`
+	Cookies        map[string]string `json:"cookies"`
+	UserAgent      string            `json:"user_agent"`
+	TimeoutSeconds int               `json:"timeout_seconds"`
+	BasicAuth      *struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	} `json:"basic_auth"`
+}
+
+// LoginRequest is a login recipe to run before crawling. Fields maps form
+// field names to the values to submit; the values are encrypted before
+// they're stored and never returned.
+type LoginRequest struct {
+	URL             string            `json:"url"`
+	FormSelector    string            `json:"form_selector"`
+	Fields          map[string]string `json:"fields"`
+	SuccessSelector string            `json:"success_selector"`
+	SuccessText     string            `json:"success_text"`
+	SuccessURL      string            `json:"success_url"`
+}
+
+// resolveRequestSettings validates the submitted fetch settings and login
+// recipe, and splits them into what's stored in the clear and the encrypted
+// credentials.
+func resolveRequestSettings(fetch *FetchRequest, login *LoginRequest) (models.FetchConfig, *models.LoginRecipe, string, error) {
+	var (
+		cfg    models.FetchConfig
+		recipe *models.LoginRecipe
+		creds  models.FetchCredentials
+	)
+
+	if fetch != nil {
+		cfg = models.FetchConfig{
+			Proxy:          fetch.Proxy,
+			HeaderNames:    sortedKeys(fetch.Headers),
+			CookieNames:    sortedKeys(fetch.Cookies),
+			UserAgent:      fetch.UserAgent,
+			TimeoutSeconds: fetch.TimeoutSeconds,
+		}
+		creds.Headers, creds.Cookies = fetch.Headers, fetch.Cookies
+		if fetch.BasicAuth != nil {
+			creds.Username, creds.Password = fetch.BasicAuth.Username, fetch.BasicAuth.Password
+		}
+		if proxy, err := url.Parse(fetch.Proxy); err == nil && proxy.User != nil {
+			creds.ProxyUsername = proxy.User.Username()
+			creds.ProxyPassword, _ = proxy.User.Password()
+			proxy.User = nil
+			cfg.Proxy = proxy.String()
+		}
+	}
+
+	if login != nil {
+		recipe = &models.LoginRecipe{
+			URL:             login.URL,
+			FormSelector:    login.FormSelector,
+			Fields:          make([]string, 0, len(login.Fields)),
+			SuccessSelector: login.SuccessSelector,
+			SuccessText:     login.SuccessText,
+			SuccessURL:      login.SuccessURL,
+		}
+		for name := range login.Fields {
+			recipe.Fields = append(recipe.Fields, name)
+		}
+		sort.Strings(recipe.Fields)
+		creds.LoginFields = login.Fields
+	}
+
+	if err := services.NewRequestConfig(cfg, recipe, creds).Validate(); err != nil {
+		return cfg, nil, "", err
+	}
+
+	if creds.Username == "" && creds.Password == "" && creds.ProxyUsername == "" &&
+		creds.ProxyPassword == "" && len(creds.LoginFields) == 0 && len(creds.Headers) == 0 && len(creds.Cookies) == 0 {
+		return cfg, recipe, "", nil
+	}
+	sealed, err := auth.EncryptJSON(creds)
+	if err != nil {
+		return cfg, nil, "", err
+	}
+	cfg.HasCredentials = true
+	return cfg, recipe, sealed, nil
+}
+
+// sortedKeys returns the names of a map of headers or cookies, or nil when
+// there are none.
+func sortedKeys(m map[string]string) []string {
+	if len(m) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,55 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/saqibroy/web-crawler-dashboard/server/repository"
+	"github.com/saqibroy/web-crawler-dashboard/server/search"
+	"github.com/saqibroy/web-crawler-dashboard/server/worker"
+)
+
+// Handler serves the API from the given repositories, stopping running
+// crawls through the worker.
+type Handler struct {
+	analyses repository.AnalysisRepository
+	ruleSets repository.RuleSetRepository
+	alerts   repository.AlertRepository
+	searcher *search.Searcher
+	worker   *worker.Worker
+}
+
+func NewHandler(analyses repository.AnalysisRepository, ruleSets repository.RuleSetRepository, alerts repository.AlertRepository, searcher *search.Searcher, w *worker.Worker) *Handler {
+	return &Handler{analyses: analyses, ruleSets: ruleSets, alerts: alerts, searcher: searcher, worker: w}
+}
+
+// RegisterRoutes mounts the endpoints on public, which needs no token, and
+// authenticated, which is expected to sit behind auth.AuthMiddleware.
+func (h *Handler) RegisterRoutes(public, authenticated gin.IRoutes) {
+	public.GET("/public/reports/:id", h.GetSharedReport)
+
+	authenticated.POST("/analyses", h.SubmitURL)
+	authenticated.POST("/analyses/bulk", h.SubmitBulkURLs)
+	authenticated.GET("/analyses", h.GetAnalyses)
+	authenticated.GET("/analyses/export", h.ExportAnalyses)
+	authenticated.GET("/analyses/trends", h.GetTrends)
+	authenticated.DELETE("/analyses", h.DeleteAnalyses)
+	authenticated.POST("/analyses/stop", h.StopAnalyses)
+	authenticated.POST("/analyses/rerun", h.RerunAnalyses)
+	authenticated.GET("/analyses/:id", h.GetSingleAnalysis)
+	authenticated.GET("/analyzers", ListAnalyzers)
+	authenticated.GET("/search", h.Search)
+	authenticated.GET("/domains", h.GetDomains)
+	authenticated.GET("/domains/:domain", h.GetDomain)
+	authenticated.POST("/rule-sets", h.CreateRuleSet)
+	authenticated.GET("/rule-sets", h.GetRuleSets)
+	authenticated.GET("/rule-sets/:id", h.GetRuleSet)
+	authenticated.DELETE("/rule-sets/:id", h.DeleteRuleSet)
+	authenticated.POST("/alert-rules", h.CreateAlertRule)
+	authenticated.GET("/alert-rules", h.GetAlertRules)
+	authenticated.GET("/alert-rules/:id", h.GetAlertRule)
+	authenticated.PUT("/alert-rules/:id", h.UpdateAlertRule)
+	authenticated.DELETE("/alert-rules/:id", h.DeleteAlertRule)
+	authenticated.GET("/alerts", h.GetAlerts)
+	authenticated.GET("/analyses/:id/broken-links/export", h.ExportBrokenLinks)
+	authenticated.GET("/analyses/:id/report", h.GetReport)
+	authenticated.POST("/analyses/:id/share", h.CreateShareLink)
+}
@@ -0,0 +1,204 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/saqibroy/web-crawler-dashboard/server/alerts"
+	"github.com/saqibroy/web-crawler-dashboard/server/auth"
+	"github.com/saqibroy/web-crawler-dashboard/server/models"
+	"github.com/saqibroy/web-crawler-dashboard/server/repository"
+	"github.com/saqibroy/web-crawler-dashboard/server/search"
+	"github.com/saqibroy/web-crawler-dashboard/server/worker"
+)
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	os.Exit(m.Run())
+}
+
+// testServer is the API wired to in-memory repositories and search index,
+// with the worker run by hand instead of in the background. Alerts are
+// evaluated as analyses finish.
+type testServer struct {
+	t        *testing.T
+	router   *gin.Engine
+	analyses repository.AnalysisRepository
+	ruleSets *repository.MemoryRuleSetRepository
+	alerts   *repository.MemoryAlertRepository
+	worker   *worker.Worker
+	token    string
+}
+
+func newTestServer(t *testing.T) *testServer {
+	index := search.NewIndex()
+	analyses := search.NewIndexedRepository(repository.NewMemoryAnalysisRepository(), index)
+	ruleSets := repository.NewMemoryRuleSetRepository()
+	alertStore := repository.NewMemoryAlertRepository()
+	engine := alerts.NewEngine(alertStore, alerts.NewDispatcher(alerts.SMTPConfig{}))
+	w := worker.New(analyses)
+	w.OnFinish(func(ctx context.Context, a *models.Analysis) {
+		if err := engine.Evaluate(ctx, a); err != nil {
+			t.Errorf("evaluate alerts: %v", err)
+		}
+		// Deliveries are recorded by the time the analysis is processed
+		engine.Wait()
+	})
+
+	router := gin.New()
+	public := router.Group("/api")
+	authenticated := router.Group("/api")
+	authenticated.Use(auth.AuthMiddleware())
+	NewHandler(analyses, ruleSets, alertStore, search.New(index, analyses), w).RegisterRoutes(public, authenticated)
+
+	token, err := auth.GenerateAccessToken()
+	if err != nil {
+		t.Fatalf("generate token: %v", err)
+	}
+
+	return &testServer{t: t, router: router, analyses: analyses, ruleSets: ruleSets, alerts: alertStore, worker: w, token: token}
+}
+
+// do sends an authenticated request. A non-nil body that isn't an io.Reader
+// is encoded as JSON.
+func (s *testServer) do(method, path string, body interface{}) *httptest.ResponseRecorder {
+	s.t.Helper()
+
+	var reader io.Reader
+	switch b := body.(type) {
+	case nil:
+	case io.Reader:
+		reader = b
+	default:
+		data, err := json.Marshal(b)
+		if err != nil {
+			s.t.Fatalf("encode body: %v", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req := httptest.NewRequest(method, path, reader)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Authorization", "Bearer "+s.token)
+	return s.serve(req)
+}
+
+func (s *testServer) serve(req *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, req)
+	return w
+}
+
+// submit queues an analysis through the API and returns its ID.
+func (s *testServer) submit(body gin.H) string {
+	s.t.Helper()
+
+	w := s.do("POST", "/api/analyses", body)
+	if w.Code != 202 {
+		s.t.Fatalf("submit: expected 202, got %d: %s", w.Code, w.Body)
+	}
+	var resp struct{ ID string }
+	decode(s.t, w, &resp)
+	return resp.ID
+}
+
+// seed stores analyses directly, bypassing the API.
+func (s *testServer) seed(analyses ...*models.Analysis) {
+	s.t.Helper()
+	for _, a := range analyses {
+		if err := s.analyses.Create(context.Background(), a); err != nil {
+			s.t.Fatalf("seed: %v", err)
+		}
+	}
+}
+
+// process crawls everything that's queued.
+func (s *testServer) process() {
+	for s.worker.ProcessNext(context.Background()) {
+	}
+}
+
+func (s *testServer) get(id string) *models.Analysis {
+	s.t.Helper()
+	a, err := s.analyses.Get(context.Background(), id)
+	if err != nil {
+		s.t.Fatalf("get %s: %v", id, err)
+	}
+	return a
+}
+
+func decode(t *testing.T, w *httptest.ResponseRecorder, v interface{}) {
+	t.Helper()
+	if err := json.Unmarshal(w.Body.Bytes(), v); err != nil {
+		t.Fatalf("decode %q: %v", w.Body.String(), err)
+	}
+}
+
+// expectError checks the status and error code of an error response.
+func expectError(t *testing.T, w *httptest.ResponseRecorder, status int, code string) {
+	t.Helper()
+	var resp struct{ Error string }
+	decode(t, w, &resp)
+	if w.Code != status || resp.Error != code {
+		t.Errorf("expected %d %s, got %d %s", status, code, w.Code, w.Body)
+	}
+}
+
+// crawlTarget serves a small site to analyse: a page with a login form, a
+// working and a broken internal link, and a price to extract.
+func crawlTarget(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `<!DOCTYPE html>
+<html lang="en">
+<head><title>Test Shop</title><meta name="description" content="A shop for tests"></head>
+<body>
+<h1>Welcome</h1>
+<h2>Products</h2>
+<p class="price">9.99</p>
+<a href="/about">About</a>
+<a href="/missing">Missing</a>
+<form action="/login" method="post">
+<label for="user">User</label><input id="user" name="user">
+<label for="pass">Password</label><input id="pass" type="password" name="pass">
+<button type="submit">Sign in</button>
+</form>
+</body>
+</html>`)
+	})
+	mux.HandleFunc("/about", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<!DOCTYPE html><html><head><title>About</title></head><body></body></html>`)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestRoutesRequireToken(t *testing.T) {
+	s := newTestServer(t)
+
+	w := s.serve(httptest.NewRequest("GET", "/api/analyses", nil))
+	if w.Code != 401 {
+		t.Errorf("expected 401 without a token, got %d", w.Code)
+	}
+
+	req := httptest.NewRequest("GET", "/api/analyses", nil)
+	req.Header.Set("Authorization", "Bearer not-a-token")
+	if w := s.serve(req); w.Code != 401 {
+		t.Errorf("expected 401 with an invalid token, got %d", w.Code)
+	}
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/saqibroy/web-crawler-dashboard/server/repository"
+)
+
+// pageLink is one relation of a Link header, given as the query parameters
+// that change from the current request.
+type pageLink struct {
+	rel    string
+	params map[string]string
+}
+
+func cursorLinks(page *repository.AnalysisPage) []pageLink {
+	links := []pageLink{{"first", map[string]string{"cursor": ""}}}
+	if page.Prev != "" {
+		links = append(links, pageLink{"prev", map[string]string{"cursor": page.Prev}})
+	}
+	if page.Next != "" {
+		links = append(links, pageLink{"next", map[string]string{"cursor": page.Next}})
+	}
+	return links
+}
+
+func numberedPageLinks(page, limit int, total int64) []pageLink {
+	last := int((total + int64(limit) - 1) / int64(limit))
+	link := func(rel string, n int) pageLink {
+		return pageLink{rel, map[string]string{"page": strconv.Itoa(n)}}
+	}
+
+	links := []pageLink{link("first", 1)}
+	if page > 1 {
+		links = append(links, link("prev", min(page-1, max(last, 1))))
+	}
+	if page < last {
+		links = append(links, link("next", page+1))
+	}
+	return append(links, link("last", max(last, 1)))
+}
+
+// setPaginationHeaders writes X-Total-Count and an RFC 8288 Link header,
+// with links relative to the current request.
+func setPaginationHeaders(c *gin.Context, total int64, links []pageLink) {
+	c.Header("X-Total-Count", strconv.FormatInt(total, 10))
+
+	values := make([]string, 0, len(links))
+	for _, link := range links {
+		query := c.Request.URL.Query()
+		for param, value := range link.params {
+			if value == "" {
+				query.Del(param)
+			} else {
+				query.Set(param, value)
+			}
+		}
+		target := url.URL{Path: c.Request.URL.Path, RawQuery: query.Encode()}
+		values = append(values, fmt.Sprintf(`<%s>; rel="%s"`, target.String(), link.rel))
+	}
+	if len(values) > 0 {
+		c.Header("Link", strings.Join(values, ", "))
+	}
+}
+
+// optional gives nil for an empty string, so it encodes as JSON null.
+func optional(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
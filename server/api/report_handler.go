@@ -0,0 +1,138 @@
+package api
+
+import (
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/saqibroy/web-crawler-dashboard/server/auth"
+	"github.com/saqibroy/web-crawler-dashboard/server/models"
+	"github.com/saqibroy/web-crawler-dashboard/server/report"
+)
+
+const (
+	defaultShareTTL = 7 * 24 * time.Hour
+	maxShareTTL     = 30 * 24 * time.Hour
+)
+
+type ShareRequest struct {
+	ExpiresIn int `json:"expires_in"` // seconds
+}
+
+func shareResource(id string) string {
+	return "report:" + id
+}
+
+func (h *Handler) findAnalysis(c *gin.Context) (*models.Analysis, bool) {
+	id := c.Param("id")
+	if len(id) != 36 {
+		errorResponse(c, 400, "invalid_id_format", "Invalid ID format")
+		return nil, false
+	}
+
+	analysis, err := h.analyses.Get(c.Request.Context(), id)
+	if err != nil {
+		errorResponse(c, 404, "not_found", "Analysis not found")
+		return nil, false
+	}
+	return analysis, true
+}
+
+func renderReport(c *gin.Context, analysis *models.Analysis) {
+	var err error
+	switch format := c.DefaultQuery("format", "html"); format {
+	case "html":
+		c.Header("Content-Type", "text/html; charset=utf-8")
+		c.Status(200)
+		err = report.RenderHTML(c.Writer, analysis)
+	case "pdf":
+		c.Header("Content-Type", "application/pdf")
+		c.Header("Content-Disposition", fmt.Sprintf(`inline; filename="report-%s.pdf"`, analysis.ID))
+		c.Status(200)
+		err = report.RenderPDF(c.Writer, analysis)
+	default:
+		errorResponse(c, 400, "invalid_format", "Format must be html or pdf")
+		return
+	}
+
+	if err != nil {
+		log.Printf("Report rendering failed for %s: %v", analysis.ID, err)
+	}
+}
+
+func (h *Handler) GetReport(c *gin.Context) {
+	analysis, ok := h.findAnalysis(c)
+	if !ok {
+		return
+	}
+	renderReport(c, analysis)
+}
+
+// CreateShareLink issues a signed, time-limited link to an analysis report
+// that can be opened without an access token. Links point at PUBLIC_BASE_URL,
+// never at the request's Host header, which the client controls.
+func (h *Handler) CreateShareLink(c *gin.Context) {
+	base := publicBaseURL()
+	if base == "" {
+		errorResponse(c, 503, "share_links_not_configured", "Set PUBLIC_BASE_URL to create share links")
+		return
+	}
+
+	var req ShareRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			errorResponse(c, 400, "invalid_request", "Invalid request format", err.Error())
+			return
+		}
+	}
+
+	ttl := defaultShareTTL
+	if req.ExpiresIn != 0 {
+		ttl = time.Duration(req.ExpiresIn) * time.Second
+		if ttl < time.Minute || ttl > maxShareTTL {
+			errorResponse(c, 400, "invalid_expires_in", fmt.Sprintf("expires_in must be between 60 and %d seconds", int(maxShareTTL.Seconds())))
+			return
+		}
+	}
+
+	analysis, ok := h.findAnalysis(c)
+	if !ok {
+		return
+	}
+
+	expiresAt := time.Now().Add(ttl).Truncate(time.Second)
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(expiresAt.Unix(), 10))
+	query.Set("signature", auth.SignShareLink(shareResource(analysis.ID), expiresAt))
+	path := "/api/public/reports/" + analysis.ID + "?" + query.Encode()
+
+	c.JSON(201, gin.H{
+		"url":        base + path,
+		"expires_at": expiresAt,
+	})
+}
+
+// GetSharedReport serves a report through a signed share link. It is mounted
+// outside AuthMiddleware; the signature is the only credential.
+func (h *Handler) GetSharedReport(c *gin.Context) {
+	id := c.Param("id")
+	if err := auth.VerifyShareLink(shareResource(id), c.Query("expires"), c.Query("signature")); err != nil {
+		errorResponse(c, 403, "invalid_share_link", err.Error())
+		return
+	}
+
+	analysis, ok := h.findAnalysis(c)
+	if !ok {
+		return
+	}
+	renderReport(c, analysis)
+}
+
+func publicBaseURL() string {
+	return strings.TrimSuffix(os.Getenv("PUBLIC_BASE_URL"), "/")
+}
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/saqibroy/web-crawler-dashboard/server/models"
+)
+
+func TestGetReport(t *testing.T) {
+	s := newTestServer(t)
+	a := &models.Analysis{URL: "https://example.com", Title: "Example Report", Status: models.Completed}
+	s.seed(a)
+
+	w := s.do("GET", "/api/analyses/"+a.ID+"/report", nil)
+	if w.Code != 200 || !strings.Contains(w.Body.String(), "Example Report") {
+		t.Errorf("expected an HTML report, got %d", w.Code)
+	}
+
+	w = s.do("GET", "/api/analyses/"+a.ID+"/report?format=pdf", nil)
+	if w.Code != 200 || !bytes.HasPrefix(w.Body.Bytes(), []byte("%PDF")) {
+		t.Errorf("expected a PDF, got %d %q", w.Code, w.Header().Get("Content-Type"))
+	}
+
+	expectError(t, s.do("GET", "/api/analyses/"+a.ID+"/report?format=doc", nil), 400, "invalid_format")
+	expectError(t, s.do("GET", "/api/analyses/00000000-0000-0000-0000-000000000000/report", nil), 404, "not_found")
+}
+
+func TestShareLink(t *testing.T) {
+	s := newTestServer(t)
+	a := &models.Analysis{URL: "https://example.com", Title: "Shared Report", Status: models.Completed}
+	s.seed(a)
+
+	// Links aren't built from the Host header
+	t.Setenv("PUBLIC_BASE_URL", "")
+	expectError(t, s.do("POST", "/api/analyses/"+a.ID+"/share", nil), 503, "share_links_not_configured")
+
+	t.Setenv("PUBLIC_BASE_URL", "https://crawler.example.com/")
+	w := s.do("POST", "/api/analyses/"+a.ID+"/share", gin.H{"expires_in": 3600})
+	if w.Code != 201 {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body)
+	}
+	var resp struct{ URL string }
+	decode(t, w, &resp)
+	if !strings.HasPrefix(resp.URL, "https://crawler.example.com/api/public/reports/"+a.ID+"?") {
+		t.Errorf("unexpected link %s", resp.URL)
+	}
+
+	link, err := url.Parse(resp.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w = s.serve(httptest.NewRequest("GET", link.RequestURI(), nil))
+	if w.Code != 200 || !strings.Contains(w.Body.String(), "Shared Report") {
+		t.Errorf("expected the shared report without a token, got %d", w.Code)
+	}
+
+	query := link.Query()
+	query.Set("signature", "forged")
+	w = s.serve(httptest.NewRequest("GET", link.Path+"?"+query.Encode(), nil))
+	expectError(t, w, 403, "invalid_share_link")
+
+	// The signature is tied to the analysis it was issued for
+	other := &models.Analysis{URL: "https://other.example.com", Status: models.Completed}
+	s.seed(other)
+	w = s.serve(httptest.NewRequest("GET", strings.Replace(link.RequestURI(), a.ID, other.ID, 1), nil))
+	expectError(t, w, 403, "invalid_share_link")
+
+	expectError(t, s.do("POST", "/api/analyses/"+a.ID+"/share", gin.H{"expires_in": 1}), 400, "invalid_expires_in")
+}
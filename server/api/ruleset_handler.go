@@ -0,0 +1,92 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/saqibroy/web-crawler-dashboard/server/models"
+	"github.com/saqibroy/web-crawler-dashboard/server/repository"
+	"github.com/saqibroy/web-crawler-dashboard/server/services"
+)
+
+var errRuleSetNotFound = errors.New("rule set not found")
+
+type RuleSetRequest struct {
+	Name  string                 `json:"name" binding:"required"`
+	Rules models.ExtractionRules `json:"rules" binding:"required"`
+}
+
+func (h *Handler) CreateRuleSet(c *gin.Context) {
+	var req RuleSetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errorResponse(c, 400, "invalid_request", "Invalid request format", err.Error())
+		return
+	}
+
+	if err := services.ValidateExtractionRules(req.Rules); err != nil {
+		errorResponse(c, 400, "invalid_rules", err.Error())
+		return
+	}
+
+	set := models.ExtractionRuleSet{Name: strings.TrimSpace(req.Name), Rules: req.Rules}
+	if err := h.ruleSets.Create(c.Request.Context(), &set); err != nil {
+		errorResponse(c, 500, "db_create_failed", "Failed to save rule set", err.Error())
+		return
+	}
+
+	c.JSON(201, set)
+}
+
+func (h *Handler) GetRuleSets(c *gin.Context) {
+	sets, err := h.ruleSets.List(c.Request.Context())
+	if err != nil {
+		errorResponse(c, 500, "db_query_failed", "Failed to load rule sets", err.Error())
+		return
+	}
+
+	c.JSON(200, gin.H{"data": sets})
+}
+
+func (h *Handler) GetRuleSet(c *gin.Context) {
+	set, err := h.ruleSets.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		errorResponse(c, 404, "not_found", "Rule set not found")
+		return
+	}
+
+	c.JSON(200, set)
+}
+
+func (h *Handler) DeleteRuleSet(c *gin.Context) {
+	err := h.ruleSets.Delete(c.Request.Context(), c.Param("id"))
+	if errors.Is(err, repository.ErrNotFound) {
+		errorResponse(c, 404, "not_found", "Rule set not found")
+		return
+	} else if err != nil {
+		errorResponse(c, 500, "db_delete_failed", "Failed to delete rule set", err.Error())
+		return
+	}
+
+	c.JSON(200, gin.H{"deleted": 1})
+}
+
+// resolveExtractionRules combines a saved rule set with inline rules into the
+// snapshot stored on a new analysis.
+func (h *Handler) resolveExtractionRules(ctx context.Context, ruleSetID string, inline models.ExtractionRules) (models.ExtractionRules, error) {
+	var rules models.ExtractionRules
+	if ruleSetID != "" {
+		set, err := h.ruleSets.Get(ctx, ruleSetID)
+		if err != nil {
+			return nil, errRuleSetNotFound
+		}
+		rules = append(rules, set.Rules...)
+	}
+	rules = append(rules, inline...)
+
+	if err := services.ValidateExtractionRules(rules); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/saqibroy/web-crawler-dashboard/server/models"
+)
+
+func TestRuleSets(t *testing.T) {
+	s := newTestServer(t)
+
+	w := s.do("POST", "/api/rule-sets", gin.H{
+		"name":  "prices",
+		"rules": []gin.H{{"name": "price", "selector": ".price"}},
+	})
+	if w.Code != 201 {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body)
+	}
+	var set models.ExtractionRuleSet
+	decode(t, w, &set)
+
+	var list struct{ Data []models.ExtractionRuleSet }
+	decode(t, s.do("GET", "/api/rule-sets", nil), &list)
+	if len(list.Data) != 1 || list.Data[0].Name != "prices" {
+		t.Errorf("unexpected rule sets: %+v", list.Data)
+	}
+
+	var got models.ExtractionRuleSet
+	decode(t, s.do("GET", "/api/rule-sets/"+set.ID, nil), &got)
+	if len(got.Rules) != 1 || got.Rules[0].Selector != ".price" {
+		t.Errorf("unexpected rules: %+v", got.Rules)
+	}
+
+	if w := s.do("DELETE", "/api/rule-sets/"+set.ID, nil); w.Code != 200 {
+		t.Errorf("expected 200, got %d", w.Code)
+	}
+	expectError(t, s.do("GET", "/api/rule-sets/"+set.ID, nil), 404, "not_found")
+	expectError(t, s.do("DELETE", "/api/rule-sets/"+set.ID, nil), 404, "not_found")
+}
+
+func TestCreateRuleSetValidation(t *testing.T) {
+	s := newTestServer(t)
+
+	expectError(t, s.do("POST", "/api/rule-sets", gin.H{"name": "empty"}), 400, "invalid_request")
+	expectError(t, s.do("POST", "/api/rule-sets", gin.H{
+		"name":  "broken",
+		"rules": []gin.H{{"name": "x", "type": "xpath", "selector": "//["}},
+	}), 400, "invalid_rules")
+}
+
+func TestSubmitWithRuleSet(t *testing.T) {
+	s := newTestServer(t)
+	target := crawlTarget(t)
+
+	w := s.do("POST", "/api/rule-sets", gin.H{
+		"name":  "prices",
+		"rules": []gin.H{{"name": "price", "selector": ".price"}},
+	})
+	var set models.ExtractionRuleSet
+	decode(t, w, &set)
+
+	id := s.submit(gin.H{"url": target.URL, "rule_set_id": set.ID})
+	s.process()
+
+	if got := s.get(id); got.Extracted["price"] != "9.99" {
+		t.Errorf("expected the price to be extracted, got %v", got.Extracted)
+	}
+}
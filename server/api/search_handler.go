@@ -0,0 +1,42 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/saqibroy/web-crawler-dashboard/server/search"
+)
+
+const maxSearchLimit = 100
+
+// Search finds analyses by URL, title and page text. Every word in q must
+// match, and text in double quotes must appear as a phrase. Results are
+// ranked best first, with HTML snippets that wrap matches in <mark>.
+func (h *Handler) Search(c *gin.Context) {
+	query := search.ParseQuery(c.Query("q"))
+	if query.Empty() {
+		errorResponse(c, 400, "invalid_query", "Query must contain at least one word")
+		return
+	}
+
+	page, err := parseIntParam(c, "page", 1)
+	if err != nil {
+		errorResponse(c, 400, "invalid_page", "Invalid page number")
+		return
+	}
+
+	limit, err := parseIntParam(c, "limit", 20)
+	if err != nil || limit > maxSearchLimit {
+		errorResponse(c, 400, "invalid_limit", "Limit must be between 1 and 100")
+		return
+	}
+
+	hits, total, err := h.searcher.Search(c.Request.Context(), query, (page-1)*limit, limit)
+	if err != nil {
+		errorResponse(c, 500, "search_failed", "Failed to search analyses", err.Error())
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"data":        hits,
+		"total_count": total,
+	})
+}
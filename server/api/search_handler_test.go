@@ -0,0 +1,75 @@
+package api
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/saqibroy/web-crawler-dashboard/server/search"
+)
+
+func TestSearch(t *testing.T) {
+	s := newTestServer(t)
+	target := crawlTarget(t)
+
+	id := s.submit(gin.H{"url": target.URL})
+	s.submit(gin.H{"url": "https://products.example.com"})
+	s.process()
+
+	type searchResponse struct {
+		Data       []search.Hit `json:"data"`
+		TotalCount int64        `json:"total_count"`
+	}
+
+	// Page text is only known once the crawl completes
+	var resp searchResponse
+	decode(t, s.do("GET", "/api/search?q=password", nil), &resp)
+	if resp.TotalCount != 1 || resp.Data[0].ID != id {
+		t.Fatalf("expected the crawled page, got %+v", resp)
+	}
+	if resp.Data[0].Title != "Test Shop" || resp.Data[0].Snippet == "" {
+		t.Errorf("unexpected hit: %+v", resp.Data[0])
+	}
+
+	resp = searchResponse{}
+	decode(t, s.do("GET", "/api/search?q=products", nil), &resp)
+	if resp.TotalCount != 2 {
+		t.Errorf("expected body and URL matches, got %+v", resp)
+	}
+
+	resp = searchResponse{}
+	decode(t, s.do("GET", `/api/search?q="sign+in"+welcome`, nil), &resp)
+	if resp.TotalCount != 1 || resp.Data[0].Snippet != "<mark>Welcome</mark> Products 9.99 About Missing User Password <mark>Sign</mark> <mark>in</mark>" {
+		t.Errorf("unexpected phrase results: %+v", resp)
+	}
+
+	resp = searchResponse{}
+	decode(t, s.do("GET", `/api/search?q="in+sign"`, nil), &resp)
+	if resp.TotalCount != 0 || resp.Data == nil {
+		t.Errorf("expected an empty list, got %+v", resp)
+	}
+
+	resp = searchResponse{}
+	decode(t, s.do("GET", "/api/search?q=products&limit=1&page=2", nil), &resp)
+	if resp.TotalCount != 2 || len(resp.Data) != 1 {
+		t.Errorf("unexpected page: %+v", resp)
+	}
+
+	if _, err := s.analyses.Delete(context.Background(), []string{id}); err != nil {
+		t.Fatal(err)
+	}
+	resp = searchResponse{}
+	decode(t, s.do("GET", "/api/search?q=password", nil), &resp)
+	if resp.TotalCount != 0 {
+		t.Errorf("expected deleted analyses to drop out, got %+v", resp)
+	}
+}
+
+func TestSearchValidation(t *testing.T) {
+	s := newTestServer(t)
+
+	expectError(t, s.do("GET", "/api/search", nil), 400, "invalid_query")
+	expectError(t, s.do("GET", "/api/search?q=%22%22", nil), 400, "invalid_query")
+	expectError(t, s.do("GET", "/api/search?q=x&page=0", nil), 400, "invalid_page")
+	expectError(t, s.do("GET", "/api/search?q=x&limit=101", nil), 400, "invalid_limit")
+}
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/saqibroy/web-crawler-dashboard/server/trends"
+)
+
+// trendWindows is how far back a trend reaches when from isn't given.
+var trendWindows = map[trends.Period]time.Duration{
+	trends.Hour:  48 * time.Hour,
+	trends.Day:   30 * 24 * time.Hour,
+	trends.Week:  26 * 7 * 24 * time.Hour,
+	trends.Month: 365 * 24 * time.Hour,
+}
+
+// GetTrends charts the metrics recorded by completed analyses of one url or
+// one domain (excluding its subdomains), or of every analysis when neither is
+// given, bucketed by bucket (hour, day, week or month) between from and to.
+func (h *Handler) GetTrends(c *gin.Context) {
+	query := trends.Query{
+		URL:    c.Query("url"),
+		Domain: strings.ToLower(c.Query("domain")),
+		Period: trends.Period(c.DefaultQuery("bucket", string(trends.Day))),
+	}
+	if query.URL != "" && query.Domain != "" {
+		errorResponse(c, 400, "invalid_filter", "Use either url or domain, not both")
+		return
+	}
+	if !query.Period.Valid() {
+		errorResponse(c, 400, "invalid_period", "Invalid trend bucket", trends.Periods)
+		return
+	}
+
+	var err error
+	for _, bound := range []struct {
+		param string
+		end   bool
+		dest  *time.Time
+	}{
+		{"from", false, &query.From},
+		{"to", true, &query.To},
+	} {
+		if value := c.Query(bound.param); value != "" {
+			if *bound.dest, err = parseTimeBound(value, bound.end); err != nil {
+				errorResponse(c, 400, "invalid_filter", bound.param+" must be a date (YYYY-MM-DD) or RFC 3339 timestamp")
+				return
+			}
+		}
+	}
+	if query.To.IsZero() {
+		query.To = time.Now().UTC()
+	}
+	if query.From.IsZero() {
+		query.From = query.To.Add(-trendWindows[query.Period])
+	}
+	if query.From.After(query.To) {
+		errorResponse(c, 400, "invalid_filter", "from must not be after to")
+		return
+	}
+
+	series, err := trends.Build(c.Request.Context(), h.analyses, query)
+	if errors.Is(err, trends.ErrTooManyBuckets) {
+		errorResponse(c, 400, "invalid_range", fmt.Sprintf("The range spans more than %d buckets", trends.MaxBuckets))
+		return
+	}
+	if err != nil {
+		errorResponse(c, 500, "db_query_failed", "Failed to build trends", err.Error())
+		return
+	}
+
+	c.JSON(200, series)
+}
@@ -0,0 +1,45 @@
+package api
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/saqibroy/web-crawler-dashboard/server/trends"
+)
+
+func TestTrends(t *testing.T) {
+	s := newTestServer(t)
+	target := crawlTarget(t)
+
+	s.submit(gin.H{"url": target.URL})
+	s.submit(gin.H{"url": target.URL})
+	s.submit(gin.H{"url": target.URL + "/missing"})
+	s.process()
+
+	var series trends.Series
+	decode(t, s.do("GET", "/api/analyses/trends?bucket=hour&url="+url.QueryEscape(target.URL), nil), &series)
+	if series.Period != trends.Hour || len(series.Buckets) < 48 {
+		t.Fatalf("expected two days of hourly buckets, got %d", len(series.Buckets))
+	}
+	last := series.Buckets[len(series.Buckets)-1]
+	if last.Samples != 2 || *last.AvgBrokenLinks != 1 || last.AvgCrawlMs == nil {
+		t.Errorf("unexpected bucket: %+v", last)
+	}
+
+	series = trends.Series{}
+	decode(t, s.do("GET", "/api/analyses/trends?domain=127.0.0.1", nil), &series)
+	if len(series.Buckets) < 30 || series.Buckets[len(series.Buckets)-1].Samples != 2 {
+		t.Errorf("expected failed analyses to be left out, got %+v", series.Buckets[len(series.Buckets)-1])
+	}
+}
+
+func TestTrendsValidation(t *testing.T) {
+	s := newTestServer(t)
+
+	expectError(t, s.do("GET", "/api/analyses/trends?url=https://example.com&domain=example.com", nil), 400, "invalid_filter")
+	expectError(t, s.do("GET", "/api/analyses/trends?bucket=year", nil), 400, "invalid_period")
+	expectError(t, s.do("GET", "/api/analyses/trends?from=soon", nil), 400, "invalid_filter")
+	expectError(t, s.do("GET", "/api/analyses/trends?from=2025-03-02&to=2025-03-01", nil), 400, "invalid_filter")
+	expectError(t, s.do("GET", "/api/analyses/trends?bucket=hour&from=2024-01-01&to=2025-01-01", nil), 400, "invalid_range")
+}
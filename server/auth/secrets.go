@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"os"
+)
+
+var ErrUndecryptable = errors.New("stored secret can't be decrypted")
+
+// encryptionKey derives the AES-256 key for secrets stored at rest from
+// ENCRYPTION_KEY, falling back to the JWT secret. Changing the key makes
+// previously stored secrets unreadable.
+func encryptionKey() []byte {
+	secret := []byte(os.Getenv("ENCRYPTION_KEY"))
+	if len(secret) == 0 {
+		secret = getSecretKey()
+	}
+	key := sha256.Sum256(append([]byte("secrets:"), secret...))
+	return key[:]
+}
+
+func newGCM() (cipher.AEAD, error) {
+	block, err := aes.NewCipher(encryptionKey())
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// EncryptJSON encodes v as JSON and seals it with AES-GCM, returning a string
+// suitable for a text column.
+func EncryptJSON(v interface{}) (string, error) {
+	plaintext, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+
+	gcm, err := newGCM()
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := gcm.Seal(nonce, nonce, plaintext, nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// DecryptJSON reverses EncryptJSON into v.
+func DecryptJSON(sealed string, v interface{}) error {
+	raw, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil {
+		return ErrUndecryptable
+	}
+
+	gcm, err := newGCM()
+	if err != nil {
+		return err
+	}
+	if len(raw) < gcm.NonceSize() {
+		return ErrUndecryptable
+	}
+
+	nonce, ciphertext := raw[:gcm.NonceSize()], raw[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return ErrUndecryptable
+	}
+	return json.Unmarshal(plaintext, v)
+}
@@ -0,0 +1,42 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"time"
+)
+
+var (
+	ErrInvalidSignature = errors.New("invalid share link signature")
+	ErrExpiredShareLink = errors.New("share link has expired")
+)
+
+// SignShareLink returns a signature granting read access to a single
+// analysis resource until expiresAt. Signatures are derived from the JWT
+// secret, so rotating JWT_SECRET revokes every outstanding share link.
+func SignShareLink(resource string, expiresAt time.Time) string {
+	mac := hmac.New(sha256.New, getSecretKey())
+	mac.Write([]byte("share:" + resource + ":" + strconv.FormatInt(expiresAt.Unix(), 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyShareLink checks a signature produced by SignShareLink. expires is the
+// Unix timestamp carried alongside the signature in the link.
+func VerifyShareLink(resource, expires, signature string) error {
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	expected := SignShareLink(resource, time.Unix(unix, 0))
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return ErrInvalidSignature
+	}
+	if time.Now().Unix() > unix {
+		return ErrExpiredShareLink
+	}
+	return nil
+}
@@ -0,0 +1,269 @@
+package db
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"log"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+//go:embed migrations/*/*.sql
+var migrationFiles embed.FS
+
+// baselineVersion is the last migration that only captures the schema
+// AutoMigrate used to create, the analyses table with its original columns.
+// Databases set up that way are marked as migrated up to here instead of
+// having the table recreated, and later migrations add everything since.
+const baselineVersion = 1
+
+// Migration is one versioned schema change, read from a pair of
+// NNNN_name.up.sql and NNNN_name.down.sql files in the directory for the
+// database's dialect (mysql, postgres or sqlite). Every dialect has the same
+// versions, so a schema change adds a file pair to each. Statements in a file
+// are separated by a semicolon at the end of a line.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus reports whether a migration has been applied.
+type MigrationStatus struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+type schemaMigration struct {
+	Version   int       `gorm:"primaryKey;autoIncrement:false"`
+	Name      string    `gorm:"size:255;not null"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+func (schemaMigration) TableName() string { return "schema_migrations" }
+
+// Migrations returns the embedded migrations for a dialect in version order.
+func Migrations(dialect string) ([]Migration, error) {
+	files, err := fs.Glob(migrationFiles, path.Join("migrations", dialect, "*.sql"))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no migrations for database dialect %q", dialect)
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, file := range files {
+		base := path.Base(file)
+		name, direction, ok := strings.Cut(strings.TrimSuffix(base, ".sql"), ".")
+		if !ok || (direction != "up" && direction != "down") {
+			return nil, fmt.Errorf("migration %s: expected NNNN_name.up.sql or NNNN_name.down.sql", base)
+		}
+		prefix, label, _ := strings.Cut(name, "_")
+		version, err := strconv.Atoi(prefix)
+		if err != nil {
+			return nil, fmt.Errorf("migration %s: invalid version", base)
+		}
+
+		sql, err := migrationFiles.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		m := byVersion[version]
+		if m == nil {
+			m = &Migration{Version: version, Name: label}
+			byVersion[version] = m
+		} else if m.Name != label {
+			return nil, fmt.Errorf("migration %d: conflicting names %q and %q", version, m.Name, label)
+		}
+		if direction == "up" {
+			m.Up = string(sql)
+		} else {
+			m.Down = string(sql)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %04d_%s: both up and down files are required", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Migrate applies every pending migration in order. Each migration and the
+// record of it run in one transaction, so on PostgreSQL and SQLite a failed
+// migration leaves no trace and can be retried once fixed. MySQL commits each
+// DDL statement as it runs, so a migration failing there partway has to be
+// cleaned up by hand before it's retried.
+func Migrate(db *gorm.DB) error {
+	migrations, err := Migrations(db.Dialector.Name())
+	if err != nil {
+		return err
+	}
+	applied, err := appliedVersions(db)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range migrations {
+		if _, ok := applied[m.Version]; ok {
+			continue
+		}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := runStatements(tx, m.Up); err != nil {
+				return err
+			}
+			return tx.Create(&schemaMigration{Version: m.Version, Name: m.Name, AppliedAt: time.Now()}).Error
+		})
+		if err != nil {
+			return fmt.Errorf("migration %04d_%s failed: %w", m.Version, m.Name, err)
+		}
+		log.Printf("Applied migration %04d_%s", m.Version, m.Name)
+	}
+	return nil
+}
+
+// Rollback reverts the most recently applied migrations, newest first, each
+// in a transaction as for Migrate.
+func Rollback(db *gorm.DB, steps int) error {
+	migrations, err := Migrations(db.Dialector.Name())
+	if err != nil {
+		return err
+	}
+	applied, err := appliedVersions(db)
+	if err != nil {
+		return err
+	}
+
+	for i := len(migrations) - 1; i >= 0 && steps > 0; i-- {
+		m := migrations[i]
+		if _, ok := applied[m.Version]; !ok {
+			continue
+		}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := runStatements(tx, m.Down); err != nil {
+				return err
+			}
+			return tx.Delete(&schemaMigration{}, m.Version).Error
+		})
+		if err != nil {
+			return fmt.Errorf("rollback of %04d_%s failed: %w", m.Version, m.Name, err)
+		}
+		log.Printf("Rolled back migration %04d_%s", m.Version, m.Name)
+		steps--
+	}
+	return nil
+}
+
+// Status lists every migration with the time it was applied, if it was.
+func Status(db *gorm.DB) ([]MigrationStatus, error) {
+	migrations, err := Migrations(db.Dialector.Name())
+	if err != nil {
+		return nil, err
+	}
+	applied, err := appliedVersions(db)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	for _, m := range migrations {
+		s := MigrationStatus{Migration: m}
+		if at, ok := applied[m.Version]; ok {
+			at := at
+			s.AppliedAt = &at
+		}
+		statuses = append(statuses, s)
+	}
+	return statuses, nil
+}
+
+// appliedVersions creates the schema_migrations table if needed and returns
+// the applied versions.
+func appliedVersions(db *gorm.DB) (map[int]time.Time, error) {
+	migrator := db.Migrator()
+	if !migrator.HasTable(&schemaMigration{}) {
+		if err := migrator.CreateTable(&schemaMigration{}); err != nil {
+			return nil, err
+		}
+		if migrator.HasTable("analyses") {
+			if err := adoptBaseline(db); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var rows []schemaMigration
+	if err := db.Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	applied := make(map[int]time.Time, len(rows))
+	for _, r := range rows {
+		applied[r.Version] = r.AppliedAt
+	}
+	return applied, nil
+}
+
+// adoptBaseline records the baseline migrations as applied for a database
+// whose tables were created by AutoMigrate.
+func adoptBaseline(db *gorm.DB) error {
+	migrations, err := Migrations(db.Dialector.Name())
+	if err != nil {
+		return err
+	}
+	for _, m := range migrations {
+		if m.Version > baselineVersion {
+			break
+		}
+		record := schemaMigration{Version: m.Version, Name: m.Name, AppliedAt: time.Now()}
+		if err := db.Create(&record).Error; err != nil {
+			return err
+		}
+	}
+	log.Printf("Existing schema found; marked migrations up to %04d as applied", baselineVersion)
+	return nil
+}
+
+func runStatements(db *gorm.DB, sql string) error {
+	for _, stmt := range splitStatements(sql) {
+		if err := db.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// splitStatements splits a migration file on semicolons that end a line, since
+// the MySQL driver runs one statement per Exec.
+func splitStatements(sql string) []string {
+	var statements []string
+	var current strings.Builder
+	for _, line := range strings.Split(sql, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if current.Len() == 0 && (trimmed == "" || strings.HasPrefix(trimmed, "--")) {
+			continue
+		}
+		current.WriteString(line)
+		current.WriteString("\n")
+		if strings.HasSuffix(trimmed, ";") {
+			statements = append(statements, strings.TrimSuffix(strings.TrimSpace(current.String()), ";"))
+			current.Reset()
+		}
+	}
+	if rest := strings.TrimSpace(current.String()); rest != "" {
+		statements = append(statements, rest)
+	}
+	return statements
+}
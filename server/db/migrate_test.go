@@ -0,0 +1,117 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// baselineAnalysis is the model AutoMigrate created the analyses table from
+// before migrations.
+type baselineAnalysis struct {
+	ID            string `gorm:"type:char(36);primaryKey"`
+	URL           string `gorm:"not null"`
+	Status        string `gorm:"default:queued"`
+	HTMLVersion   string
+	Title         string
+	Headings      string `gorm:"type:json"`
+	InternalLinks int
+	ExternalLinks int
+	BrokenLinks   string `gorm:"type:json"`
+	HasLoginForm  bool
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	CompletedAt   *time.Time
+}
+
+func (baselineAnalysis) TableName() string { return "analyses" }
+
+func openMemory(t *testing.T) *gorm.DB {
+	t.Helper()
+	conn, err := Open("sqlite://:memory:")
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := conn.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return conn
+}
+
+func TestMigrateAdoptsAutoMigrateSchema(t *testing.T) {
+	conn := openMemory(t)
+	if err := conn.AutoMigrate(&baselineAnalysis{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := conn.Create(&baselineAnalysis{ID: "a1", URL: "https://example.com", Status: "completed", Headings: "{}", BrokenLinks: `{"https://example.com/gone": 404}`}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Migrate(conn); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	migrator := conn.Migrator()
+	for _, column := range []string{"render_mode", "sitemap", "fetch_credentials", "security", "domain", "failure_reason"} {
+		if !migrator.HasColumn("analyses", column) {
+			t.Errorf("expected column %s to be added", column)
+		}
+	}
+	for _, table := range []string{"analyzer_results", "extraction_rule_sets", "analysis_metrics", "alerts"} {
+		if !migrator.HasTable(table) {
+			t.Errorf("expected table %s to be created", table)
+		}
+	}
+	var count int64
+	if err := conn.Table("analyses").Where("id = ? AND render_mode = ? AND broken_link_count = ?", "a1", "http", 1).Count(&count).Error; err != nil || count != 1 {
+		t.Errorf("expected the existing analysis to be kept, got %d (%v)", count, err)
+	}
+}
+
+func TestMigrateRollback(t *testing.T) {
+	conn := openMemory(t)
+	if err := Migrate(conn); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	migrations, err := Migrations(conn.Dialector.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Rollback(conn, len(migrations)); err != nil {
+		t.Fatalf("rollback: %v", err)
+	}
+	if conn.Migrator().HasTable("analyses") {
+		t.Error("expected every table to be dropped")
+	}
+	if err := Migrate(conn); err != nil {
+		t.Fatalf("migrate again: %v", err)
+	}
+}
+
+func TestFailedMigrationRolledBack(t *testing.T) {
+	conn := openMemory(t)
+	// 0010 creates its other tables before failing on this one
+	if err := conn.Exec("CREATE TABLE alerts (id INTEGER)").Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := Migrate(conn); err == nil {
+		t.Fatal("expected the migration to fail")
+	}
+	if conn.Migrator().HasTable("alert_rules") {
+		t.Error("expected the failed migration's tables to be rolled back")
+	}
+	var count int64
+	conn.Model(&schemaMigration{}).Where("version = ?", 10).Count(&count)
+	if count != 0 {
+		t.Error("expected the failed migration not to be recorded")
+	}
+
+	if err := conn.Exec("DROP TABLE alerts").Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := Migrate(conn); err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+}
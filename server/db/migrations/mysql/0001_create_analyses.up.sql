@@ -0,0 +1,15 @@
+CREATE TABLE analyses (
+  id CHAR(36) NOT NULL PRIMARY KEY,
+  url VARCHAR(2048) NOT NULL,
+  status ENUM('queued', 'processing', 'completed', 'failed', 'cancelled') NOT NULL DEFAULT 'queued',
+  html_version VARCHAR(64),
+  title TEXT,
+  headings JSON,
+  internal_links INT NOT NULL DEFAULT 0,
+  external_links INT NOT NULL DEFAULT 0,
+  broken_links JSON,
+  has_login_form BOOLEAN NOT NULL DEFAULT FALSE,
+  created_at DATETIME(3),
+  updated_at DATETIME(3),
+  completed_at DATETIME(3) NULL
+);
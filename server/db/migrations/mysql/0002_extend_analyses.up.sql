@@ -0,0 +1,47 @@
+-- Columns and indexes added since the schema AutoMigrate created, which
+-- 0001 matches.
+ALTER TABLE analyses
+  ADD COLUMN render_mode VARCHAR(16) NOT NULL DEFAULT 'http',
+  ADD COLUMN analyzers JSON,
+  ADD COLUMN doctype TEXT,
+  ADD COLUMN auth_forms JSON,
+  ADD COLUMN sitemap JSON,
+  ADD COLUMN meta_description TEXT,
+  ADD COLUMN canonical_url TEXT,
+  ADD COLUMN meta_robots TEXT,
+  ADD COLUMN x_robots_tag TEXT,
+  ADD COLUMN open_graph JSON,
+  ADD COLUMN twitter_card JSON,
+  ADD COLUMN hreflang JSON,
+  ADD COLUMN viewport TEXT,
+  ADD COLUMN charset VARCHAR(64),
+  ADD COLUMN seo_warnings JSON,
+  ADD COLUMN accessibility_score INT NOT NULL DEFAULT 0,
+  ADD COLUMN accessibility_issues JSON,
+  ADD COLUMN extraction_rules JSON,
+  ADD COLUMN extracted JSON,
+  ADD COLUMN fetch_config JSON,
+  ADD COLUMN login_recipe JSON,
+  ADD COLUMN fetch_credentials TEXT,
+  ADD COLUMN redirects JSON,
+  ADD COLUMN redirect_warnings JSON,
+  ADD COLUMN perf_dns_lookup_ms BIGINT NOT NULL DEFAULT 0,
+  ADD COLUMN perf_connect_ms BIGINT NOT NULL DEFAULT 0,
+  ADD COLUMN perf_tls_handshake_ms BIGINT NOT NULL DEFAULT 0,
+  ADD COLUMN perf_ttfb_ms BIGINT NOT NULL DEFAULT 0,
+  ADD COLUMN perf_download_ms BIGINT NOT NULL DEFAULT 0,
+  ADD COLUMN perf_total_ms BIGINT NOT NULL DEFAULT 0,
+  ADD COLUMN perf_transfer_size BIGINT NOT NULL DEFAULT 0,
+  ADD COLUMN perf_page_size BIGINT NOT NULL DEFAULT 0,
+  ADD COLUMN perf_compression_ratio DOUBLE NOT NULL DEFAULT 0,
+  ADD COLUMN perf_script_count INT NOT NULL DEFAULT 0,
+  ADD COLUMN perf_script_bytes BIGINT NOT NULL DEFAULT 0,
+  ADD COLUMN perf_stylesheet_count INT NOT NULL DEFAULT 0,
+  ADD COLUMN perf_stylesheet_bytes BIGINT NOT NULL DEFAULT 0,
+  ADD COLUMN perf_image_count INT NOT NULL DEFAULT 0,
+  ADD COLUMN perf_image_bytes BIGINT NOT NULL DEFAULT 0,
+  ADD COLUMN security_grade VARCHAR(2),
+  ADD COLUMN security JSON;
+
+CREATE INDEX idx_analyses_status ON analyses (status);
+CREATE INDEX idx_analyses_created_at ON analyses (created_at);
@@ -0,0 +1,11 @@
+CREATE TABLE analyzer_results (
+  id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  analysis_id CHAR(36) NOT NULL,
+  analyzer VARCHAR(64) NOT NULL,
+  result JSON,
+  error TEXT,
+  created_at DATETIME(3),
+
+  INDEX idx_analyzer_results_analysis_id (analysis_id),
+  CONSTRAINT fk_analyses_results FOREIGN KEY (analysis_id) REFERENCES analyses (id) ON DELETE CASCADE
+);
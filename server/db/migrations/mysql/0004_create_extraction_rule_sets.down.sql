@@ -0,0 +1 @@
+DROP TABLE extraction_rule_sets;
@@ -0,0 +1,9 @@
+CREATE TABLE extraction_rule_sets (
+  id CHAR(36) NOT NULL PRIMARY KEY,
+  name VARCHAR(255) NOT NULL,
+  rules JSON,
+  created_at DATETIME(3),
+  updated_at DATETIME(3),
+
+  UNIQUE INDEX idx_extraction_rule_sets_name (name)
+);
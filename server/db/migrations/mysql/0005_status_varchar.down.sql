@@ -0,0 +1 @@
+ALTER TABLE analyses MODIFY status ENUM('queued', 'processing', 'completed', 'failed', 'cancelled') NOT NULL DEFAULT 'queued';
@@ -0,0 +1,3 @@
+-- Status is validated by the application, so adding a status doesn't need
+-- an ENUM change, and the column matches the other dialects.
+ALTER TABLE analyses MODIFY status VARCHAR(16) NOT NULL DEFAULT 'queued';
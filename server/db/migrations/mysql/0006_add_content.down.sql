@@ -0,0 +1,2 @@
+ALTER TABLE analyses DROP INDEX ft_analyses_search;
+ALTER TABLE analyses DROP COLUMN content;
@@ -0,0 +1,4 @@
+-- Visible page text for full-text search. The FULLTEXT index covers the
+-- columns searched by MATCH in search.MySQLEngine, which must match exactly.
+ALTER TABLE analyses ADD COLUMN content MEDIUMTEXT;
+ALTER TABLE analyses ADD FULLTEXT INDEX ft_analyses_search (url, title, content);
@@ -0,0 +1,3 @@
+DROP INDEX idx_analyses_domain ON analyses;
+ALTER TABLE analyses DROP COLUMN broken_link_count;
+ALTER TABLE analyses DROP COLUMN domain;
@@ -0,0 +1,18 @@
+-- Columns derived from url and broken_links, so they can be filtered and
+-- sorted on. The application keeps them up to date; existing rows are
+-- backfilled here. The domain is what follows "://", up to the path, query,
+-- fragment or port, after any user info.
+ALTER TABLE analyses ADD COLUMN domain VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE analyses ADD COLUMN broken_link_count INT NOT NULL DEFAULT 0;
+
+UPDATE analyses SET domain = SUBSTR(url, INSTR(url, '://') + 3, 255);
+UPDATE analyses SET domain = SUBSTR(domain, 1, INSTR(domain, '/') - 1) WHERE INSTR(domain, '/') > 0;
+UPDATE analyses SET domain = SUBSTR(domain, 1, INSTR(domain, '?') - 1) WHERE INSTR(domain, '?') > 0;
+UPDATE analyses SET domain = SUBSTR(domain, 1, INSTR(domain, '#') - 1) WHERE INSTR(domain, '#') > 0;
+UPDATE analyses SET domain = SUBSTR(domain, INSTR(domain, '@') + 1) WHERE INSTR(domain, '@') > 0;
+UPDATE analyses SET domain = SUBSTR(domain, 1, INSTR(domain, ':') - 1) WHERE INSTR(domain, ':') > 0;
+UPDATE analyses SET domain = LOWER(domain);
+
+UPDATE analyses SET broken_link_count = COALESCE(JSON_LENGTH(broken_links), 0);
+
+CREATE INDEX idx_analyses_domain ON analyses (domain);
@@ -0,0 +1,4 @@
+-- Why an analysis failed, so failures can be grouped by reason. Analyses that
+-- failed before this migration have no reason.
+ALTER TABLE analyses ADD COLUMN failure_reason VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE analyses ADD COLUMN error_message TEXT;
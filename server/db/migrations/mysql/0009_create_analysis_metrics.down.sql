@@ -0,0 +1,2 @@
+DROP TABLE analysis_metrics;
+ALTER TABLE analyses DROP COLUMN started_at;
@@ -0,0 +1,50 @@
+CREATE TABLE alert_rules (
+  id CHAR(36) NOT NULL PRIMARY KEY,
+  name VARCHAR(255) NOT NULL,
+  scope VARCHAR(16) NOT NULL,
+  target VARCHAR(2048) NOT NULL DEFAULT '',
+  type VARCHAR(32) NOT NULL,
+  threshold INT NOT NULL DEFAULT 0,
+  channel VARCHAR(16) NOT NULL,
+  destination VARCHAR(2048) NOT NULL,
+  enabled BOOLEAN NOT NULL DEFAULT TRUE,
+  created_at DATETIME(3),
+  updated_at DATETIME(3)
+);
+
+-- What each rule remembers about a URL between its analyses.
+CREATE TABLE alert_states (
+  id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  rule_id CHAR(36) NOT NULL,
+  url TEXT NOT NULL,
+  failures INT NOT NULL DEFAULT 0,
+  last_title TEXT,
+  alert_id BIGINT UNSIGNED,
+  updated_at DATETIME(3),
+
+  INDEX idx_alert_states_rule_id (rule_id)
+);
+
+-- Alert history, kept when a rule is deleted.
+CREATE TABLE alerts (
+  id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  rule_id CHAR(36) NOT NULL,
+  rule_name VARCHAR(255) NOT NULL DEFAULT '',
+  analysis_id CHAR(36) NOT NULL DEFAULT '',
+  url TEXT NOT NULL,
+  domain VARCHAR(255) NOT NULL DEFAULT '',
+  type VARCHAR(32) NOT NULL DEFAULT '',
+  message TEXT,
+  fingerprint VARCHAR(255) NOT NULL DEFAULT '',
+  occurrences INT NOT NULL DEFAULT 1,
+  channel VARCHAR(16) NOT NULL DEFAULT '',
+  delivery_status VARCHAR(16) NOT NULL DEFAULT '',
+  delivery_error TEXT,
+  created_at DATETIME(3),
+  last_seen_at DATETIME(3),
+  resolved_at DATETIME(3),
+
+  INDEX idx_alerts_rule_id (rule_id),
+  INDEX idx_alerts_domain (domain),
+  INDEX idx_alerts_created_at (created_at)
+);
@@ -0,0 +1,4 @@
+-- Domain reports look up each domain's completed analyses by completion time,
+-- and the domain alone is covered by the prefix.
+DROP INDEX idx_analyses_domain ON analyses;
+CREATE INDEX idx_analyses_domain_status ON analyses (domain, status, completed_at);
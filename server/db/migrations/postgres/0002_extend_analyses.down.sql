@@ -0,0 +1,44 @@
+DROP INDEX idx_analyses_created_at;
+DROP INDEX idx_analyses_status;
+ALTER TABLE analyses
+  DROP COLUMN render_mode,
+  DROP COLUMN analyzers,
+  DROP COLUMN doctype,
+  DROP COLUMN auth_forms,
+  DROP COLUMN sitemap,
+  DROP COLUMN meta_description,
+  DROP COLUMN canonical_url,
+  DROP COLUMN meta_robots,
+  DROP COLUMN x_robots_tag,
+  DROP COLUMN open_graph,
+  DROP COLUMN twitter_card,
+  DROP COLUMN hreflang,
+  DROP COLUMN viewport,
+  DROP COLUMN charset,
+  DROP COLUMN seo_warnings,
+  DROP COLUMN accessibility_score,
+  DROP COLUMN accessibility_issues,
+  DROP COLUMN extraction_rules,
+  DROP COLUMN extracted,
+  DROP COLUMN fetch_config,
+  DROP COLUMN login_recipe,
+  DROP COLUMN fetch_credentials,
+  DROP COLUMN redirects,
+  DROP COLUMN redirect_warnings,
+  DROP COLUMN perf_dns_lookup_ms,
+  DROP COLUMN perf_connect_ms,
+  DROP COLUMN perf_tls_handshake_ms,
+  DROP COLUMN perf_ttfb_ms,
+  DROP COLUMN perf_download_ms,
+  DROP COLUMN perf_total_ms,
+  DROP COLUMN perf_transfer_size,
+  DROP COLUMN perf_page_size,
+  DROP COLUMN perf_compression_ratio,
+  DROP COLUMN perf_script_count,
+  DROP COLUMN perf_script_bytes,
+  DROP COLUMN perf_stylesheet_count,
+  DROP COLUMN perf_stylesheet_bytes,
+  DROP COLUMN perf_image_count,
+  DROP COLUMN perf_image_bytes,
+  DROP COLUMN security_grade,
+  DROP COLUMN security;
@@ -0,0 +1,10 @@
+CREATE TABLE analyzer_results (
+  id BIGSERIAL PRIMARY KEY,
+  analysis_id CHAR(36) NOT NULL REFERENCES analyses (id) ON DELETE CASCADE,
+  analyzer VARCHAR(64) NOT NULL,
+  result JSON,
+  error TEXT,
+  created_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_analyzer_results_analysis_id ON analyzer_results (analysis_id);
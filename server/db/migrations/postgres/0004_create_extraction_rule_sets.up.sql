@@ -0,0 +1,9 @@
+CREATE TABLE extraction_rule_sets (
+  id CHAR(36) NOT NULL PRIMARY KEY,
+  name VARCHAR(255) NOT NULL,
+  rules JSON,
+  created_at TIMESTAMPTZ,
+  updated_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX idx_extraction_rule_sets_name ON extraction_rule_sets (name);
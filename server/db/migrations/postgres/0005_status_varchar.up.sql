@@ -0,0 +1,2 @@
+-- analyses.status is a plain text column from 0001 on this dialect; only MySQL
+-- needs converting from an ENUM.
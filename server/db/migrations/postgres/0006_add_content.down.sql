@@ -0,0 +1 @@
+ALTER TABLE analyses DROP COLUMN content;
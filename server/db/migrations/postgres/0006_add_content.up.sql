@@ -0,0 +1,3 @@
+-- Visible page text for full-text search. On this dialect it is searched by
+-- the in-process index, so no database index is needed.
+ALTER TABLE analyses ADD COLUMN content TEXT;
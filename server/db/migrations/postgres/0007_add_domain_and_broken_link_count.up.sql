@@ -0,0 +1,19 @@
+-- Columns derived from url and broken_links, so they can be filtered and
+-- sorted on. The application keeps them up to date; existing rows are
+-- backfilled here. The domain is what follows "://", up to the path, query,
+-- fragment or port, after any user info.
+ALTER TABLE analyses ADD COLUMN domain VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE analyses ADD COLUMN broken_link_count INT NOT NULL DEFAULT 0;
+
+UPDATE analyses SET domain = SUBSTR(url, STRPOS(url, '://') + 3, 255);
+UPDATE analyses SET domain = SUBSTR(domain, 1, STRPOS(domain, '/') - 1) WHERE STRPOS(domain, '/') > 0;
+UPDATE analyses SET domain = SUBSTR(domain, 1, STRPOS(domain, '?') - 1) WHERE STRPOS(domain, '?') > 0;
+UPDATE analyses SET domain = SUBSTR(domain, 1, STRPOS(domain, '#') - 1) WHERE STRPOS(domain, '#') > 0;
+UPDATE analyses SET domain = SUBSTR(domain, STRPOS(domain, '@') + 1) WHERE STRPOS(domain, '@') > 0;
+UPDATE analyses SET domain = SUBSTR(domain, 1, STRPOS(domain, ':') - 1) WHERE STRPOS(domain, ':') > 0;
+UPDATE analyses SET domain = LOWER(domain);
+
+UPDATE analyses SET broken_link_count = (SELECT COUNT(*) FROM json_object_keys(broken_links))
+  WHERE json_typeof(broken_links) = 'object';
+
+CREATE INDEX idx_analyses_domain ON analyses (domain);
@@ -0,0 +1,50 @@
+CREATE TABLE alert_rules (
+  id CHAR(36) NOT NULL PRIMARY KEY,
+  name VARCHAR(255) NOT NULL,
+  scope VARCHAR(16) NOT NULL,
+  target VARCHAR(2048) NOT NULL DEFAULT '',
+  type VARCHAR(32) NOT NULL,
+  threshold INT NOT NULL DEFAULT 0,
+  channel VARCHAR(16) NOT NULL,
+  destination VARCHAR(2048) NOT NULL,
+  enabled BOOLEAN NOT NULL DEFAULT TRUE,
+  created_at TIMESTAMPTZ,
+  updated_at TIMESTAMPTZ
+);
+
+-- What each rule remembers about a URL between its analyses.
+CREATE TABLE alert_states (
+  id BIGSERIAL PRIMARY KEY,
+  rule_id CHAR(36) NOT NULL,
+  url TEXT NOT NULL,
+  failures INT NOT NULL DEFAULT 0,
+  last_title TEXT,
+  alert_id BIGINT,
+  updated_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_alert_states_rule_id ON alert_states (rule_id);
+
+-- Alert history, kept when a rule is deleted.
+CREATE TABLE alerts (
+  id BIGSERIAL PRIMARY KEY,
+  rule_id CHAR(36) NOT NULL,
+  rule_name VARCHAR(255) NOT NULL DEFAULT '',
+  analysis_id CHAR(36) NOT NULL DEFAULT '',
+  url TEXT NOT NULL,
+  domain VARCHAR(255) NOT NULL DEFAULT '',
+  type VARCHAR(32) NOT NULL DEFAULT '',
+  message TEXT,
+  fingerprint VARCHAR(255) NOT NULL DEFAULT '',
+  occurrences INT NOT NULL DEFAULT 1,
+  channel VARCHAR(16) NOT NULL DEFAULT '',
+  delivery_status VARCHAR(16) NOT NULL DEFAULT '',
+  delivery_error TEXT,
+  created_at TIMESTAMPTZ,
+  last_seen_at TIMESTAMPTZ,
+  resolved_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_alerts_rule_id ON alerts (rule_id);
+CREATE INDEX idx_alerts_domain ON alerts (domain);
+CREATE INDEX idx_alerts_created_at ON alerts (created_at);
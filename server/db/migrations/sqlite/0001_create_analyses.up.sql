@@ -0,0 +1,15 @@
+CREATE TABLE analyses (
+  id TEXT NOT NULL PRIMARY KEY,
+  url TEXT NOT NULL,
+  status TEXT NOT NULL DEFAULT 'queued',
+  html_version TEXT,
+  title TEXT,
+  headings TEXT,
+  internal_links INTEGER NOT NULL DEFAULT 0,
+  external_links INTEGER NOT NULL DEFAULT 0,
+  broken_links TEXT,
+  has_login_form BOOLEAN NOT NULL DEFAULT 0,
+  created_at DATETIME,
+  updated_at DATETIME,
+  completed_at DATETIME NULL
+);
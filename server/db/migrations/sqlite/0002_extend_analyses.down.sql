@@ -0,0 +1,43 @@
+DROP INDEX idx_analyses_created_at;
+DROP INDEX idx_analyses_status;
+ALTER TABLE analyses DROP COLUMN render_mode;
+ALTER TABLE analyses DROP COLUMN analyzers;
+ALTER TABLE analyses DROP COLUMN doctype;
+ALTER TABLE analyses DROP COLUMN auth_forms;
+ALTER TABLE analyses DROP COLUMN sitemap;
+ALTER TABLE analyses DROP COLUMN meta_description;
+ALTER TABLE analyses DROP COLUMN canonical_url;
+ALTER TABLE analyses DROP COLUMN meta_robots;
+ALTER TABLE analyses DROP COLUMN x_robots_tag;
+ALTER TABLE analyses DROP COLUMN open_graph;
+ALTER TABLE analyses DROP COLUMN twitter_card;
+ALTER TABLE analyses DROP COLUMN hreflang;
+ALTER TABLE analyses DROP COLUMN viewport;
+ALTER TABLE analyses DROP COLUMN charset;
+ALTER TABLE analyses DROP COLUMN seo_warnings;
+ALTER TABLE analyses DROP COLUMN accessibility_score;
+ALTER TABLE analyses DROP COLUMN accessibility_issues;
+ALTER TABLE analyses DROP COLUMN extraction_rules;
+ALTER TABLE analyses DROP COLUMN extracted;
+ALTER TABLE analyses DROP COLUMN fetch_config;
+ALTER TABLE analyses DROP COLUMN login_recipe;
+ALTER TABLE analyses DROP COLUMN fetch_credentials;
+ALTER TABLE analyses DROP COLUMN redirects;
+ALTER TABLE analyses DROP COLUMN redirect_warnings;
+ALTER TABLE analyses DROP COLUMN perf_dns_lookup_ms;
+ALTER TABLE analyses DROP COLUMN perf_connect_ms;
+ALTER TABLE analyses DROP COLUMN perf_tls_handshake_ms;
+ALTER TABLE analyses DROP COLUMN perf_ttfb_ms;
+ALTER TABLE analyses DROP COLUMN perf_download_ms;
+ALTER TABLE analyses DROP COLUMN perf_total_ms;
+ALTER TABLE analyses DROP COLUMN perf_transfer_size;
+ALTER TABLE analyses DROP COLUMN perf_page_size;
+ALTER TABLE analyses DROP COLUMN perf_compression_ratio;
+ALTER TABLE analyses DROP COLUMN perf_script_count;
+ALTER TABLE analyses DROP COLUMN perf_script_bytes;
+ALTER TABLE analyses DROP COLUMN perf_stylesheet_count;
+ALTER TABLE analyses DROP COLUMN perf_stylesheet_bytes;
+ALTER TABLE analyses DROP COLUMN perf_image_count;
+ALTER TABLE analyses DROP COLUMN perf_image_bytes;
+ALTER TABLE analyses DROP COLUMN security_grade;
+ALTER TABLE analyses DROP COLUMN security;
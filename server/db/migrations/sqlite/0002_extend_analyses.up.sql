@@ -0,0 +1,46 @@
+-- Columns and indexes added since the schema AutoMigrate created, which
+-- 0001 matches.
+ALTER TABLE analyses ADD COLUMN render_mode TEXT NOT NULL DEFAULT 'http';
+ALTER TABLE analyses ADD COLUMN analyzers TEXT;
+ALTER TABLE analyses ADD COLUMN doctype TEXT;
+ALTER TABLE analyses ADD COLUMN auth_forms TEXT;
+ALTER TABLE analyses ADD COLUMN sitemap TEXT;
+ALTER TABLE analyses ADD COLUMN meta_description TEXT;
+ALTER TABLE analyses ADD COLUMN canonical_url TEXT;
+ALTER TABLE analyses ADD COLUMN meta_robots TEXT;
+ALTER TABLE analyses ADD COLUMN x_robots_tag TEXT;
+ALTER TABLE analyses ADD COLUMN open_graph TEXT;
+ALTER TABLE analyses ADD COLUMN twitter_card TEXT;
+ALTER TABLE analyses ADD COLUMN hreflang TEXT;
+ALTER TABLE analyses ADD COLUMN viewport TEXT;
+ALTER TABLE analyses ADD COLUMN charset TEXT;
+ALTER TABLE analyses ADD COLUMN seo_warnings TEXT;
+ALTER TABLE analyses ADD COLUMN accessibility_score INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE analyses ADD COLUMN accessibility_issues TEXT;
+ALTER TABLE analyses ADD COLUMN extraction_rules TEXT;
+ALTER TABLE analyses ADD COLUMN extracted TEXT;
+ALTER TABLE analyses ADD COLUMN fetch_config TEXT;
+ALTER TABLE analyses ADD COLUMN login_recipe TEXT;
+ALTER TABLE analyses ADD COLUMN fetch_credentials TEXT;
+ALTER TABLE analyses ADD COLUMN redirects TEXT;
+ALTER TABLE analyses ADD COLUMN redirect_warnings TEXT;
+ALTER TABLE analyses ADD COLUMN perf_dns_lookup_ms INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE analyses ADD COLUMN perf_connect_ms INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE analyses ADD COLUMN perf_tls_handshake_ms INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE analyses ADD COLUMN perf_ttfb_ms INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE analyses ADD COLUMN perf_download_ms INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE analyses ADD COLUMN perf_total_ms INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE analyses ADD COLUMN perf_transfer_size INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE analyses ADD COLUMN perf_page_size INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE analyses ADD COLUMN perf_compression_ratio REAL NOT NULL DEFAULT 0;
+ALTER TABLE analyses ADD COLUMN perf_script_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE analyses ADD COLUMN perf_script_bytes INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE analyses ADD COLUMN perf_stylesheet_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE analyses ADD COLUMN perf_stylesheet_bytes INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE analyses ADD COLUMN perf_image_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE analyses ADD COLUMN perf_image_bytes INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE analyses ADD COLUMN security_grade TEXT;
+ALTER TABLE analyses ADD COLUMN security TEXT;
+
+CREATE INDEX idx_analyses_status ON analyses (status);
+CREATE INDEX idx_analyses_created_at ON analyses (created_at);
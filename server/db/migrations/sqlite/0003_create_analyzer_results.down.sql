@@ -0,0 +1 @@
+DROP TABLE analyzer_results;
@@ -0,0 +1,10 @@
+CREATE TABLE analyzer_results (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  analysis_id TEXT NOT NULL REFERENCES analyses (id) ON DELETE CASCADE,
+  analyzer TEXT NOT NULL,
+  result TEXT,
+  error TEXT,
+  created_at DATETIME
+);
+
+CREATE INDEX idx_analyzer_results_analysis_id ON analyzer_results (analysis_id);
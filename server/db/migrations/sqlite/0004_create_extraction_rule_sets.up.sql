@@ -0,0 +1,9 @@
+CREATE TABLE extraction_rule_sets (
+  id TEXT NOT NULL PRIMARY KEY,
+  name TEXT NOT NULL,
+  rules TEXT,
+  created_at DATETIME,
+  updated_at DATETIME
+);
+
+CREATE UNIQUE INDEX idx_extraction_rule_sets_name ON extraction_rule_sets (name);
@@ -0,0 +1,2 @@
+ALTER TABLE analyses DROP COLUMN error_message;
+ALTER TABLE analyses DROP COLUMN failure_reason;
@@ -0,0 +1,25 @@
+-- When the worker last picked each analysis up, to time its crawl.
+ALTER TABLE analyses ADD COLUMN started_at DATETIME;
+
+-- A sample of each analysis's numbers every time it completes, for charting
+-- trends. Samples are kept when the analysis is re-run or deleted.
+CREATE TABLE analysis_metrics (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  analysis_id TEXT NOT NULL,
+  url TEXT NOT NULL,
+  domain TEXT NOT NULL DEFAULT '',
+  recorded_at DATETIME NOT NULL,
+  internal_links INTEGER NOT NULL DEFAULT 0,
+  external_links INTEGER NOT NULL DEFAULT 0,
+  broken_links INTEGER NOT NULL DEFAULT 0,
+  crawl_ms INTEGER,
+  page_load_ms INTEGER
+);
+
+CREATE INDEX idx_analysis_metrics_domain_recorded_at ON analysis_metrics (domain, recorded_at);
+CREATE INDEX idx_analysis_metrics_recorded_at ON analysis_metrics (recorded_at);
+
+-- Analyses completed so far give one sample each, without a crawl time
+INSERT INTO analysis_metrics (analysis_id, url, domain, recorded_at, internal_links, external_links, broken_links, page_load_ms)
+SELECT id, url, domain, completed_at, internal_links, external_links, broken_link_count, NULLIF(perf_total_ms, 0)
+FROM analyses WHERE status = 'completed' AND completed_at IS NOT NULL;
@@ -0,0 +1,50 @@
+CREATE TABLE alert_rules (
+  id TEXT NOT NULL PRIMARY KEY,
+  name TEXT NOT NULL,
+  scope TEXT NOT NULL,
+  target TEXT NOT NULL DEFAULT '',
+  type TEXT NOT NULL,
+  threshold INTEGER NOT NULL DEFAULT 0,
+  channel TEXT NOT NULL,
+  destination TEXT NOT NULL,
+  enabled BOOLEAN NOT NULL DEFAULT 1,
+  created_at DATETIME,
+  updated_at DATETIME
+);
+
+-- What each rule remembers about a URL between its analyses.
+CREATE TABLE alert_states (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  rule_id TEXT NOT NULL,
+  url TEXT NOT NULL,
+  failures INTEGER NOT NULL DEFAULT 0,
+  last_title TEXT,
+  alert_id INTEGER,
+  updated_at DATETIME
+);
+
+CREATE INDEX idx_alert_states_rule_id ON alert_states (rule_id);
+
+-- Alert history, kept when a rule is deleted.
+CREATE TABLE alerts (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  rule_id TEXT NOT NULL,
+  rule_name TEXT NOT NULL DEFAULT '',
+  analysis_id TEXT NOT NULL DEFAULT '',
+  url TEXT NOT NULL,
+  domain TEXT NOT NULL DEFAULT '',
+  type TEXT NOT NULL DEFAULT '',
+  message TEXT,
+  fingerprint TEXT NOT NULL DEFAULT '',
+  occurrences INTEGER NOT NULL DEFAULT 1,
+  channel TEXT NOT NULL DEFAULT '',
+  delivery_status TEXT NOT NULL DEFAULT '',
+  delivery_error TEXT,
+  created_at DATETIME,
+  last_seen_at DATETIME,
+  resolved_at DATETIME
+);
+
+CREATE INDEX idx_alerts_rule_id ON alerts (rule_id);
+CREATE INDEX idx_alerts_domain ON alerts (domain);
+CREATE INDEX idx_alerts_created_at ON alerts (created_at);
@@ -0,0 +1,2 @@
+DROP INDEX idx_analyses_domain_status;
+CREATE INDEX idx_analyses_domain ON analyses (domain);
@@ -0,0 +1,193 @@
+package domains
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/saqibroy/web-crawler-dashboard/server/models"
+	"github.com/saqibroy/web-crawler-dashboard/server/repository"
+	"github.com/saqibroy/web-crawler-dashboard/server/trends"
+)
+
+// maxBrokenLinks caps the broken links listed in a report.
+const maxBrokenLinks = 10
+
+// unknownReason stands in for failures recorded without a reason.
+const unknownReason = "unknown"
+
+// Report describes the health of one domain across the analyses of its
+// pages.
+type Report struct {
+	repository.DomainStats
+	// UniqueBrokenLinks counts the distinct broken links found by the
+	// latest completed analysis of each page, and TopBrokenLinks lists the
+	// ones found on the most pages.
+	UniqueBrokenLinks int              `json:"unique_broken_links"`
+	TopBrokenLinks    []BrokenLink     `json:"top_broken_links"`
+	FailureReasons    []FailureSummary `json:"failure_reasons"`
+	Trend             []TrendBucket    `json:"trend"`
+}
+
+type BrokenLink struct {
+	URL   string `json:"url"`
+	Pages int    `json:"pages"`
+}
+
+// FailureSummary counts the failed analyses with one failure reason.
+type FailureSummary struct {
+	Reason string `json:"reason"`
+	Count  int    `json:"count"`
+}
+
+// TrendBucket summarises the analyses submitted in one period.
+type TrendBucket struct {
+	Start     time.Time `json:"start"`
+	Analyses  int       `json:"analyses"`
+	Completed int       `json:"completed"`
+	Failed    int       `json:"failed"`
+	// AverageBrokenLinks is the mean number of broken links per completed
+	// analysis.
+	AverageBrokenLinks float64 `json:"average_broken_links"`
+}
+
+// TrendOptions selects the trend's bucket width and, when set, the range of
+// submission times it covers.
+type TrendOptions struct {
+	Period   trends.Period
+	From, To time.Time
+}
+
+// BuildReport reports on an exact domain, without its subdomains. It returns
+// repository.ErrNotFound when no analysis was made for the domain.
+func BuildReport(ctx context.Context, analyses repository.AnalysisRepository, domain string, opts TrendOptions) (*Report, error) {
+	stats, _, err := analyses.Domains(ctx, repository.DomainFilter{Domain: domain})
+	if err != nil {
+		return nil, err
+	}
+	if len(stats) == 0 {
+		return nil, repository.ErrNotFound
+	}
+	report := &Report{
+		DomainStats:    stats[0],
+		TopBrokenLinks: []BrokenLink{},
+		FailureReasons: []FailureSummary{},
+		Trend:          []TrendBucket{},
+	}
+
+	latest := make(map[string]*latestAnalysis)
+	reasons := make(map[string]int)
+	buckets := make(map[time.Time]*TrendBucket)
+	brokenLinks := make(map[time.Time]int)
+	err = analyses.Each(ctx, repository.AnalysisFilter{Domain: domain}, func(a *models.Analysis) error {
+		// The filter includes subdomains
+		if a.Domain != domain {
+			return nil
+		}
+
+		switch a.Status {
+		case models.Completed:
+			if l := latest[a.URL]; l == nil || completedAfter(a, l) {
+				latest[a.URL] = newLatestAnalysis(a)
+			}
+		case models.Failed:
+			reason := a.FailureReason
+			if reason == "" {
+				reason = unknownReason
+			}
+			reasons[reason]++
+		}
+
+		if (!opts.From.IsZero() && a.CreatedAt.Before(opts.From)) || (!opts.To.IsZero() && a.CreatedAt.After(opts.To)) {
+			return nil
+		}
+		start := opts.Period.Start(a.CreatedAt)
+		bucket := buckets[start]
+		if bucket == nil {
+			bucket = &TrendBucket{Start: start}
+			buckets[start] = bucket
+		}
+		bucket.Analyses++
+		switch a.Status {
+		case models.Completed:
+			bucket.Completed++
+			brokenLinks[start] += a.BrokenLinkCount
+		case models.Failed:
+			bucket.Failed++
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("read analyses: %w", err)
+	}
+
+	// Broken links by the number of pages they're on
+	pages := make(map[string]int)
+	for _, a := range latest {
+		for _, link := range a.brokenLinks {
+			pages[link]++
+		}
+	}
+	report.UniqueBrokenLinks = len(pages)
+	for link, count := range pages {
+		report.TopBrokenLinks = append(report.TopBrokenLinks, BrokenLink{URL: link, Pages: count})
+	}
+	sort.Slice(report.TopBrokenLinks, func(i, j int) bool {
+		a, b := report.TopBrokenLinks[i], report.TopBrokenLinks[j]
+		if a.Pages != b.Pages {
+			return a.Pages > b.Pages
+		}
+		return a.URL < b.URL
+	})
+	report.TopBrokenLinks = report.TopBrokenLinks[:min(len(report.TopBrokenLinks), maxBrokenLinks)]
+
+	for reason, count := range reasons {
+		report.FailureReasons = append(report.FailureReasons, FailureSummary{Reason: reason, Count: count})
+	}
+	sort.Slice(report.FailureReasons, func(i, j int) bool {
+		a, b := report.FailureReasons[i], report.FailureReasons[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Reason < b.Reason
+	})
+
+	for start, bucket := range buckets {
+		if bucket.Completed > 0 {
+			bucket.AverageBrokenLinks = float64(brokenLinks[start]) / float64(bucket.Completed)
+		}
+		report.Trend = append(report.Trend, *bucket)
+	}
+	sort.Slice(report.Trend, func(i, j int) bool { return report.Trend[i].Start.Before(report.Trend[j].Start) })
+
+	return report, nil
+}
+
+// latestAnalysis is what a report keeps of the latest completed analysis of
+// a page, leaving the rest, such as its content, to be collected.
+type latestAnalysis struct {
+	id          string
+	completedAt *time.Time
+	brokenLinks []string
+}
+
+func newLatestAnalysis(a *models.Analysis) *latestAnalysis {
+	l := &latestAnalysis{id: a.ID, completedAt: a.CompletedAt, brokenLinks: make([]string, 0, len(a.BrokenLinks))}
+	for link := range a.BrokenLinks {
+		l.brokenLinks = append(l.brokenLinks, link)
+	}
+	return l
+}
+
+// completedAfter reports whether a completed after b, breaking ties by ID as
+// the database does.
+func completedAfter(a *models.Analysis, b *latestAnalysis) bool {
+	switch {
+	case a.CompletedAt == nil || b.completedAt == nil:
+		return b.completedAt == nil && a.CompletedAt != nil
+	case !a.CompletedAt.Equal(*b.completedAt):
+		return a.CompletedAt.After(*b.completedAt)
+	}
+	return a.ID > b.id
+}
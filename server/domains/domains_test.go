@@ -0,0 +1,71 @@
+package domains
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/saqibroy/web-crawler-dashboard/server/models"
+	"github.com/saqibroy/web-crawler-dashboard/server/repository"
+	"github.com/saqibroy/web-crawler-dashboard/server/trends"
+)
+
+func TestBuildReport(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryAnalysisRepository()
+	day := func(d int) time.Time { return time.Date(2025, 3, d, 12, 0, 0, 0, time.UTC) }
+	completed := func(d int) *time.Time { at := day(d); return &at }
+
+	analyses := []*models.Analysis{
+		// Only the latest analysis of a page counts towards its broken links
+		{URL: "https://example.com/a", Status: models.Completed, CreatedAt: day(3), CompletedAt: completed(3),
+			BrokenLinks: models.JSONMap{"https://example.com/old": "Broken"}},
+		{URL: "https://example.com/a", Status: models.Completed, CreatedAt: day(4), CompletedAt: completed(4),
+			BrokenLinks: models.JSONMap{"https://example.com/x": "Broken", "https://example.com/y": "Broken"}},
+		{URL: "https://example.com/b", Status: models.Completed, CreatedAt: day(4), CompletedAt: completed(4),
+			BrokenLinks: models.JSONMap{"https://example.com/x": "Broken"}},
+		{URL: "https://example.com/c", Status: models.Failed, FailureReason: "timeout", CreatedAt: day(10)},
+		{URL: "https://example.com/d", Status: models.Failed, FailureReason: "timeout", CreatedAt: day(11)},
+		{URL: "https://example.com/e", Status: models.Failed, CreatedAt: day(11)},
+		// Subdomains have reports of their own
+		{URL: "https://blog.example.com", Status: models.Failed, FailureReason: "dns_error", CreatedAt: day(11)},
+	}
+	for _, a := range analyses {
+		if err := repo.Create(ctx, a); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	report, err := BuildReport(ctx, repo, "example.com", TrendOptions{Period: trends.Week})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Analyses != 6 || report.Pages != 5 || report.BrokenLinks != 3 || report.UniqueBrokenLinks != 2 {
+		t.Errorf("unexpected stats: %+v", report)
+	}
+	if len(report.TopBrokenLinks) != 2 || report.TopBrokenLinks[0] != (BrokenLink{URL: "https://example.com/x", Pages: 2}) {
+		t.Errorf("unexpected broken links: %+v", report.TopBrokenLinks)
+	}
+	wantReasons := []FailureSummary{{"timeout", 2}, {"unknown", 1}}
+	if len(report.FailureReasons) != 2 || report.FailureReasons[0] != wantReasons[0] || report.FailureReasons[1] != wantReasons[1] {
+		t.Errorf("unexpected failure reasons: %+v", report.FailureReasons)
+	}
+
+	want := []TrendBucket{
+		{Start: day(3).Add(-12 * time.Hour), Analyses: 3, Completed: 3, AverageBrokenLinks: 4.0 / 3},
+		{Start: day(10).Add(-12 * time.Hour), Analyses: 3, Failed: 3},
+	}
+	if len(report.Trend) != 2 || report.Trend[0] != want[0] || report.Trend[1] != want[1] {
+		t.Errorf("unexpected trend: %+v", report.Trend)
+	}
+
+	report, _ = BuildReport(ctx, repo, "example.com", TrendOptions{Period: trends.Day, From: day(4), To: day(10)})
+	if len(report.Trend) != 2 || report.Trend[0].Analyses != 2 || report.Trend[1].Analyses != 1 {
+		t.Errorf("unexpected trend within range: %+v", report.Trend)
+	}
+
+	if _, err := BuildReport(ctx, repo, "nowhere.example", TrendOptions{}); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
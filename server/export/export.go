@@ -0,0 +1,151 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Format string
+
+const (
+	CSV   Format = "csv"
+	JSONL Format = "jsonl"
+	XLSX  Format = "xlsx"
+)
+
+// Writer streams tabular records to an underlying io.Writer. Header must be
+// called once before any rows are written, and Close must be called to flush
+// buffered output.
+type Writer interface {
+	Header(columns []string) error
+	Row(values []interface{}) error
+	Close() error
+}
+
+func ParseFormat(value string) (Format, error) {
+	switch f := Format(value); f {
+	case CSV, JSONL, XLSX:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unsupported export format: %q", value)
+	}
+}
+
+func (f Format) ContentType() string {
+	switch f {
+	case CSV:
+		return "text/csv; charset=utf-8"
+	case JSONL:
+		return "application/x-ndjson"
+	case XLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "application/octet-stream"
+}
+
+func (f Format) Extension() string {
+	return string(f)
+}
+
+func NewWriter(f Format, w io.Writer) Writer {
+	switch f {
+	case JSONL:
+		return &jsonlWriter{enc: json.NewEncoder(w)}
+	case XLSX:
+		return newXLSXWriter(w)
+	default:
+		return &csvWriter{w: csv.NewWriter(w)}
+	}
+}
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func (c *csvWriter) Header(columns []string) error {
+	return c.w.Write(columns)
+}
+
+func (c *csvWriter) Row(values []interface{}) error {
+	record := make([]string, len(values))
+	for i, v := range values {
+		record[i] = cellText(v)
+	}
+	return c.w.Write(record)
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+type jsonlWriter struct {
+	enc     *json.Encoder
+	columns []string
+}
+
+func (j *jsonlWriter) Header(columns []string) error {
+	j.columns = columns
+	return nil
+}
+
+func (j *jsonlWriter) Row(values []interface{}) error {
+	record := make(map[string]interface{}, len(values))
+	for i, v := range values {
+		if i < len(j.columns) {
+			record[j.columns[i]] = v
+		}
+	}
+	return j.enc.Encode(record)
+}
+
+func (j *jsonlWriter) Close() error {
+	return nil
+}
+
+// cellText formats a value for a CSV cell. Text starting with a character
+// spreadsheets read as a formula is prefixed with a quote, so a page title
+// such as =HYPERLINK(...) is shown rather than evaluated; numbers keep their
+// sign.
+func cellText(v interface{}) string {
+	switch v.(type) {
+	case nil, bool, int, int64, float64, time.Time, *time.Time:
+		return formatValue(v)
+	}
+	s := formatValue(v)
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+func formatValue(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case bool:
+		return strconv.FormatBool(val)
+	case int:
+		return strconv.Itoa(val)
+	case int64:
+		return strconv.FormatInt(val, 10)
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case time.Time:
+		return val.Format(time.RFC3339)
+	case *time.Time:
+		if val == nil {
+			return ""
+		}
+		return val.Format(time.RFC3339)
+	default:
+		return fmt.Sprint(val)
+	}
+}
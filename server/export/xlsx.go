@@ -0,0 +1,132 @@
+package export
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"io"
+	"strconv"
+	"time"
+)
+
+// The minimal set of parts Excel and LibreOffice need to open a workbook with
+// a single sheet. Cells use inline strings so rows can be streamed without
+// building a shared strings table first.
+const (
+	xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/></Types>`
+	xlsxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`
+	xlsxWorkbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="Export" sheetId="1" r:id="rId1"/></sheets></workbook>`
+	xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/></Relationships>`
+	xlsxSheetStart = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`
+	xlsxSheetEnd = `</sheetData></worksheet>`
+)
+
+type xlsxWriter struct {
+	zw    *zip.Writer
+	sheet *bufio.Writer
+	row   int
+	err   error
+}
+
+func newXLSXWriter(w io.Writer) *xlsxWriter {
+	x := &xlsxWriter{zw: zip.NewWriter(w)}
+
+	for _, part := range []struct{ name, body string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", xlsxWorkbook},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+	} {
+		x.writePart(part.name, part.body)
+	}
+
+	if x.err == nil {
+		var sheet io.Writer
+		sheet, x.err = x.zw.Create("xl/worksheets/sheet1.xml")
+		if x.err == nil {
+			x.sheet = bufio.NewWriter(sheet)
+			_, x.err = x.sheet.WriteString(xlsxSheetStart)
+		}
+	}
+
+	return x
+}
+
+func (x *xlsxWriter) writePart(name, body string) {
+	if x.err != nil {
+		return
+	}
+	var part io.Writer
+	if part, x.err = x.zw.Create(name); x.err == nil {
+		_, x.err = io.WriteString(part, body)
+	}
+}
+
+func (x *xlsxWriter) Header(columns []string) error {
+	values := make([]interface{}, len(columns))
+	for i, c := range columns {
+		values[i] = c
+	}
+	return x.Row(values)
+}
+
+func (x *xlsxWriter) Row(values []interface{}) error {
+	if x.err != nil {
+		return x.err
+	}
+	x.row++
+
+	x.sheet.WriteString(`<row r="` + strconv.Itoa(x.row) + `">`)
+	for _, v := range values {
+		x.writeCell(v)
+	}
+	_, x.err = x.sheet.WriteString(`</row>`)
+	return x.err
+}
+
+func (x *xlsxWriter) writeCell(v interface{}) {
+	switch val := v.(type) {
+	case int, int64, float64:
+		x.sheet.WriteString(`<c><v>` + formatValue(val) + `</v></c>`)
+	case bool:
+		b := "0"
+		if val {
+			b = "1"
+		}
+		x.sheet.WriteString(`<c t="b"><v>` + b + `</v></c>`)
+	case nil:
+		x.sheet.WriteString(`<c/>`)
+	case *time.Time:
+		if val == nil {
+			x.sheet.WriteString(`<c/>`)
+			return
+		}
+		x.writeString(formatValue(val))
+	default:
+		// Inline strings are never evaluated, so they need no escaping
+		x.writeString(formatValue(val))
+	}
+}
+
+func (x *xlsxWriter) writeString(s string) {
+	x.sheet.WriteString(`<c t="inlineStr"><is><t xml:space="preserve">`)
+	xml.EscapeText(x.sheet, []byte(s))
+	x.sheet.WriteString(`</t></is></c>`)
+}
+
+func (x *xlsxWriter) Close() error {
+	if x.err == nil {
+		if _, x.err = x.sheet.WriteString(xlsxSheetEnd); x.err == nil {
+			x.err = x.sheet.Flush()
+		}
+	}
+	if err := x.zw.Close(); x.err == nil {
+		x.err = err
+	}
+	return x.err
+}
@@ -1,23 +1,37 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
+	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
+	"github.com/saqibroy/web-crawler-dashboard/server/alerts"
 	"github.com/saqibroy/web-crawler-dashboard/server/api"
 	"github.com/saqibroy/web-crawler-dashboard/server/auth"
 	"github.com/saqibroy/web-crawler-dashboard/server/db"
 	"github.com/saqibroy/web-crawler-dashboard/server/models"
+	"github.com/saqibroy/web-crawler-dashboard/server/repository"
+	"github.com/saqibroy/web-crawler-dashboard/server/search"
 	"github.com/saqibroy/web-crawler-dashboard/server/worker"
+	"gorm.io/gorm"
 )
 
 func main() {
 	if err := godotenv.Load(); err != nil {
 		log.Println("Warning: No .env file found")
 	}
+
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrateCommand(os.Args[2:]); err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		return
+	}
+
 	gin.SetMode(gin.ReleaseMode)
 	r := gin.Default()
 
@@ -25,7 +39,7 @@ func main() {
 		AllowOrigins:     []string{"http://localhost:4173"},
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
 		AllowHeaders:     []string{"Origin", "Authorization", "Content-Type"},
-		ExposeHeaders:    []string{"Content-Length"},
+		ExposeHeaders:    []string{"Content-Length", "Link", "X-Total-Count"},
 		AllowCredentials: true,
 	}))
 
@@ -43,35 +57,38 @@ func main() {
 		log.Fatalf("Database init failed: %v", err)
 	}
 
-	// Auto-migrate schema for interview/demo
-	if err := db.DB.AutoMigrate(&models.Analysis{}); err != nil {
-		log.Fatalf("AutoMigrate failed: %v", err)
+	if err := db.Migrate(db.DB); err != nil {
+		log.Fatalf("Migration failed: %v", err)
 	}
 
-	// Update status column to allow 'cancelled' value
-	if err := updateStatusColumn(); err != nil {
-		log.Printf("Warning: Failed to update status column: %v", err)
+	var analyses repository.AnalysisRepository = repository.NewGormAnalysisRepository(db.DB)
+	if ttl := statusCountsCacheTTL(); ttl > 0 {
+		analyses = repository.NewStatusCountCache(analyses, ttl)
 	}
-
-	worker.StartWorker()
+	searcher, analyses, err := newSearcher(db.DB, analyses)
+	if err != nil {
+		log.Fatalf("Search index build failed: %v", err)
+	}
+	alertStore := repository.NewGormAlertRepository(db.DB)
+	alertEngine := alerts.NewEngine(alertStore, alerts.NewDispatcher(smtpConfig()))
+	crawler := worker.New(analyses)
+	crawler.OnFinish(func(ctx context.Context, analysis *models.Analysis) {
+		if err := alertEngine.Evaluate(ctx, analysis); err != nil {
+			log.Printf("Alert evaluation failed for %s: %v", analysis.ID, err)
+		}
+	})
+	crawler.Start()
+	handler := api.NewHandler(analyses, repository.NewGormRuleSetRepository(db.DB), alertStore, searcher, crawler)
 
 	// Public routes
 	public := r.Group("/api")
-	{
-		public.POST("/auth/token", generateTokenHandler)
-	}
+	public.POST("/auth/token", generateTokenHandler)
 
 	// Authenticated routes
 	authGroup := r.Group("/api")
 	authGroup.Use(auth.AuthMiddleware())
-	{
-		authGroup.POST("/analyses", api.SubmitURL)
-		authGroup.GET("/analyses", api.GetAnalyses)
-		authGroup.DELETE("/analyses", api.DeleteAnalyses)
-		authGroup.POST("/analyses/stop", api.StopAnalyses)
-		authGroup.POST("/analyses/rerun", api.RerunAnalyses)
-		authGroup.GET("/analyses/:id", api.GetSingleAnalysis)
-	}
+
+	handler.RegisterRoutes(public, authGroup)
 
 	port := os.Getenv("PORT")
 	if port == "" {
@@ -84,6 +101,49 @@ func main() {
 	}
 }
 
+// newSearcher uses MySQL's FULLTEXT index when available. Other databases get
+// an in-memory index, kept current by storing analyses through the returned
+// repository.
+func newSearcher(database *gorm.DB, analyses repository.AnalysisRepository) (*search.Searcher, repository.AnalysisRepository, error) {
+	if database.Dialector.Name() == "mysql" {
+		return search.New(search.NewMySQLEngine(database), analyses), analyses, nil
+	}
+
+	index := search.NewIndex()
+	if err := index.Build(context.Background(), analyses); err != nil {
+		return nil, nil, err
+	}
+	indexed := search.NewIndexedRepository(analyses, index)
+	return search.New(index, indexed), indexed, nil
+}
+
+// statusCountsCacheTTL reads STATUS_COUNTS_CACHE_TTL, such as "5s". Status
+// counts are uncached when it's unset.
+func statusCountsCacheTTL() time.Duration {
+	value := os.Getenv("STATUS_COUNTS_CACHE_TTL")
+	if value == "" {
+		return 0
+	}
+	ttl, err := time.ParseDuration(value)
+	if err != nil {
+		log.Fatalf("Invalid STATUS_COUNTS_CACHE_TTL %q: %v", value, err)
+	}
+	return ttl
+}
+
+// smtpConfig reads the mail server alerts are emailed through from
+// SMTP_HOST, SMTP_PORT (587 by default), SMTP_USERNAME, SMTP_PASSWORD and
+// ALERT_EMAIL_FROM.
+func smtpConfig() alerts.SMTPConfig {
+	return alerts.SMTPConfig{
+		Host:     os.Getenv("SMTP_HOST"),
+		Port:     os.Getenv("SMTP_PORT"),
+		Username: os.Getenv("SMTP_USERNAME"),
+		Password: os.Getenv("SMTP_PASSWORD"),
+		From:     os.Getenv("ALERT_EMAIL_FROM"),
+	}
+}
+
 func setupRoutes(r *gin.Engine) {
 	r.GET("/health", func(c *gin.Context) {
 		c.JSON(200, gin.H{"status": "ok"})
@@ -106,9 +166,3 @@ func generateTokenHandler(c *gin.Context) {
 		"expires_in":   3600, // 1 hour in seconds
 	})
 }
-
-func updateStatusColumn() error {
-	// For MySQL, we need to manually update the ENUM constraint
-	// This is a simple approach - in production you'd want proper migrations
-	return db.DB.Exec("ALTER TABLE analyses MODIFY COLUMN status ENUM('queued', 'processing', 'completed', 'failed', 'cancelled') DEFAULT 'queued'").Error
-}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/saqibroy/web-crawler-dashboard/server/db"
+)
+
+const migrateUsage = "usage: migrate [up | down [steps] | status]"
+
+// runMigrateCommand handles `server migrate ...`, applying or reverting the
+// embedded schema migrations without starting the API.
+func runMigrateCommand(args []string) error {
+	if err := db.Init(); err != nil {
+		return err
+	}
+
+	command := "up"
+	if len(args) > 0 {
+		command = args[0]
+	}
+
+	switch command {
+	case "up":
+		return db.Migrate(db.DB)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				return fmt.Errorf("invalid number of steps %q", args[1])
+			}
+			steps = n
+		}
+		return db.Rollback(db.DB, steps)
+	case "status":
+		statuses, err := db.Status(db.DB)
+		if err != nil {
+			return err
+		}
+		for _, s := range statuses {
+			applied := "pending"
+			if s.AppliedAt != nil {
+				applied = "applied " + s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d_%-40s %s\n", s.Version, s.Name, applied)
+		}
+		return nil
+	default:
+		return errors.New(migrateUsage)
+	}
+}
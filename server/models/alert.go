@@ -0,0 +1,106 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Alert rule types.
+const (
+	// AlertBrokenLinks fires when a completed analysis finds more than
+	// Threshold broken links.
+	AlertBrokenLinks = "broken_links"
+	// AlertConsecutiveFailures fires when a URL's analyses fail Threshold
+	// times in a row.
+	AlertConsecutiveFailures = "consecutive_failures"
+	// AlertTitleChanged fires when a URL's title differs from the one its
+	// previous completed analysis found.
+	AlertTitleChanged = "title_changed"
+	// AlertCertExpiry fires when the site's TLS certificate expires in
+	// fewer than Threshold days.
+	AlertCertExpiry = "cert_expiry"
+)
+
+// Alert rule scopes: the analyses a rule is evaluated for.
+const (
+	AlertScopeURL    = "url"
+	AlertScopeDomain = "domain"
+	AlertScopeAll    = "all"
+)
+
+// Alert delivery channels.
+const (
+	AlertChannelEmail   = "email"
+	AlertChannelSlack   = "slack"
+	AlertChannelWebhook = "webhook"
+)
+
+// AlertRule raises an alert when an analysis in its scope finishes in the
+// condition its type describes. Target is the URL or domain the scope
+// names, and Destination the email addresses (comma-separated) or webhook
+// URL alerts are delivered to.
+type AlertRule struct {
+	ID          string    `gorm:"type:char(36);primaryKey" json:"id"`
+	Name        string    `gorm:"size:255;not null" json:"name"`
+	Scope       string    `gorm:"size:16;not null" json:"scope"`
+	Target      string    `gorm:"size:2048" json:"target"`
+	Type        string    `gorm:"size:32;not null" json:"type"`
+	Threshold   int       `json:"threshold"`
+	Channel     string    `gorm:"size:16;not null" json:"channel"`
+	Destination string    `gorm:"size:2048;not null" json:"destination"`
+	Enabled     bool      `json:"enabled"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func (r *AlertRule) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == "" {
+		r.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// Alert is raised once when a rule's condition starts holding for a URL and
+// stays open, counting further occurrences without being delivered again,
+// until an analysis finds the condition cleared. Alerts are kept when their
+// rule is deleted.
+type Alert struct {
+	ID         uint   `gorm:"primaryKey" json:"id"`
+	RuleID     string `gorm:"type:char(36);not null" json:"rule_id"`
+	RuleName   string `gorm:"size:255" json:"rule_name"`
+	AnalysisID string `gorm:"type:char(36)" json:"analysis_id"`
+	URL        string `gorm:"type:text" json:"url"`
+	Domain     string `gorm:"size:255" json:"domain"`
+	Type       string `gorm:"size:32" json:"type"`
+	Message    string `gorm:"type:text" json:"message"`
+	// Fingerprint tells occurrences of the same problem apart from new
+	// ones, such as a second title change.
+	Fingerprint string `gorm:"size:255" json:"-"`
+	Occurrences int    `json:"occurrences"`
+
+	Channel        string `gorm:"size:16" json:"channel"`
+	DeliveryStatus string `gorm:"size:16" json:"delivery_status"` // "pending", "sent" or "failed"
+	DeliveryError  string `gorm:"type:text" json:"delivery_error,omitempty"`
+
+	CreatedAt  time.Time  `json:"created_at"`
+	LastSeenAt time.Time  `json:"last_seen_at"`
+	ResolvedAt *time.Time `json:"resolved_at"`
+}
+
+// AlertState is what a rule remembers about a URL between its analyses.
+type AlertState struct {
+	ID     uint   `gorm:"primaryKey"`
+	RuleID string `gorm:"type:char(36);not null"`
+	URL    string `gorm:"type:text;not null"`
+	// Failures counts the analyses that failed since the last one that
+	// completed.
+	Failures int
+	// LastTitle is the title of the latest completed analysis, nil before
+	// the first.
+	LastTitle *string `gorm:"type:text"`
+	// AlertID is the rule's open alert for the URL.
+	AlertID   *uint
+	UpdatedAt time.Time
+}
@@ -4,6 +4,8 @@ import (
 	"database/sql/driver"
 	"encoding/json"
 	"fmt"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -20,20 +22,96 @@ const (
 	Cancelled  AnalysisStatus = "cancelled"
 )
 
+// Statuses lists every analysis status. The column is plain text on every
+// database, so this is the source of truth for which values are allowed.
+var Statuses = []AnalysisStatus{Queued, Processing, Completed, Failed, Cancelled}
+
+func (s AnalysisStatus) Valid() bool {
+	for _, status := range Statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
 type Analysis struct {
 	ID            string         `gorm:"type:char(36);primaryKey" json:"id"`
 	URL           string         `gorm:"not null" json:"url"`
+	Domain        string         `gorm:"size:255" json:"domain"`
 	Status        AnalysisStatus `gorm:"default:queued" json:"status"`
+	RenderMode    string         `gorm:"default:http" json:"render_mode"`
+	Analyzers     StringList     `gorm:"type:json" json:"analyzers"`
 	HTMLVersion   string         `json:"html_version"`
+	Doctype       string         `gorm:"type:text" json:"doctype"`
 	Title         string         `json:"title"`
 	Headings      JSONMap        `gorm:"type:json" json:"headings"`
 	InternalLinks int            `json:"internal_links"`
 	ExternalLinks int            `json:"external_links"`
 	BrokenLinks   JSONMap        `gorm:"type:json" json:"broken_links"`
 	HasLoginForm  bool           `json:"has_login_form"`
-	CreatedAt     time.Time      `json:"created_at"`
-	UpdatedAt     time.Time      `json:"updated_at"`
-	CompletedAt   *time.Time     `json:"completed_at"`
+	AuthForms     AuthForms      `gorm:"type:json" json:"auth_forms"`
+	Sitemap       JSONMap        `gorm:"type:json" json:"sitemap"`
+
+	// Derived from BrokenLinks in BeforeSave, so it can be filtered on
+	BrokenLinkCount int `json:"broken_link_count"`
+
+	// Why the analysis failed: a reason code shared by similar errors, so
+	// failures can be grouped, and the error itself
+	FailureReason string `gorm:"size:64" json:"failure_reason,omitempty"`
+	ErrorMessage  string `gorm:"type:text" json:"error_message,omitempty"`
+
+	// Visible page text, kept for full-text search rather than returned
+	Content string `gorm:"type:text" json:"-"`
+
+	// SEO metadata
+	MetaDescription string     `gorm:"type:text" json:"meta_description"`
+	CanonicalURL    string     `gorm:"type:text" json:"canonical_url"`
+	MetaRobots      string     `json:"meta_robots"`
+	XRobotsTag      string     `json:"x_robots_tag"`
+	OpenGraph       JSONMap    `gorm:"type:json" json:"open_graph"`
+	TwitterCard     JSONMap    `gorm:"type:json" json:"twitter_card"`
+	Hreflang        JSONMap    `gorm:"type:json" json:"hreflang"`
+	Viewport        string     `json:"viewport"`
+	Charset         string     `json:"charset"`
+	SEOWarnings     StringList `gorm:"type:json" json:"seo_warnings"`
+
+	// Accessibility audit
+	AccessibilityScore  int                 `json:"accessibility_score"`
+	AccessibilityIssues AccessibilityIssues `gorm:"type:json" json:"accessibility_issues"`
+
+	// Custom extraction
+	ExtractionRules ExtractionRules `gorm:"type:json" json:"extraction_rules"`
+	Extracted       JSONMap         `gorm:"type:json" json:"extracted"`
+
+	// Request settings. FetchCredentials is encrypted and never serialised.
+	FetchConfig      FetchConfig  `gorm:"type:json" json:"fetch_config"`
+	LoginRecipe      *LoginRecipe `gorm:"type:json" json:"login_recipe,omitempty"`
+	FetchCredentials string       `gorm:"type:text" json:"-"`
+
+	// Redirects followed to reach the page
+	Redirects        RedirectChain `gorm:"type:json" json:"redirects"`
+	RedirectWarnings StringList    `gorm:"type:json" json:"redirect_warnings"`
+
+	// Page performance
+	Performance PerformanceMetrics `gorm:"embedded;embeddedPrefix:perf_" json:"performance"`
+
+	// TLS and security headers
+	SecurityGrade string         `gorm:"size:2" json:"security_grade"`
+	Security      SecurityReport `gorm:"type:json" json:"security"`
+
+	// Results holds the output of every analyzer that ran, including ones
+	// without a dedicated column above.
+	Results []AnalyzerResult `gorm:"foreignKey:AnalysisID;constraint:OnDelete:CASCADE" json:"results,omitempty"`
+
+	// Metric is the sample MarkAsCompleted takes, which the repository
+	// stores with the analysis.
+	Metric *AnalysisMetric `gorm:"-" json:"-"`
+
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	StartedAt   *time.Time `json:"started_at"`
+	CompletedAt *time.Time `json:"completed_at"`
 }
 
 func (a *Analysis) BeforeCreate(tx *gorm.DB) error {
@@ -43,48 +121,123 @@ func (a *Analysis) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
-func (a *Analysis) MarkAsProcessing(db *gorm.DB) error {
-	return a.updateStatus(db, Processing)
+// BeforeSave fills in the columns derived from other fields, which exist so
+// the database can filter and sort on them.
+func (a *Analysis) BeforeSave(tx *gorm.DB) error {
+	a.Domain = URLDomain(a.URL)
+	a.BrokenLinkCount = len(a.BrokenLinks)
+	return nil
+}
+
+// URLDomain returns the lower-cased host name of a URL, without the port.
+func URLDomain(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(parsed.Hostname())
 }
 
-func (a *Analysis) MarkAsFailed(db *gorm.DB) error {
-	return a.updateStatus(db, Failed)
+// MarkAsFailed and the other MarkAs methods only change the analysis in
+// memory; the caller saves it through a repository.
+func (a *Analysis) MarkAsFailed(reason, message string) {
+	a.Status = Failed
+	a.FailureReason = reason
+	a.ErrorMessage = message
 }
 
-func (a *Analysis) MarkAsCompleted(db *gorm.DB, result *Analysis) error {
+func (a *Analysis) MarkAsCompleted(result *Analysis) {
 	now := time.Now()
 	a.Status = Completed
 	a.HTMLVersion = result.HTMLVersion
+	a.Doctype = result.Doctype
 	a.Title = result.Title
+	a.Content = result.Content
 	a.Headings = result.Headings
 	a.InternalLinks = result.InternalLinks
 	a.ExternalLinks = result.ExternalLinks
 	a.BrokenLinks = result.BrokenLinks
 	a.HasLoginForm = result.HasLoginForm
+	a.AuthForms = result.AuthForms
+	a.Sitemap = result.Sitemap
+	a.MetaDescription = result.MetaDescription
+	a.CanonicalURL = result.CanonicalURL
+	a.MetaRobots = result.MetaRobots
+	a.XRobotsTag = result.XRobotsTag
+	a.OpenGraph = result.OpenGraph
+	a.TwitterCard = result.TwitterCard
+	a.Hreflang = result.Hreflang
+	a.Viewport = result.Viewport
+	a.Charset = result.Charset
+	a.SEOWarnings = result.SEOWarnings
+	a.AccessibilityScore = result.AccessibilityScore
+	a.AccessibilityIssues = result.AccessibilityIssues
+	a.Extracted = result.Extracted
+	a.Redirects = result.Redirects
+	a.RedirectWarnings = result.RedirectWarnings
+	a.Performance = result.Performance
+	a.SecurityGrade = result.SecurityGrade
+	a.Security = result.Security
 	a.CompletedAt = &now
-	return db.Save(a).Error
+	a.Results = result.Results
+	a.FailureReason = ""
+	a.ErrorMessage = ""
+
+	a.Metric = &AnalysisMetric{
+		RecordedAt:    now,
+		InternalLinks: a.InternalLinks,
+		ExternalLinks: a.ExternalLinks,
+		BrokenLinks:   len(a.BrokenLinks),
+	}
+	if a.StartedAt != nil {
+		crawlMs := now.Sub(*a.StartedAt).Milliseconds()
+		a.Metric.CrawlMs = &crawlMs
+	}
+	if a.Performance.TotalMs > 0 {
+		pageLoadMs := a.Performance.TotalMs
+		a.Metric.PageLoadMs = &pageLoadMs
+	}
 }
 
-func (a *Analysis) MarkAsCancelled(db *gorm.DB) error {
+func (a *Analysis) MarkAsCancelled() {
 	a.Status = Cancelled
 	a.clearResults()
-	return db.Save(a).Error
-}
-
-func (a *Analysis) updateStatus(db *gorm.DB, status AnalysisStatus) error {
-	a.Status = status
-	return db.Save(a).Error
 }
 
 func (a *Analysis) clearResults() {
 	a.Title = ""
+	a.Content = ""
 	a.HTMLVersion = ""
+	a.Doctype = ""
 	a.Headings = JSONMap{}
 	a.InternalLinks = 0
 	a.ExternalLinks = 0
 	a.BrokenLinks = JSONMap{}
 	a.HasLoginForm = false
+	a.AuthForms = AuthForms{}
+	a.Sitemap = JSONMap{}
+	a.MetaDescription = ""
+	a.CanonicalURL = ""
+	a.MetaRobots = ""
+	a.XRobotsTag = ""
+	a.OpenGraph = JSONMap{}
+	a.TwitterCard = JSONMap{}
+	a.Hreflang = JSONMap{}
+	a.Viewport = ""
+	a.Charset = ""
+	a.SEOWarnings = StringList{}
+	a.AccessibilityScore = 0
+	a.AccessibilityIssues = AccessibilityIssues{}
+	a.Extracted = JSONMap{}
+	a.Redirects = RedirectChain{}
+	a.RedirectWarnings = StringList{}
+	a.Performance = PerformanceMetrics{}
+	a.SecurityGrade = ""
+	a.Security = SecurityReport{}
+	a.Results = nil
 	a.CompletedAt = nil
+	a.FailureReason = ""
+	a.ErrorMessage = ""
 }
 
 type JSONMap map[string]interface{}
@@ -95,12 +248,7 @@ func (j *JSONMap) Scan(value interface{}) error {
 		return nil
 	}
 
-	bytes, ok := value.([]byte)
-	if !ok {
-		return fmt.Errorf("failed to unmarshal JSONMap value: %v", value)
-	}
-
-	return json.Unmarshal(bytes, j)
+	return scanJSON(value, j, "JSONMap")
 }
 
 func (j JSONMap) Value() (driver.Value, error) {
@@ -109,3 +257,82 @@ func (j JSONMap) Value() (driver.Value, error) {
 	}
 	return json.Marshal(j)
 }
+
+type StringList []string
+
+func (l *StringList) Scan(value interface{}) error {
+	if value == nil {
+		*l = StringList{}
+		return nil
+	}
+	return scanJSON(value, l, "StringList")
+}
+
+func (l StringList) Value() (driver.Value, error) {
+	if l == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(l)
+}
+
+type AccessibilityIssue struct {
+	Rule     string   `json:"rule"`
+	Severity string   `json:"severity"`
+	Message  string   `json:"message"`
+	Count    int      `json:"count"`
+	Examples []string `json:"examples,omitempty"`
+}
+
+type AccessibilityIssues []AccessibilityIssue
+
+func (a *AccessibilityIssues) Scan(value interface{}) error {
+	if value == nil {
+		*a = AccessibilityIssues{}
+		return nil
+	}
+	return scanJSON(value, a, "AccessibilityIssues")
+}
+
+func (a AccessibilityIssues) Value() (driver.Value, error) {
+	if a == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(a)
+}
+
+// AuthForm is an authentication-related form or SSO button found on a page.
+type AuthForm struct {
+	Type     string   `json:"type"`
+	Selector string   `json:"selector"`
+	Evidence []string `json:"evidence"`
+}
+
+type AuthForms []AuthForm
+
+func (a *AuthForms) Scan(value interface{}) error {
+	if value == nil {
+		*a = AuthForms{}
+		return nil
+	}
+	return scanJSON(value, a, "AuthForms")
+}
+
+func (a AuthForms) Value() (driver.Value, error) {
+	if a == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(a)
+}
+
+// scanJSON decodes a JSON column, which drivers return as either []byte or
+// string.
+func scanJSON(value interface{}, dest interface{}, typeName string) error {
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, dest)
+	case string:
+		return json.Unmarshal([]byte(v), dest)
+	default:
+		return fmt.Errorf("failed to unmarshal %s value: %v", typeName, value)
+	}
+}
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+)
+
+// AnalyzerResult stores the output of one analyzer for one analysis, so new
+// checks can be added without schema changes.
+type AnalyzerResult struct {
+	ID         uint      `gorm:"primaryKey" json:"-"`
+	AnalysisID string    `gorm:"type:char(36);index;not null" json:"-"`
+	Analyzer   string    `gorm:"size:64;not null" json:"analyzer"`
+	Result     JSONRaw   `gorm:"type:json" json:"result"`
+	Error      string    `gorm:"type:text" json:"error,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// JSONRaw is a JSON column holding an arbitrary, already-encoded value.
+type JSONRaw json.RawMessage
+
+func (j *JSONRaw) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*j = JSONRaw("null")
+	case []byte:
+		*j = append(JSONRaw{}, v...)
+	case string:
+		*j = JSONRaw(v)
+	default:
+		return scanJSON(value, j, "JSONRaw")
+	}
+	return nil
+}
+
+func (j JSONRaw) Value() (driver.Value, error) {
+	if len(j) == 0 {
+		return []byte("null"), nil
+	}
+	return []byte(j), nil
+}
+
+func (j JSONRaw) MarshalJSON() ([]byte, error) {
+	if len(j) == 0 {
+		return []byte("null"), nil
+	}
+	return j, nil
+}
+
+func (j *JSONRaw) UnmarshalJSON(data []byte) error {
+	*j = append(JSONRaw{}, data...)
+	return nil
+}
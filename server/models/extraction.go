@@ -0,0 +1,55 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ExtractionRule pulls a named value out of a page with a CSS selector or an
+// XPath expression.
+type ExtractionRule struct {
+	Name      string `json:"name"`
+	Type      string `json:"type"` // "css" (default) or "xpath"
+	Selector  string `json:"selector"`
+	Attribute string `json:"attribute,omitempty"` // empty extracts the text content
+	Multiple  bool   `json:"multiple"`
+}
+
+type ExtractionRules []ExtractionRule
+
+func (r *ExtractionRules) Scan(value interface{}) error {
+	if value == nil {
+		*r = ExtractionRules{}
+		return nil
+	}
+	return scanJSON(value, r, "ExtractionRules")
+}
+
+func (r ExtractionRules) Value() (driver.Value, error) {
+	if r == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(r)
+}
+
+// ExtractionRuleSet is a reusable, named group of extraction rules. Rules are
+// copied onto an analysis when it is submitted, so editing or deleting a set
+// never changes past results.
+type ExtractionRuleSet struct {
+	ID        string          `gorm:"type:char(36);primaryKey" json:"id"`
+	Name      string          `gorm:"size:255;not null;uniqueIndex" json:"name"`
+	Rules     ExtractionRules `gorm:"type:json" json:"rules"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+func (s *ExtractionRuleSet) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == "" {
+		s.ID = uuid.New().String()
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+)
+
+// FetchConfig customises how an analysis's pages are requested. Proxy and
+// basic auth credentials and the values of custom headers and cookies, which
+// often hold tokens, aren't part of it: they're stored encrypted in
+// Analysis.FetchCredentials and never returned by the API. HeaderNames and
+// CookieNames list which ones were set.
+type FetchConfig struct {
+	Proxy          string   `json:"proxy,omitempty"`
+	HeaderNames    []string `json:"header_names,omitempty"`
+	CookieNames    []string `json:"cookie_names,omitempty"`
+	UserAgent      string   `json:"user_agent,omitempty"`
+	TimeoutSeconds int      `json:"timeout_seconds,omitempty"`
+	HasCredentials bool     `json:"has_credentials,omitempty"`
+}
+
+// FetchCredentials are the secrets that go with a FetchConfig and
+// LoginRecipe.
+type FetchCredentials struct {
+	Username      string            `json:"username,omitempty"`
+	Password      string            `json:"password,omitempty"`
+	ProxyUsername string            `json:"proxy_username,omitempty"`
+	ProxyPassword string            `json:"proxy_password,omitempty"`
+	LoginFields   map[string]string `json:"login_fields,omitempty"`
+	Headers       map[string]string `json:"headers,omitempty"`
+	Cookies       map[string]string `json:"cookies,omitempty"`
+}
+
+func (f *FetchConfig) Scan(value interface{}) error {
+	if value == nil {
+		*f = FetchConfig{}
+		return nil
+	}
+	return scanJSON(value, f, "FetchConfig")
+}
+
+func (f FetchConfig) Value() (driver.Value, error) {
+	return json.Marshal(f)
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+)
+
+// LoginRecipe tells the crawler how to sign in before fetching the page. Only
+// the names of the form fields are kept here; their values are stored
+// encrypted with the analysis's other credentials.
+type LoginRecipe struct {
+	URL          string   `json:"url"`
+	FormSelector string   `json:"form_selector,omitempty"`
+	Fields       []string `json:"fields"`
+	// Success checks; when none are set, signing in succeeds if the response
+	// no longer contains a password field.
+	SuccessSelector string `json:"success_selector,omitempty"`
+	SuccessText     string `json:"success_text,omitempty"`
+	SuccessURL      string `json:"success_url,omitempty"`
+}
+
+func (l *LoginRecipe) Scan(value interface{}) error {
+	if value == nil {
+		*l = LoginRecipe{}
+		return nil
+	}
+	return scanJSON(value, l, "LoginRecipe")
+}
+
+func (l LoginRecipe) Value() (driver.Value, error) {
+	return json.Marshal(l)
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// AnalysisMetric is a sample of an analysis's numbers, taken each time it
+// completes so they can be charted over time. Samples are kept when the
+// analysis is re-run or deleted.
+type AnalysisMetric struct {
+	ID            uint      `gorm:"primaryKey" json:"-"`
+	AnalysisID    string    `gorm:"type:char(36);not null" json:"analysis_id"`
+	URL           string    `gorm:"not null" json:"url"`
+	Domain        string    `gorm:"size:255" json:"domain"`
+	RecordedAt    time.Time `json:"recorded_at"`
+	InternalLinks int       `json:"internal_links"`
+	ExternalLinks int       `json:"external_links"`
+	BrokenLinks   int       `json:"broken_links"`
+	// CrawlMs is how long the worker took, from picking the analysis up to
+	// completing it, and PageLoadMs how long the page took to load. Either
+	// is nil when unknown.
+	CrawlMs    *int64 `json:"crawl_ms"`
+	PageLoadMs *int64 `json:"page_load_ms"`
+}
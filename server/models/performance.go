@@ -0,0 +1,22 @@
+package models
+
+// PerformanceMetrics describes how the page loaded. Durations are in
+// milliseconds and sizes in bytes. The fields are stored as perf_* columns so
+// they can be sorted on.
+type PerformanceMetrics struct {
+	DNSLookupMs      int64   `json:"dns_lookup_ms"`
+	ConnectMs        int64   `json:"connect_ms"`
+	TLSHandshakeMs   int64   `json:"tls_handshake_ms"`
+	TTFBMs           int64   `gorm:"column:ttfb_ms" json:"ttfb_ms"`
+	DownloadMs       int64   `json:"download_ms"`
+	TotalMs          int64   `json:"total_ms"`
+	TransferSize     int64   `json:"transfer_size"`
+	PageSize         int64   `json:"page_size"`
+	CompressionRatio float64 `json:"compression_ratio"`
+	ScriptCount      int     `json:"script_count"`
+	ScriptBytes      int64   `json:"script_bytes"`
+	StylesheetCount  int     `json:"stylesheet_count"`
+	StylesheetBytes  int64   `json:"stylesheet_bytes"`
+	ImageCount       int     `json:"image_count"`
+	ImageBytes       int64   `json:"image_bytes"`
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+)
+
+// RedirectHop is one response in the chain that led to the analysed page.
+// The last hop is the page itself.
+type RedirectHop struct {
+	URL        string `json:"url"`
+	StatusCode int    `json:"status_code"`
+	Location   string `json:"location,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+type RedirectChain []RedirectHop
+
+func (r *RedirectChain) Scan(value interface{}) error {
+	if value == nil {
+		*r = RedirectChain{}
+		return nil
+	}
+	return scanJSON(value, r, "RedirectChain")
+}
+
+func (r RedirectChain) Value() (driver.Value, error) {
+	if r == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(r)
+}
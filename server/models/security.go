@@ -0,0 +1,55 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+)
+
+// SecurityReport is the outcome of the TLS, security header and mixed
+// content checks.
+type SecurityReport struct {
+	TLS          *TLSInfo         `json:"tls"`
+	Headers      []SecurityHeader `json:"headers"`
+	MixedContent []string         `json:"mixed_content"`
+}
+
+// TLSInfo describes the connection and certificate chain an HTTPS site
+// served. Chain starts with the site's own certificate.
+type TLSInfo struct {
+	Protocol        string            `json:"protocol"`
+	CipherSuite     string            `json:"cipher_suite"`
+	Valid           bool              `json:"valid"`
+	Error           string            `json:"error,omitempty"`
+	DaysUntilExpiry int               `json:"days_until_expiry"`
+	Chain           []CertificateInfo `json:"chain"`
+}
+
+type CertificateInfo struct {
+	Subject   string    `json:"subject"`
+	Issuer    string    `json:"issuer"`
+	NotBefore time.Time `json:"not_before"`
+	NotAfter  time.Time `json:"not_after"`
+	SANs      []string  `json:"sans,omitempty"`
+}
+
+// SecurityHeader is the grade given to one response header: pass, warn or
+// fail.
+type SecurityHeader struct {
+	Name    string `json:"name"`
+	Value   string `json:"value"`
+	Grade   string `json:"grade"`
+	Message string `json:"message,omitempty"`
+}
+
+func (r *SecurityReport) Scan(value interface{}) error {
+	if value == nil {
+		*r = SecurityReport{}
+		return nil
+	}
+	return scanJSON(value, r, "SecurityReport")
+}
+
+func (r SecurityReport) Value() (driver.Value, error) {
+	return json.Marshal(r)
+}
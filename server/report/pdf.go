@@ -0,0 +1,134 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// A minimal PDF 1.4 writer for text-only reports. It uses the standard
+// Helvetica fonts, which every PDF viewer provides, so no font data needs to
+// be embedded.
+const (
+	pageWidth    = 595 // A4 in points
+	pageHeight   = 842
+	pageMargin   = 50
+	bodySize     = 11
+	headingSize  = 18
+	sectionSize  = 14
+	smallSize    = 8
+	lineSpacing  = 1.4
+	avgCharWidth = 0.5 // Helvetica's average glyph width relative to font size
+)
+
+type pdfDoc struct {
+	pages []*bytes.Buffer
+	y     float64
+}
+
+func newPDF() *pdfDoc {
+	p := &pdfDoc{}
+	p.newPage()
+	return p
+}
+
+func (p *pdfDoc) newPage() {
+	p.pages = append(p.pages, &bytes.Buffer{})
+	p.y = pageHeight - pageMargin
+}
+
+func (p *pdfDoc) heading(s string) { p.line(s, "F2", headingSize) }
+func (p *pdfDoc) section(s string) { p.space(); p.line(s, "F2", sectionSize) }
+func (p *pdfDoc) text(s string)    { p.line(s, "F1", bodySize) }
+func (p *pdfDoc) small(s string)   { p.line(s, "F1", smallSize) }
+func (p *pdfDoc) space()           { p.y -= bodySize }
+
+// line writes s, wrapping it to the page width and starting new pages as
+// needed.
+func (p *pdfDoc) line(s, font string, size float64) {
+	maxChars := int((pageWidth - 2*pageMargin) / (size * avgCharWidth))
+	for _, l := range wrap(s, maxChars) {
+		p.y -= size * lineSpacing
+		if p.y < pageMargin {
+			p.newPage()
+			p.y -= size * lineSpacing
+		}
+		fmt.Fprintf(p.pages[len(p.pages)-1], "BT /%s %.0f Tf %d %.2f Td (%s) Tj ET\n", font, size, pageMargin, p.y, escapePDF(l))
+	}
+}
+
+func wrap(s string, width int) []string {
+	runes := []rune(s)
+	if len(runes) <= width {
+		return []string{s}
+	}
+
+	var lines []string
+	for len(runes) > width {
+		cut := width
+		if i := strings.LastIndexAny(string(runes[:width]), " /-?&"); i > 0 {
+			cut = len([]rune(string(runes[:width])[:i+1]))
+		}
+		lines = append(lines, string(runes[:cut]))
+		runes = runes[cut:]
+	}
+	return append(lines, string(runes))
+}
+
+// escapePDF escapes string delimiters and replaces characters outside the
+// printable ASCII range, which the standard fonts can't render reliably.
+func escapePDF(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 32 || r > 126:
+			b.WriteByte('?')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func (p *pdfDoc) write(w io.Writer) error {
+	var out bytes.Buffer
+	var offsets []int
+
+	obj := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	// Objects 1-4 are fixed; each page then takes a page object followed by
+	// its content stream.
+	out.WriteString("%PDF-1.4\n")
+	obj("<< /Type /Catalog /Pages 2 0 R >>")
+
+	kids := make([]string, len(p.pages))
+	for i := range p.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+2*i)
+	}
+	obj(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(p.pages)))
+	obj("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	obj("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+
+	for i, page := range p.pages {
+		obj(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			pageWidth, pageHeight, 6+2*i))
+		obj(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", page.Len(), page.String()))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	_, err := w.Write(out.Bytes())
+	return err
+}
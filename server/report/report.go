@@ -0,0 +1,115 @@
+package report
+
+import (
+	"embed"
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/saqibroy/web-crawler-dashboard/server/models"
+)
+
+//go:embed templates/report.html
+var templateFS embed.FS
+
+var htmlTemplate = template.Must(template.ParseFS(templateFS, "templates/report.html"))
+
+type headingRow struct {
+	Tag   string
+	Count int
+}
+
+type brokenLinkRow struct {
+	URL    string
+	Reason string
+}
+
+// data is the view model shared by the HTML and PDF renderers.
+type data struct {
+	Analysis    *models.Analysis
+	Headings    []headingRow
+	BrokenLinks []brokenLinkRow
+	GeneratedAt time.Time
+}
+
+func newData(a *models.Analysis) *data {
+	d := &data{Analysis: a, GeneratedAt: time.Now()}
+
+	for i := 1; i <= 6; i++ {
+		tag := fmt.Sprintf("h%d", i)
+		count := 0
+		switch v := a.Headings[tag].(type) {
+		case float64:
+			count = int(v)
+		case int:
+			count = v
+		}
+		d.Headings = append(d.Headings, headingRow{Tag: tag, Count: count})
+	}
+
+	for link, reason := range a.BrokenLinks {
+		d.BrokenLinks = append(d.BrokenLinks, brokenLinkRow{URL: link, Reason: fmt.Sprint(reason)})
+	}
+	sort.Slice(d.BrokenLinks, func(i, j int) bool { return d.BrokenLinks[i].URL < d.BrokenLinks[j].URL })
+
+	return d
+}
+
+// RenderHTML writes a self-contained HTML report with inline styles and no
+// external assets, so it can be saved or emailed as a single file.
+func RenderHTML(w io.Writer, a *models.Analysis) error {
+	return htmlTemplate.Execute(w, newData(a))
+}
+
+// RenderPDF writes the same report as a PDF document.
+func RenderPDF(w io.Writer, a *models.Analysis) error {
+	d := newData(a)
+	p := newPDF()
+
+	title := a.Title
+	if title == "" {
+		title = "Untitled page"
+	}
+	p.heading(title)
+	p.text(a.URL)
+	status := "Status: " + string(a.Status)
+	if a.CompletedAt != nil {
+		status += "  -  Analysed " + a.CompletedAt.Format("2 Jan 2006 15:04 MST")
+	}
+	p.text(status)
+
+	p.section("Overview")
+	htmlVersion := a.HTMLVersion
+	if htmlVersion == "" {
+		htmlVersion = "Unknown"
+	}
+	p.text("HTML version: " + htmlVersion)
+	p.text(fmt.Sprintf("Internal links: %d", a.InternalLinks))
+	p.text(fmt.Sprintf("External links: %d", a.ExternalLinks))
+	p.text(fmt.Sprintf("Broken links: %d", len(d.BrokenLinks)))
+	if a.HasLoginForm {
+		p.text("Login form: Yes")
+	} else {
+		p.text("Login form: No")
+	}
+
+	p.section("Headings")
+	for _, h := range d.Headings {
+		p.text(fmt.Sprintf("%s: %d", h.Tag, h.Count))
+	}
+
+	p.section("Broken links")
+	if len(d.BrokenLinks) == 0 {
+		p.text("No broken links were found.")
+	}
+	for _, l := range d.BrokenLinks {
+		p.text(fmt.Sprintf("%s (%s)", l.URL, l.Reason))
+	}
+
+	p.space()
+	p.small("Generated " + d.GeneratedAt.Format("2 Jan 2006 15:04 MST") + " by Web Crawler Dashboard")
+
+	return p.write(w)
+}
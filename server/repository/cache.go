@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/saqibroy/web-crawler-dashboard/server/models"
+)
+
+// StatusCountCache serves CountByStatus from memory for up to a TTL, and
+// forgets the counts whenever an analysis changes through it. Changes made by
+// other servers sharing the database show once the counts expire.
+type StatusCountCache struct {
+	AnalysisRepository
+
+	ttl     time.Duration
+	mu      sync.Mutex
+	counts  map[models.AnalysisStatus]int64
+	expires time.Time
+}
+
+func NewStatusCountCache(analyses AnalysisRepository, ttl time.Duration) *StatusCountCache {
+	return &StatusCountCache{AnalysisRepository: analyses, ttl: ttl}
+}
+
+func (c *StatusCountCache) CountByStatus(ctx context.Context) (map[models.AnalysisStatus]int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.counts == nil || !time.Now().Before(c.expires) {
+		counts, err := c.AnalysisRepository.CountByStatus(ctx)
+		if err != nil {
+			return nil, err
+		}
+		c.counts, c.expires = counts, time.Now().Add(c.ttl)
+	}
+
+	// Callers get their own copy
+	counts := make(map[models.AnalysisStatus]int64, len(c.counts))
+	for status, count := range c.counts {
+		counts[status] = count
+	}
+	return counts, nil
+}
+
+func (c *StatusCountCache) Create(ctx context.Context, analyses ...*models.Analysis) error {
+	defer c.invalidate()
+	return c.AnalysisRepository.Create(ctx, analyses...)
+}
+
+func (c *StatusCountCache) ClaimNext(ctx context.Context) (*models.Analysis, error) {
+	a, err := c.AnalysisRepository.ClaimNext(ctx)
+	if a != nil {
+		c.invalidate()
+	}
+	return a, err
+}
+
+func (c *StatusCountCache) UpdateStatus(ctx context.Context, ids []string, to models.AnalysisStatus, from ...models.AnalysisStatus) (int64, error) {
+	defer c.invalidate()
+	return c.AnalysisRepository.UpdateStatus(ctx, ids, to, from...)
+}
+
+func (c *StatusCountCache) Save(ctx context.Context, a *models.Analysis) error {
+	defer c.invalidate()
+	return c.AnalysisRepository.Save(ctx, a)
+}
+
+func (c *StatusCountCache) Delete(ctx context.Context, ids []string) (int64, error) {
+	defer c.invalidate()
+	return c.AnalysisRepository.Delete(ctx, ids)
+}
+
+func (c *StatusCountCache) invalidate() {
+	c.mu.Lock()
+	c.counts = nil
+	c.mu.Unlock()
+}
@@ -0,0 +1,305 @@
+package repository
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/saqibroy/web-crawler-dashboard/server/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrInvalidCursor is returned for a cursor that can't be decoded or was
+// issued for a different sort order.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// AnalysisPage is one page of a keyset-paginated listing. Next and Prev are
+// empty when there is nothing further in that direction.
+type AnalysisPage struct {
+	Analyses []models.Analysis
+	Total    int64
+	Next     string
+	Prev     string
+}
+
+// activeKey is the first key of the default order, putting queued and
+// processing analyses before the rest.
+const activeKey = "active"
+
+const activeExpr = "CASE WHEN status IN ('queued', 'processing') THEN 0 ELSE 1 END"
+
+// nullableColumns are the sort columns that can be NULL. NULLs sort last in
+// either direction, on every database.
+var nullableColumns = map[string]bool{"completed_at": true}
+
+type sortKey struct {
+	column string
+	desc   bool
+}
+
+// sortKeys is the order analyses are listed in. The ID always breaks ties, so
+// every analysis has a distinct position for a cursor to point at.
+func sortKeys(filter AnalysisFilter) []sortKey {
+	if len(filter.Sort) == 0 {
+		return []sortKey{{column: activeKey}, {column: "updated_at", desc: true}}
+	}
+	keys := make([]sortKey, 0, len(filter.Sort))
+	for _, field := range filter.Sort {
+		if IsSortColumn(field.Column) {
+			keys = append(keys, sortKey{column: field.Column, desc: field.Desc})
+		}
+	}
+	return keys
+}
+
+func keyValue(a *models.Analysis, column string) interface{} {
+	if column == activeKey {
+		if isActive(a.Status) {
+			return int64(0)
+		}
+		return int64(1)
+	}
+	return sortValue(a, column)
+}
+
+// compareRows compares the positions of two rows given their key values and
+// IDs.
+func compareRows(keys []sortKey, a []interface{}, aID string, b []interface{}, bID string) int {
+	for i, key := range keys {
+		if c := compareKey(a[i], b[i], key.desc); c != 0 {
+			return c
+		}
+	}
+	return strings.Compare(aID, bID)
+}
+
+func compareKey(a, b interface{}, desc bool) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return 1
+	case b == nil:
+		return -1
+	}
+	c := compareValues(a, b)
+	if desc {
+		return -c
+	}
+	return c
+}
+
+func keyValues(a *models.Analysis, keys []sortKey) []interface{} {
+	values := make([]interface{}, len(keys))
+	for i, key := range keys {
+		values[i] = keyValue(a, key.column)
+	}
+	return values
+}
+
+// pageCursor marks the position of a row. Before selects the rows preceding
+// it rather than those following it.
+type pageCursor struct {
+	Sort   string            `json:"s"`
+	Values []json.RawMessage `json:"v"`
+	ID     string            `json:"id"`
+	Before bool              `json:"b,omitempty"`
+
+	values []interface{}
+}
+
+func sortSignature(keys []sortKey) string {
+	parts := make([]string, len(keys))
+	for i, key := range keys {
+		parts[i] = key.column
+		if key.desc {
+			parts[i] = "-" + key.column
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+func encodeCursor(keys []sortKey, a *models.Analysis, before bool) string {
+	c := pageCursor{Sort: sortSignature(keys), ID: a.ID, Before: before}
+	for _, value := range keyValues(a, keys) {
+		raw, _ := json.Marshal(value)
+		c.Values = append(c.Values, raw)
+	}
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor reads a cursor for the given order, returning nil for an
+// empty one.
+func decodeCursor(s string, keys []sortKey) (*pageCursor, error) {
+	if s == "" {
+		return nil, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c pageCursor
+	if err := json.Unmarshal(data, &c); err != nil || c.ID == "" {
+		return nil, ErrInvalidCursor
+	}
+	if c.Sort != sortSignature(keys) || len(c.Values) != len(keys) {
+		return nil, fmt.Errorf("%w: it was issued for a different sort order", ErrInvalidCursor)
+	}
+
+	// Decode each value into the type the column sorts as
+	template := &models.Analysis{CompletedAt: &time.Time{}}
+	for i, key := range keys {
+		if nullableColumns[key.column] && bytes.Equal(c.Values[i], []byte("null")) {
+			c.values = append(c.values, nil)
+			continue
+		}
+		value, err := decodeValue(c.Values[i], keyValue(template, key.column))
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		c.values = append(c.values, value)
+	}
+	return &c, nil
+}
+
+func decodeValue(raw json.RawMessage, like interface{}) (interface{}, error) {
+	var err error
+	switch like.(type) {
+	case string:
+		var v string
+		err = json.Unmarshal(raw, &v)
+		return v, err
+	case int64:
+		var v int64
+		err = json.Unmarshal(raw, &v)
+		return v, err
+	case float64:
+		var v float64
+		err = json.Unmarshal(raw, &v)
+		return v, err
+	case bool:
+		var v bool
+		err = json.Unmarshal(raw, &v)
+		return v, err
+	case time.Time:
+		var v time.Time
+		err = json.Unmarshal(raw, &v)
+		return v, err
+	}
+	return nil, ErrInvalidCursor
+}
+
+// newPage trims a page fetched with one extra row, which only shows whether
+// more follow, and sets the cursors. Rows fetched backwards arrive in reverse.
+func newPage(rows []models.Analysis, total int64, keys []sortKey, limit int, c *pageCursor) *AnalysisPage {
+	more := len(rows) > limit
+	if more {
+		rows = rows[:limit]
+	}
+	backward := c != nil && c.Before
+	if backward {
+		for i, j := 0, len(rows)-1; i < j; i, j = i+1, j-1 {
+			rows[i], rows[j] = rows[j], rows[i]
+		}
+	}
+
+	page := &AnalysisPage{Analyses: rows, Total: total}
+	if len(rows) == 0 {
+		return page
+	}
+	// Moving in one direction, the cursor row itself lies in the other
+	hasNext, hasPrev := more, c != nil
+	if backward {
+		hasNext, hasPrev = true, more
+	}
+	if hasNext {
+		page.Next = encodeCursor(keys, &rows[len(rows)-1], false)
+	}
+	if hasPrev {
+		page.Prev = encodeCursor(keys, &rows[0], true)
+	}
+	return page
+}
+
+func keyExpr(column string) interface{} {
+	if column == activeKey {
+		return clause.Expr{SQL: activeExpr}
+	}
+	return clause.Column{Name: column}
+}
+
+// orderBy sorts a query by the keys and ID, or in exactly the opposite order
+// when reverse is set.
+func orderBy(query *gorm.DB, keys []sortKey, reverse bool) *gorm.DB {
+	// A single expression, as GORM doesn't merge ORDER BY expressions
+	var terms []string
+	var vars []interface{}
+	for _, key := range keys {
+		if nullableColumns[key.column] {
+			terms = append(terms, "? IS NULL"+direction(reverse))
+			vars = append(vars, clause.Column{Name: key.column})
+		}
+		terms = append(terms, "?"+direction(key.desc != reverse))
+		vars = append(vars, keyExpr(key.column))
+	}
+	terms = append(terms, "id"+direction(reverse))
+	return query.Order(clause.OrderBy{Expression: clause.Expr{SQL: strings.Join(terms, ", "), Vars: vars}})
+}
+
+func direction(desc bool) string {
+	if desc {
+		return " DESC"
+	}
+	return ""
+}
+
+// keysetCondition selects the rows after the cursor, or before it, in the
+// order of the keys.
+func keysetCondition(keys []sortKey, c *pageCursor) clause.Expression {
+	idOp := ">"
+	if c.Before {
+		idOp = "<"
+	}
+	condition := clause.Expression(clause.Expr{SQL: "id " + idOp + " ?", Vars: []interface{}{c.ID}})
+
+	// Build from the last key outwards: beyond this key's value, or equal to
+	// it and beyond on the following keys
+	for i := len(keys) - 1; i >= 0; i-- {
+		beyond, equal := keyComparison(keys[i], c.values[i], c.Before)
+		condition = clause.Or(beyond, clause.And(equal, condition))
+	}
+	return condition
+}
+
+func keyComparison(key sortKey, value interface{}, before bool) (beyond, equal clause.Expression) {
+	expr := keyExpr(key.column)
+	op := ">"
+	if key.desc != before {
+		op = "<"
+	}
+
+	if !nullableColumns[key.column] {
+		return clause.Expr{SQL: "? " + op + " ?", Vars: []interface{}{expr, value}},
+			clause.Expr{SQL: "? = ?", Vars: []interface{}{expr, value}}
+	}
+
+	// NULLs come after every value
+	isNull := clause.Expr{SQL: "? IS NULL", Vars: []interface{}{expr}}
+	if value == nil {
+		if before {
+			return clause.Expr{SQL: "? IS NOT NULL", Vars: []interface{}{expr}}, isNull
+		}
+		return clause.Expr{SQL: "1 = 0"}, isNull
+	}
+	beyond = clause.Expr{SQL: "? " + op + " ?", Vars: []interface{}{expr, value}}
+	if !before {
+		beyond = clause.Or(beyond, isNull)
+	}
+	return beyond, clause.Expr{SQL: "? = ?", Vars: []interface{}{expr, value}}
+}
@@ -0,0 +1,587 @@
+package repository
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/saqibroy/web-crawler-dashboard/server/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GormAnalysisRepository stores analyses in any database GORM supports.
+type GormAnalysisRepository struct {
+	db *gorm.DB
+}
+
+func NewGormAnalysisRepository(db *gorm.DB) *GormAnalysisRepository {
+	return &GormAnalysisRepository{db: db}
+}
+
+func (r *GormAnalysisRepository) Create(ctx context.Context, analyses ...*models.Analysis) error {
+	if len(analyses) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return tx.CreateInBatches(analyses, 100).Error
+	})
+}
+
+func (r *GormAnalysisRepository) Get(ctx context.Context, id string) (*models.Analysis, error) {
+	var analysis models.Analysis
+	if err := r.db.WithContext(ctx).Preload("Results").First(&analysis, "id = ?", id).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &analysis, nil
+}
+
+func (r *GormAnalysisRepository) List(ctx context.Context, filter AnalysisFilter) ([]models.Analysis, int64, error) {
+	query := r.filtered(ctx, filter)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	query = orderBy(query, sortKeys(filter), false).Omit("content")
+	if filter.Offset > 0 {
+		query = query.Offset(filter.Offset)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	var analyses []models.Analysis
+	if err := query.Find(&analyses).Error; err != nil {
+		return nil, 0, err
+	}
+	return analyses, total, nil
+}
+
+func (r *GormAnalysisRepository) ListPage(ctx context.Context, filter AnalysisFilter, cursor string, limit int) (*AnalysisPage, error) {
+	keys := sortKeys(filter)
+	c, err := decodeCursor(cursor, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	var total int64
+	if err := r.filtered(ctx, filter).Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	query := r.filtered(ctx, filter)
+	if c != nil {
+		query = query.Where(keysetCondition(keys, c))
+	}
+	// Pages before the cursor are read backwards from it
+	query = orderBy(query, keys, c != nil && c.Before).Omit("content").Limit(limit + 1)
+
+	var rows []models.Analysis
+	if err := query.Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	return newPage(rows, total, keys, limit, c), nil
+}
+
+func (r *GormAnalysisRepository) Each(ctx context.Context, filter AnalysisFilter, fn func(*models.Analysis) error) error {
+	query := orderBy(r.filtered(ctx, filter), sortKeys(filter), false)
+	if !filter.WithContent {
+		query = query.Omit("content")
+	}
+	rows, err := query.Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var analysis models.Analysis
+		if err := r.db.ScanRows(rows, &analysis); err != nil {
+			return err
+		}
+		if err := fn(&analysis); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// latestCompleted ranks the completed analyses of each URL among the given
+// ones, newest first, so the latest has position 1.
+func latestCompleted(analyses *gorm.DB) *gorm.DB {
+	return analyses.
+		Select("analyses.id, ROW_NUMBER() OVER (PARTITION BY analyses.url ORDER BY analyses.completed_at DESC, analyses.id DESC) AS position").
+		Where("analyses.status = ?", models.Completed)
+}
+
+// domainAggregates are the expressions behind DomainStats. ORDER BY repeats
+// them, as PostgreSQL doesn't accept output names inside expressions.
+var domainAggregates = []struct{ column, expr string }{
+	{"domain", "analyses.domain"},
+	{"analyses", "COUNT(*)"},
+	{"pages", "COUNT(DISTINCT analyses.url)"},
+	{"completed", "SUM(CASE WHEN analyses.status = 'completed' THEN 1 ELSE 0 END)"},
+	{"failed", "SUM(CASE WHEN analyses.status = 'failed' THEN 1 ELSE 0 END)"},
+	{"broken_links", "SUM(CASE WHEN latest.id IS NULL THEN 0 ELSE analyses.broken_link_count END)"},
+	{"last_analyzed_at", "MAX(analyses.completed_at)"},
+}
+
+func (r *GormAnalysisRepository) Domains(ctx context.Context, filter DomainFilter) ([]DomainStats, int64, error) {
+	domains := func() *gorm.DB {
+		query := r.db.WithContext(ctx).Table("analyses").Where("analyses.domain <> ''")
+		if filter.Search != "" {
+			query = query.Where("analyses.domain LIKE ?", "%"+strings.ToLower(filter.Search)+"%")
+		}
+		if filter.Domain != "" {
+			query = query.Where("analyses.domain = ?", filter.Domain)
+		}
+		return query
+	}
+
+	var total int64
+	if err := domains().Distinct("analyses.domain").Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	selects := make([]string, len(domainAggregates))
+	exprs := make(map[string]string, len(domainAggregates))
+	for i, aggregate := range domainAggregates {
+		selects[i] = aggregate.expr + " AS " + aggregate.column
+		exprs[aggregate.column] = aggregate.expr
+	}
+	query := domains().
+		Select(strings.Join(selects, ", ")).
+		Joins("LEFT JOIN (?) latest ON latest.id = analyses.id AND latest.position = 1", latestCompleted(domains())).
+		Group("analyses.domain")
+
+	// Columns are checked against the whitelist, never interpolated
+	order := "analyses.domain"
+	if field := filter.Sort; field != nil && IsDomainSortColumn(field.Column) && field.Column != "domain" {
+		expr := exprs[field.Column]
+		order = expr + direction(field.Desc) + ", " + order
+		if field.Column == "last_analyzed_at" {
+			order = expr + " IS NULL, " + order
+		}
+	} else if field != nil && field.Desc {
+		order += " DESC"
+	}
+	query = query.Order(order)
+	if filter.Offset > 0 {
+		query = query.Offset(filter.Offset)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	var rows []struct {
+		Domain                                          string
+		Analyses, Pages, Completed, Failed, BrokenLinks int64
+		LastAnalyzedAt                                  aggregateTime
+	}
+	if err := query.Scan(&rows).Error; err != nil {
+		return nil, 0, err
+	}
+	stats := make([]DomainStats, len(rows))
+	for i, row := range rows {
+		stats[i] = DomainStats{
+			Domain: row.Domain, Analyses: row.Analyses, Pages: row.Pages, Completed: row.Completed,
+			Failed: row.Failed, BrokenLinks: row.BrokenLinks, LastAnalyzedAt: row.LastAnalyzedAt.Time,
+		}
+	}
+	return stats, total, nil
+}
+
+// aggregateTime scans a timestamp computed by an aggregate such as MAX,
+// which SQLite returns as text, as it can't tell the column's type.
+type aggregateTime struct {
+	Time *time.Time
+}
+
+var aggregateTimeFormats = []string{
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02T15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+}
+
+// Value is only there for GORM, which requires it of column types.
+func (t aggregateTime) Value() (driver.Value, error) {
+	if t.Time == nil {
+		return nil, nil
+	}
+	return *t.Time, nil
+}
+
+func (t *aggregateTime) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		t.Time = nil
+		return nil
+	case time.Time:
+		t.Time = &v
+		return nil
+	case []byte:
+		value = string(v)
+	}
+	text, ok := value.(string)
+	if !ok {
+		return fmt.Errorf("can't scan %T as a time", value)
+	}
+	for _, format := range aggregateTimeFormats {
+		if parsed, err := time.Parse(format, text); err == nil {
+			t.Time = &parsed
+			return nil
+		}
+	}
+	return fmt.Errorf("can't parse time %q", text)
+}
+
+func (r *GormAnalysisRepository) CountByStatus(ctx context.Context) (map[models.AnalysisStatus]int64, error) {
+	var rows []struct {
+		Status models.AnalysisStatus
+		Count  int64
+	}
+	err := r.db.WithContext(ctx).Model(&models.Analysis{}).
+		Select("status, COUNT(*) AS count").Group("status").Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[models.AnalysisStatus]int64, len(models.Statuses))
+	for _, status := range models.Statuses {
+		counts[status] = 0
+	}
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+	return counts, nil
+}
+
+func (r *GormAnalysisRepository) ClaimNext(ctx context.Context) (*models.Analysis, error) {
+	db := r.db.WithContext(ctx)
+	for {
+		// Find rather than First, as an empty queue is the usual case and
+		// shouldn't be logged as an error
+		var queued []models.Analysis
+		if err := db.Preload("Results").Where("status = ?", models.Queued).Order("created_at, id").Limit(1).Find(&queued).Error; err != nil {
+			return nil, err
+		}
+		if len(queued) == 0 {
+			return nil, nil
+		}
+		analysis := queued[0]
+
+		// Only take it if no other worker has in the meantime
+		now := time.Now()
+		result := db.Model(&models.Analysis{}).
+			Where("id = ? AND status = ?", analysis.ID, models.Queued).
+			Updates(map[string]interface{}{"status": models.Processing, "started_at": now})
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		if result.RowsAffected == 1 {
+			analysis.Status = models.Processing
+			analysis.StartedAt = &now
+			return &analysis, nil
+		}
+	}
+}
+
+func (r *GormAnalysisRepository) UpdateStatus(ctx context.Context, ids []string, to models.AnalysisStatus, from ...models.AnalysisStatus) (int64, error) {
+	query := r.db.WithContext(ctx).Model(&models.Analysis{}).Where("id IN ?", ids)
+	if len(from) > 0 {
+		query = query.Where("status IN ?", from)
+	}
+	result := query.Update("status", to)
+	return result.RowsAffected, result.Error
+}
+
+func (r *GormAnalysisRepository) Save(ctx context.Context, a *models.Analysis) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Save(a).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("analysis_id = ?", a.ID).Delete(&models.AnalyzerResult{}).Error; err != nil {
+			return err
+		}
+		if len(a.Results) > 0 {
+			for i := range a.Results {
+				a.Results[i].ID = 0
+				a.Results[i].AnalysisID = a.ID
+			}
+			if err := tx.Create(&a.Results).Error; err != nil {
+				return err
+			}
+		}
+
+		if a.Metric != nil {
+			if err := tx.Create(metricFor(a)).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err == nil {
+		a.Metric = nil
+	}
+	return err
+}
+
+// metricFor completes the analysis's pending metric with the fields it
+// shares with the analysis.
+func metricFor(a *models.Analysis) *models.AnalysisMetric {
+	metric := *a.Metric
+	metric.AnalysisID = a.ID
+	metric.URL = a.URL
+	metric.Domain = a.Domain
+	return &metric
+}
+
+func (r *GormAnalysisRepository) Metrics(ctx context.Context, filter MetricFilter, fn func(*models.AnalysisMetric) error) error {
+	query := r.db.WithContext(ctx).Model(&models.AnalysisMetric{})
+	if filter.URL != "" {
+		// The domain narrows the search to the index
+		query = query.Where("domain = ? AND url = ?", models.URLDomain(filter.URL), filter.URL)
+	}
+	if filter.Domain != "" {
+		query = query.Where("domain = ?", filter.Domain)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("recorded_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("recorded_at <= ?", filter.To)
+	}
+
+	rows, err := query.Order("recorded_at, id").Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var metric models.AnalysisMetric
+		if err := r.db.ScanRows(rows, &metric); err != nil {
+			return err
+		}
+		if err := fn(&metric); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func (r *GormAnalysisRepository) Delete(ctx context.Context, ids []string) (int64, error) {
+	result := r.db.WithContext(ctx).Delete(&models.Analysis{}, ids)
+	return result.RowsAffected, result.Error
+}
+
+func (r *GormAnalysisRepository) filtered(ctx context.Context, filter AnalysisFilter) *gorm.DB {
+	query := r.db.WithContext(ctx).Model(&models.Analysis{})
+	if filter.Search != "" {
+		// LIKE is case-sensitive on PostgreSQL, so compare lower-cased values
+		pattern := "%" + strings.ToLower(filter.Search) + "%"
+		query = query.Where("LOWER(url) LIKE ? OR LOWER(title) LIKE ?", pattern, pattern)
+	}
+	if len(filter.Statuses) > 0 {
+		query = query.Where("status IN ?", filter.Statuses)
+	}
+	if len(filter.HTMLVersions) > 0 {
+		query = query.Where("html_version IN ?", filter.HTMLVersions)
+	}
+	if filter.HasLoginForm != nil {
+		query = query.Where("has_login_form = ?", *filter.HasLoginForm)
+	}
+	if filter.Domain != "" {
+		query = query.Where("domain = ? OR domain LIKE ?", filter.Domain, "%."+filter.Domain)
+	}
+	if !filter.CreatedFrom.IsZero() {
+		query = query.Where("created_at >= ?", filter.CreatedFrom)
+	}
+	if !filter.CreatedTo.IsZero() {
+		query = query.Where("created_at <= ?", filter.CreatedTo)
+	}
+	if !filter.CompletedFrom.IsZero() {
+		query = query.Where("completed_at >= ?", filter.CompletedFrom)
+	}
+	if !filter.CompletedTo.IsZero() {
+		query = query.Where("completed_at <= ?", filter.CompletedTo)
+	}
+	if filter.MinBrokenLinks != nil {
+		query = query.Where("broken_link_count >= ?", *filter.MinBrokenLinks)
+	}
+	if filter.MaxBrokenLinks != nil {
+		query = query.Where("broken_link_count <= ?", *filter.MaxBrokenLinks)
+	}
+	return query
+}
+
+// GormRuleSetRepository stores extraction rule sets in any database GORM
+// supports.
+type GormRuleSetRepository struct {
+	db *gorm.DB
+}
+
+func NewGormRuleSetRepository(db *gorm.DB) *GormRuleSetRepository {
+	return &GormRuleSetRepository{db: db}
+}
+
+func (r *GormRuleSetRepository) Create(ctx context.Context, set *models.ExtractionRuleSet) error {
+	return r.db.WithContext(ctx).Create(set).Error
+}
+
+func (r *GormRuleSetRepository) List(ctx context.Context) ([]models.ExtractionRuleSet, error) {
+	var sets []models.ExtractionRuleSet
+	if err := r.db.WithContext(ctx).Order("name").Find(&sets).Error; err != nil {
+		return nil, err
+	}
+	return sets, nil
+}
+
+func (r *GormRuleSetRepository) Get(ctx context.Context, id string) (*models.ExtractionRuleSet, error) {
+	var set models.ExtractionRuleSet
+	if err := r.db.WithContext(ctx).First(&set, "id = ?", id).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &set, nil
+}
+
+func (r *GormRuleSetRepository) Delete(ctx context.Context, id string) error {
+	result := r.db.WithContext(ctx).Delete(&models.ExtractionRuleSet{}, "id = ?", id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// GormAlertRepository stores alert rules and alerts in any database GORM
+// supports.
+type GormAlertRepository struct {
+	db *gorm.DB
+}
+
+func NewGormAlertRepository(db *gorm.DB) *GormAlertRepository {
+	return &GormAlertRepository{db: db}
+}
+
+func (r *GormAlertRepository) CreateRule(ctx context.Context, rule *models.AlertRule) error {
+	return r.db.WithContext(ctx).Create(rule).Error
+}
+
+func (r *GormAlertRepository) ListRules(ctx context.Context) ([]models.AlertRule, error) {
+	var rules []models.AlertRule
+	if err := r.db.WithContext(ctx).Order("name, id").Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+func (r *GormAlertRepository) GetRule(ctx context.Context, id string) (*models.AlertRule, error) {
+	var rule models.AlertRule
+	if err := r.db.WithContext(ctx).First(&rule, "id = ?", id).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &rule, nil
+}
+
+func (r *GormAlertRepository) SaveRule(ctx context.Context, rule *models.AlertRule) error {
+	return r.db.WithContext(ctx).Save(rule).Error
+}
+
+func (r *GormAlertRepository) DeleteRule(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(&models.AlertRule{}, "id = ?", id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+		return tx.Delete(&models.AlertState{}, "rule_id = ?", id).Error
+	})
+}
+
+func (r *GormAlertRepository) State(ctx context.Context, ruleID, url string) (*models.AlertState, error) {
+	var states []models.AlertState
+	if err := r.db.WithContext(ctx).Where("rule_id = ? AND url = ?", ruleID, url).Limit(1).Find(&states).Error; err != nil {
+		return nil, err
+	}
+	if len(states) == 0 {
+		return &models.AlertState{RuleID: ruleID, URL: url}, nil
+	}
+	return &states[0], nil
+}
+
+func (r *GormAlertRepository) SaveState(ctx context.Context, state *models.AlertState) error {
+	return r.db.WithContext(ctx).Save(state).Error
+}
+
+func (r *GormAlertRepository) CreateAlert(ctx context.Context, alert *models.Alert) error {
+	return r.db.WithContext(ctx).Create(alert).Error
+}
+
+func (r *GormAlertRepository) GetAlert(ctx context.Context, id uint) (*models.Alert, error) {
+	var alert models.Alert
+	if err := r.db.WithContext(ctx).First(&alert, id).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &alert, nil
+}
+
+func (r *GormAlertRepository) SaveAlert(ctx context.Context, alert *models.Alert) error {
+	return r.db.WithContext(ctx).Save(alert).Error
+}
+
+func (r *GormAlertRepository) ListAlerts(ctx context.Context, filter AlertFilter) ([]models.Alert, int64, error) {
+	query := r.db.WithContext(ctx).Model(&models.Alert{})
+	if filter.RuleID != "" {
+		query = query.Where("rule_id = ?", filter.RuleID)
+	}
+	if filter.URL != "" {
+		query = query.Where("domain = ? AND url = ?", models.URLDomain(filter.URL), filter.URL)
+	}
+	if filter.Domain != "" {
+		query = query.Where("domain = ?", filter.Domain)
+	}
+	if filter.Open != nil {
+		if *filter.Open {
+			query = query.Where("resolved_at IS NULL")
+		} else {
+			query = query.Where("resolved_at IS NOT NULL")
+		}
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	query = query.Order("created_at DESC, id DESC")
+	if filter.Offset > 0 {
+		query = query.Offset(filter.Offset)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	var alerts []models.Alert
+	if err := query.Find(&alerts).Error; err != nil {
+		return nil, 0, err
+	}
+	return alerts, total, nil
+}
+
+func notFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
@@ -0,0 +1,783 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/saqibroy/web-crawler-dashboard/server/models"
+)
+
+// MemoryAnalysisRepository keeps analyses in memory, for tests. It stores and
+// returns copies, so callers can't change stored analyses without saving.
+type MemoryAnalysisRepository struct {
+	mu       sync.Mutex
+	analyses map[string]*models.Analysis
+	metrics  []models.AnalysisMetric
+}
+
+func NewMemoryAnalysisRepository() *MemoryAnalysisRepository {
+	return &MemoryAnalysisRepository{analyses: make(map[string]*models.Analysis)}
+}
+
+func (r *MemoryAnalysisRepository) Create(ctx context.Context, analyses ...*models.Analysis) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for _, a := range analyses {
+		if err := a.BeforeCreate(nil); err != nil {
+			return err
+		}
+		if err := a.BeforeSave(nil); err != nil {
+			return err
+		}
+		if _, ok := r.analyses[a.ID]; ok {
+			return fmt.Errorf("analysis %s already exists", a.ID)
+		}
+		if a.Status == "" {
+			a.Status = models.Queued
+		}
+		if a.CreatedAt.IsZero() {
+			a.CreatedAt = now
+		}
+		a.UpdatedAt = now
+		r.analyses[a.ID] = copyAnalysis(a)
+	}
+	return nil
+}
+
+func (r *MemoryAnalysisRepository) Get(ctx context.Context, id string) (*models.Analysis, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	a, ok := r.analyses[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return copyAnalysis(a), nil
+}
+
+func (r *MemoryAnalysisRepository) List(ctx context.Context, filter AnalysisFilter) ([]models.Analysis, int64, error) {
+	matches := r.matching(filter)
+	total := int64(len(matches))
+
+	if filter.Offset >= len(matches) {
+		matches = nil
+	} else {
+		matches = matches[filter.Offset:]
+	}
+	if filter.Limit > 0 && len(matches) > filter.Limit {
+		matches = matches[:filter.Limit]
+	}
+
+	analyses := make([]models.Analysis, 0, len(matches))
+	for _, a := range matches {
+		a.Results = nil
+		a.Content = ""
+		analyses = append(analyses, *a)
+	}
+	return analyses, total, nil
+}
+
+func (r *MemoryAnalysisRepository) ListPage(ctx context.Context, filter AnalysisFilter, cursor string, limit int) (*AnalysisPage, error) {
+	keys := sortKeys(filter)
+	c, err := decodeCursor(cursor, keys)
+	if err != nil {
+		return nil, err
+	}
+	matches := r.matching(filter)
+
+	// Collect up to limit+1 rows beyond the cursor, in the direction moved
+	var rows []models.Analysis
+	for i := range matches {
+		a := matches[i]
+		if c != nil && c.Before {
+			a = matches[len(matches)-1-i]
+		}
+		if len(rows) > limit {
+			break
+		}
+		if c != nil {
+			order := compareRows(keys, keyValues(a, keys), a.ID, c.values, c.ID)
+			if (c.Before && order >= 0) || (!c.Before && order <= 0) {
+				continue
+			}
+		}
+		a.Results = nil
+		a.Content = ""
+		rows = append(rows, *a)
+	}
+	return newPage(rows, int64(len(matches)), keys, limit, c), nil
+}
+
+func (r *MemoryAnalysisRepository) Each(ctx context.Context, filter AnalysisFilter, fn func(*models.Analysis) error) error {
+	for _, a := range r.matching(filter) {
+		a.Results = nil
+		if !filter.WithContent {
+			a.Content = ""
+		}
+		if err := fn(a); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *MemoryAnalysisRepository) Domains(ctx context.Context, filter DomainFilter) ([]DomainStats, int64, error) {
+	r.mu.Lock()
+	byDomain := make(map[string]*DomainStats)
+	pages := make(map[string]map[string]bool)
+	latest := make(map[string]*models.Analysis)
+	for _, a := range r.analyses {
+		if a.Domain == "" ||
+			(filter.Search != "" && !strings.Contains(a.Domain, strings.ToLower(filter.Search))) ||
+			(filter.Domain != "" && a.Domain != filter.Domain) {
+			continue
+		}
+		stats := byDomain[a.Domain]
+		if stats == nil {
+			stats = &DomainStats{Domain: a.Domain}
+			byDomain[a.Domain] = stats
+			pages[a.Domain] = make(map[string]bool)
+		}
+		stats.Analyses++
+		pages[a.Domain][a.URL] = true
+		switch a.Status {
+		case models.Completed:
+			stats.Completed++
+			if l := latest[a.URL]; l == nil || compareRows(completedKeys, keyValues(a, completedKeys), a.ID, keyValues(l, completedKeys), l.ID) > 0 {
+				latest[a.URL] = a
+			}
+		case models.Failed:
+			stats.Failed++
+		}
+		if a.CompletedAt != nil && (stats.LastAnalyzedAt == nil || a.CompletedAt.After(*stats.LastAnalyzedAt)) {
+			completedAt := *a.CompletedAt
+			stats.LastAnalyzedAt = &completedAt
+		}
+	}
+	for _, a := range latest {
+		byDomain[a.Domain].BrokenLinks += int64(a.BrokenLinkCount)
+	}
+	r.mu.Unlock()
+
+	all := make([]DomainStats, 0, len(byDomain))
+	for domain, stats := range byDomain {
+		stats.Pages = int64(len(pages[domain]))
+		all = append(all, *stats)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		a, b := all[i], all[j]
+		if field := filter.Sort; field != nil && IsDomainSortColumn(field.Column) {
+			if c := compareKey(domainSortValue(a, field.Column), domainSortValue(b, field.Column), field.Desc); c != 0 {
+				return c < 0
+			}
+		}
+		return a.Domain < b.Domain
+	})
+
+	total := int64(len(all))
+	if filter.Offset >= len(all) {
+		all = all[:0]
+	} else {
+		all = all[filter.Offset:]
+	}
+	if filter.Limit > 0 && len(all) > filter.Limit {
+		all = all[:filter.Limit]
+	}
+	return all, total, nil
+}
+
+// completedKeys order completed analyses by when they completed.
+var completedKeys = []sortKey{{column: "completed_at"}}
+
+func domainSortValue(s DomainStats, column string) interface{} {
+	switch column {
+	case "domain":
+		return s.Domain
+	case "analyses":
+		return s.Analyses
+	case "pages":
+		return s.Pages
+	case "completed":
+		return s.Completed
+	case "failed":
+		return s.Failed
+	case "broken_links":
+		return s.BrokenLinks
+	case "last_analyzed_at":
+		if s.LastAnalyzedAt == nil {
+			return nil
+		}
+		return *s.LastAnalyzedAt
+	}
+	return nil
+}
+
+func (r *MemoryAnalysisRepository) CountByStatus(ctx context.Context) (map[models.AnalysisStatus]int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	counts := make(map[models.AnalysisStatus]int64, len(models.Statuses))
+	for _, status := range models.Statuses {
+		counts[status] = 0
+	}
+	for _, a := range r.analyses {
+		counts[a.Status]++
+	}
+	return counts, nil
+}
+
+func (r *MemoryAnalysisRepository) ClaimNext(ctx context.Context) (*models.Analysis, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var next *models.Analysis
+	for _, a := range r.analyses {
+		if a.Status != models.Queued {
+			continue
+		}
+		if next == nil || a.CreatedAt.Before(next.CreatedAt) ||
+			(a.CreatedAt.Equal(next.CreatedAt) && a.ID < next.ID) {
+			next = a
+		}
+	}
+	if next == nil {
+		return nil, nil
+	}
+
+	now := time.Now()
+	next.Status = models.Processing
+	next.StartedAt = &now
+	next.UpdatedAt = now
+	return copyAnalysis(next), nil
+}
+
+func (r *MemoryAnalysisRepository) UpdateStatus(ctx context.Context, ids []string, to models.AnalysisStatus, from ...models.AnalysisStatus) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var updated int64
+	for _, id := range ids {
+		a, ok := r.analyses[id]
+		if !ok || a.Status == to || (len(from) > 0 && !hasStatus(from, a.Status)) {
+			continue
+		}
+		a.Status = to
+		a.UpdatedAt = time.Now()
+		updated++
+	}
+	return updated, nil
+}
+
+func (r *MemoryAnalysisRepository) Save(ctx context.Context, a *models.Analysis) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.analyses[a.ID]; !ok {
+		return ErrNotFound
+	}
+	if err := a.BeforeSave(nil); err != nil {
+		return err
+	}
+	a.UpdatedAt = time.Now()
+	for i := range a.Results {
+		a.Results[i].ID = uint(i + 1)
+		a.Results[i].AnalysisID = a.ID
+	}
+	if a.Metric != nil {
+		metric := metricFor(a)
+		metric.ID = uint(len(r.metrics) + 1)
+		r.metrics = append(r.metrics, *metric)
+		a.Metric = nil
+	}
+	r.analyses[a.ID] = copyAnalysis(a)
+	return nil
+}
+
+func (r *MemoryAnalysisRepository) Metrics(ctx context.Context, filter MetricFilter, fn func(*models.AnalysisMetric) error) error {
+	r.mu.Lock()
+	var matches []models.AnalysisMetric
+	for _, m := range r.metrics {
+		if (filter.URL != "" && m.URL != filter.URL) || (filter.Domain != "" && m.Domain != filter.Domain) ||
+			!inRange(&m.RecordedAt, filter.From, filter.To) {
+			continue
+		}
+		matches = append(matches, m)
+	}
+	r.mu.Unlock()
+
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].RecordedAt.Before(matches[j].RecordedAt) })
+	for i := range matches {
+		if err := fn(&matches[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *MemoryAnalysisRepository) Delete(ctx context.Context, ids []string) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var deleted int64
+	for _, id := range ids {
+		if _, ok := r.analyses[id]; ok {
+			delete(r.analyses, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// matching returns copies of the analyses matching filter, sorted but not
+// paginated.
+func (r *MemoryAnalysisRepository) matching(filter AnalysisFilter) []*models.Analysis {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var matches []*models.Analysis
+	for _, a := range r.analyses {
+		if matchesFilter(a, filter) {
+			matches = append(matches, copyAnalysis(a))
+		}
+	}
+
+	keys := sortKeys(filter)
+	sort.Slice(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		return compareRows(keys, keyValues(a, keys), a.ID, keyValues(b, keys), b.ID) < 0
+	})
+	return matches
+}
+
+func matchesFilter(a *models.Analysis, filter AnalysisFilter) bool {
+	if search := strings.ToLower(filter.Search); search != "" &&
+		!strings.Contains(strings.ToLower(a.URL), search) &&
+		!strings.Contains(strings.ToLower(a.Title), search) {
+		return false
+	}
+	if len(filter.Statuses) > 0 && !hasStatus(filter.Statuses, a.Status) {
+		return false
+	}
+	if len(filter.HTMLVersions) > 0 && !contains(filter.HTMLVersions, a.HTMLVersion) {
+		return false
+	}
+	if filter.HasLoginForm != nil && a.HasLoginForm != *filter.HasLoginForm {
+		return false
+	}
+	if filter.Domain != "" && a.Domain != filter.Domain && !strings.HasSuffix(a.Domain, "."+filter.Domain) {
+		return false
+	}
+	if !inRange(&a.CreatedAt, filter.CreatedFrom, filter.CreatedTo) ||
+		!inRange(a.CompletedAt, filter.CompletedFrom, filter.CompletedTo) {
+		return false
+	}
+	if filter.MinBrokenLinks != nil && a.BrokenLinkCount < *filter.MinBrokenLinks {
+		return false
+	}
+	if filter.MaxBrokenLinks != nil && a.BrokenLinkCount > *filter.MaxBrokenLinks {
+		return false
+	}
+	return true
+}
+
+// inRange reports whether t lies within the bounds that are set. A nil time
+// is never in a range.
+func inRange(t *time.Time, from, to time.Time) bool {
+	if from.IsZero() && to.IsZero() {
+		return true
+	}
+	return t != nil && !t.Before(from) && (to.IsZero() || !t.After(to))
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func isActive(status models.AnalysisStatus) bool {
+	return status == models.Queued || status == models.Processing
+}
+
+func hasStatus(statuses []models.AnalysisStatus, status models.AnalysisStatus) bool {
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// sortValue returns the value of one of SortColumns, or nil for NULL.
+func sortValue(a *models.Analysis, column string) interface{} {
+	p := a.Performance
+	switch column {
+	case "url":
+		return a.URL
+	case "domain":
+		return a.Domain
+	case "status":
+		return string(a.Status)
+	case "title":
+		return a.Title
+	case "html_version":
+		return a.HTMLVersion
+	case "internal_links":
+		return int64(a.InternalLinks)
+	case "external_links":
+		return int64(a.ExternalLinks)
+	case "broken_link_count":
+		return int64(a.BrokenLinkCount)
+	case "has_login_form":
+		return a.HasLoginForm
+	case "accessibility_score":
+		return int64(a.AccessibilityScore)
+	case "security_grade":
+		return a.SecurityGrade
+	case "created_at":
+		return a.CreatedAt
+	case "updated_at":
+		return a.UpdatedAt
+	case "completed_at":
+		if a.CompletedAt == nil {
+			return nil
+		}
+		return *a.CompletedAt
+	case "perf_dns_lookup_ms":
+		return p.DNSLookupMs
+	case "perf_connect_ms":
+		return p.ConnectMs
+	case "perf_tls_handshake_ms":
+		return p.TLSHandshakeMs
+	case "perf_ttfb_ms":
+		return p.TTFBMs
+	case "perf_download_ms":
+		return p.DownloadMs
+	case "perf_total_ms":
+		return p.TotalMs
+	case "perf_transfer_size":
+		return p.TransferSize
+	case "perf_page_size":
+		return p.PageSize
+	case "perf_compression_ratio":
+		return p.CompressionRatio
+	case "perf_script_count":
+		return int64(p.ScriptCount)
+	case "perf_script_bytes":
+		return p.ScriptBytes
+	case "perf_stylesheet_count":
+		return int64(p.StylesheetCount)
+	case "perf_stylesheet_bytes":
+		return p.StylesheetBytes
+	case "perf_image_count":
+		return int64(p.ImageCount)
+	case "perf_image_bytes":
+		return p.ImageBytes
+	}
+	return nil
+}
+
+func compareValues(a, b interface{}) int {
+	switch a := a.(type) {
+	case string:
+		return strings.Compare(a, b.(string))
+	case int64:
+		b := b.(int64)
+		if a < b {
+			return -1
+		} else if a > b {
+			return 1
+		}
+	case float64:
+		b := b.(float64)
+		if a < b {
+			return -1
+		} else if a > b {
+			return 1
+		}
+	case bool:
+		if a != b.(bool) {
+			if a {
+				return 1
+			}
+			return -1
+		}
+	case time.Time:
+		return a.Compare(b.(time.Time))
+	}
+	return 0
+}
+
+// copyAnalysis copies an analysis and its results slice. JSON columns such as
+// Headings are shared, as nothing changes them in place.
+func copyAnalysis(a *models.Analysis) *models.Analysis {
+	c := *a
+	if a.Results != nil {
+		c.Results = append([]models.AnalyzerResult(nil), a.Results...)
+	}
+	return &c
+}
+
+// MemoryRuleSetRepository keeps extraction rule sets in memory, for tests.
+type MemoryRuleSetRepository struct {
+	mu   sync.Mutex
+	sets map[string]models.ExtractionRuleSet
+}
+
+func NewMemoryRuleSetRepository() *MemoryRuleSetRepository {
+	return &MemoryRuleSetRepository{sets: make(map[string]models.ExtractionRuleSet)}
+}
+
+func (r *MemoryRuleSetRepository) Create(ctx context.Context, set *models.ExtractionRuleSet) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.sets {
+		if existing.Name == set.Name {
+			return fmt.Errorf("rule set %q already exists", set.Name)
+		}
+	}
+	if err := set.BeforeCreate(nil); err != nil {
+		return err
+	}
+	set.CreatedAt = time.Now()
+	set.UpdatedAt = set.CreatedAt
+	r.sets[set.ID] = *set
+	return nil
+}
+
+func (r *MemoryRuleSetRepository) List(ctx context.Context) ([]models.ExtractionRuleSet, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	sets := make([]models.ExtractionRuleSet, 0, len(r.sets))
+	for _, set := range r.sets {
+		sets = append(sets, set)
+	}
+	sort.Slice(sets, func(i, j int) bool { return sets[i].Name < sets[j].Name })
+	return sets, nil
+}
+
+func (r *MemoryRuleSetRepository) Get(ctx context.Context, id string) (*models.ExtractionRuleSet, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	set, ok := r.sets[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &set, nil
+}
+
+func (r *MemoryRuleSetRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.sets[id]; !ok {
+		return ErrNotFound
+	}
+	delete(r.sets, id)
+	return nil
+}
+
+// MemoryAlertRepository keeps alert rules and alerts in memory, for tests.
+type MemoryAlertRepository struct {
+	mu          sync.Mutex
+	rules       map[string]models.AlertRule
+	states      []models.AlertState
+	lastStateID uint
+	alerts      []models.Alert
+}
+
+func NewMemoryAlertRepository() *MemoryAlertRepository {
+	return &MemoryAlertRepository{rules: make(map[string]models.AlertRule)}
+}
+
+func (r *MemoryAlertRepository) CreateRule(ctx context.Context, rule *models.AlertRule) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := rule.BeforeCreate(nil); err != nil {
+		return err
+	}
+	if _, ok := r.rules[rule.ID]; ok {
+		return fmt.Errorf("alert rule %s already exists", rule.ID)
+	}
+	rule.CreatedAt = time.Now()
+	rule.UpdatedAt = rule.CreatedAt
+	r.rules[rule.ID] = *rule
+	return nil
+}
+
+func (r *MemoryAlertRepository) ListRules(ctx context.Context) ([]models.AlertRule, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rules := make([]models.AlertRule, 0, len(r.rules))
+	for _, rule := range r.rules {
+		rules = append(rules, rule)
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		if rules[i].Name != rules[j].Name {
+			return rules[i].Name < rules[j].Name
+		}
+		return rules[i].ID < rules[j].ID
+	})
+	return rules, nil
+}
+
+func (r *MemoryAlertRepository) GetRule(ctx context.Context, id string) (*models.AlertRule, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rule, ok := r.rules[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &rule, nil
+}
+
+func (r *MemoryAlertRepository) SaveRule(ctx context.Context, rule *models.AlertRule) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.rules[rule.ID]; !ok {
+		return ErrNotFound
+	}
+	rule.UpdatedAt = time.Now()
+	r.rules[rule.ID] = *rule
+	return nil
+}
+
+func (r *MemoryAlertRepository) DeleteRule(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.rules[id]; !ok {
+		return ErrNotFound
+	}
+	delete(r.rules, id)
+	states := r.states[:0]
+	for _, state := range r.states {
+		if state.RuleID != id {
+			states = append(states, state)
+		}
+	}
+	r.states = states
+	return nil
+}
+
+func (r *MemoryAlertRepository) State(ctx context.Context, ruleID, url string) (*models.AlertState, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, state := range r.states {
+		if state.RuleID == ruleID && state.URL == url {
+			return copyState(state), nil
+		}
+	}
+	return &models.AlertState{RuleID: ruleID, URL: url}, nil
+}
+
+func (r *MemoryAlertRepository) SaveState(ctx context.Context, state *models.AlertState) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	state.UpdatedAt = time.Now()
+	if state.ID == 0 {
+		r.lastStateID++
+		state.ID = r.lastStateID
+		r.states = append(r.states, *copyState(*state))
+		return nil
+	}
+	for i := range r.states {
+		if r.states[i].ID == state.ID {
+			r.states[i] = *copyState(*state)
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+// copyState keeps callers from changing a stored state through its pointers.
+func copyState(state models.AlertState) *models.AlertState {
+	if state.LastTitle != nil {
+		title := *state.LastTitle
+		state.LastTitle = &title
+	}
+	if state.AlertID != nil {
+		id := *state.AlertID
+		state.AlertID = &id
+	}
+	return &state
+}
+
+func (r *MemoryAlertRepository) CreateAlert(ctx context.Context, alert *models.Alert) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	alert.ID = uint(len(r.alerts) + 1)
+	if alert.CreatedAt.IsZero() {
+		alert.CreatedAt = time.Now()
+	}
+	r.alerts = append(r.alerts, *alert)
+	return nil
+}
+
+func (r *MemoryAlertRepository) GetAlert(ctx context.Context, id uint) (*models.Alert, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if id == 0 || int(id) > len(r.alerts) {
+		return nil, ErrNotFound
+	}
+	alert := r.alerts[id-1]
+	return &alert, nil
+}
+
+func (r *MemoryAlertRepository) SaveAlert(ctx context.Context, alert *models.Alert) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if alert.ID == 0 || int(alert.ID) > len(r.alerts) {
+		return ErrNotFound
+	}
+	r.alerts[alert.ID-1] = *alert
+	return nil
+}
+
+func (r *MemoryAlertRepository) ListAlerts(ctx context.Context, filter AlertFilter) ([]models.Alert, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var matches []models.Alert
+	for i := len(r.alerts) - 1; i >= 0; i-- {
+		a := r.alerts[i]
+		if (filter.RuleID != "" && a.RuleID != filter.RuleID) || (filter.URL != "" && a.URL != filter.URL) ||
+			(filter.Domain != "" && a.Domain != filter.Domain) ||
+			(filter.Open != nil && *filter.Open != (a.ResolvedAt == nil)) {
+			continue
+		}
+		matches = append(matches, a)
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].CreatedAt.After(matches[j].CreatedAt) })
+
+	total := int64(len(matches))
+	if filter.Offset >= len(matches) {
+		return []models.Alert{}, total, nil
+	}
+	matches = matches[filter.Offset:]
+	if filter.Limit > 0 && len(matches) > filter.Limit {
+		matches = matches[:filter.Limit]
+	}
+	return matches, total, nil
+}
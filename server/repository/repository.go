@@ -0,0 +1,207 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/saqibroy/web-crawler-dashboard/server/models"
+)
+
+var ErrNotFound = errors.New("record not found")
+
+// AnalysisFilter selects and orders analyses for listing and export. Zero
+// values don't filter.
+type AnalysisFilter struct {
+	// Search matches URLs and titles case-insensitively.
+	Search string
+	// Statuses and HTMLVersions match any of their values.
+	Statuses     []models.AnalysisStatus
+	HTMLVersions []string
+	HasLoginForm *bool
+	// Domain matches the domain and its subdomains.
+	Domain string
+	// Time ranges include their bounds.
+	CreatedFrom, CreatedTo     time.Time
+	CompletedFrom, CompletedTo time.Time
+	MinBrokenLinks             *int
+	MaxBrokenLinks             *int
+
+	// Sort orders by each field in turn, then by ID. Without it, queued and
+	// processing analyses come first, then the most recently updated.
+	Sort   []SortField
+	Offset int
+	Limit  int
+	// WithContent makes Each load the page text, which it otherwise leaves
+	// out like List and ListPage do.
+	WithContent bool
+}
+
+// SortField orders analyses by one of SortColumns.
+type SortField struct {
+	Column string
+	Desc   bool
+}
+
+// SortColumns are the columns analyses can be sorted by.
+var SortColumns = []string{
+	"url", "domain", "status", "title", "html_version", "internal_links", "external_links",
+	"broken_link_count", "has_login_form", "accessibility_score", "security_grade",
+	"created_at", "updated_at", "completed_at",
+	"perf_dns_lookup_ms", "perf_connect_ms", "perf_tls_handshake_ms", "perf_ttfb_ms",
+	"perf_download_ms", "perf_total_ms", "perf_transfer_size", "perf_page_size",
+	"perf_compression_ratio", "perf_script_count", "perf_script_bytes",
+	"perf_stylesheet_count", "perf_stylesheet_bytes", "perf_image_count", "perf_image_bytes",
+}
+
+func IsSortColumn(column string) bool {
+	for _, c := range SortColumns {
+		if c == column {
+			return true
+		}
+	}
+	return false
+}
+
+// MetricFilter selects analysis metrics. Zero values don't filter.
+type MetricFilter struct {
+	// URL and Domain match exactly; Domain leaves out subdomains.
+	URL    string
+	Domain string
+	// The time range includes its bounds.
+	From, To time.Time
+}
+
+// DomainStats aggregates the analyses of one domain.
+type DomainStats struct {
+	Domain    string `json:"domain"`
+	Analyses  int64  `json:"analyses"`
+	Pages     int64  `json:"pages"`
+	Completed int64  `json:"completed"`
+	Failed    int64  `json:"failed"`
+	// BrokenLinks adds up the broken links found by the latest completed
+	// analysis of each page, so re-running a page doesn't count them twice.
+	BrokenLinks    int64      `json:"broken_links"`
+	LastAnalyzedAt *time.Time `json:"last_analyzed_at"`
+}
+
+// DomainFilter selects and orders domains. Zero values don't filter.
+type DomainFilter struct {
+	// Search matches domain names case-insensitively; Domain matches one
+	// exactly.
+	Search string
+	Domain string
+	// Sort orders by one of DomainSortColumns, then by domain. Without it,
+	// domains are ordered by name.
+	Sort   *SortField
+	Offset int
+	Limit  int
+}
+
+// DomainSortColumns are the columns domains can be sorted by.
+var DomainSortColumns = []string{"domain", "analyses", "pages", "completed", "failed", "broken_links", "last_analyzed_at"}
+
+func IsDomainSortColumn(column string) bool {
+	for _, c := range DomainSortColumns {
+		if c == column {
+			return true
+		}
+	}
+	return false
+}
+
+// AnalysisRepository stores analyses and their analyzer results.
+type AnalysisRepository interface {
+	// Create assigns IDs to and stores new analyses.
+	Create(ctx context.Context, analyses ...*models.Analysis) error
+	// Get returns an analysis with its analyzer results, or ErrNotFound.
+	Get(ctx context.Context, id string) (*models.Analysis, error)
+	// List returns one page of the analyses matching filter, without
+	// analyzer results or page text, and the number of matches across all
+	// pages.
+	List(ctx context.Context, filter AnalysisFilter) ([]models.Analysis, int64, error)
+	// ListPage returns up to limit analyses matching filter that follow the
+	// position cursor points at, or the first page for an empty cursor,
+	// ignoring the filter's offset and limit. Its cursors stay valid as
+	// analyses are added and removed. A malformed cursor, or one issued for
+	// another sort order, gives ErrInvalidCursor.
+	ListPage(ctx context.Context, filter AnalysisFilter, cursor string, limit int) (*AnalysisPage, error)
+	// Each calls fn for every analysis matching filter, ignoring its offset
+	// and limit, without holding them all in memory. Analyzer results are
+	// left out, and so is the page text unless filter.WithContent is set.
+	Each(ctx context.Context, filter AnalysisFilter, fn func(*models.Analysis) error) error
+	// Domains returns one page of the domains analyses were made for, with
+	// their statistics, and the number of matching domains across all
+	// pages. Analyses of URLs without a host name are left out.
+	Domains(ctx context.Context, filter DomainFilter) ([]DomainStats, int64, error)
+	// CountByStatus counts every analysis by status.
+	CountByStatus(ctx context.Context) (map[models.AnalysisStatus]int64, error)
+	// ClaimNext moves the oldest queued analysis to processing, setting
+	// StartedAt, and returns it, or returns nil when nothing is queued.
+	// Concurrent callers never claim the same analysis.
+	ClaimNext(ctx context.Context) (*models.Analysis, error)
+	// UpdateStatus sets the status of the given analyses that are currently
+	// in one of the from statuses and returns how many changed.
+	UpdateStatus(ctx context.Context, ids []string, to models.AnalysisStatus, from ...models.AnalysisStatus) (int64, error)
+	// Save writes every column of an existing analysis and replaces its
+	// analyzer results with a.Results. A pending a.Metric is stored and
+	// cleared.
+	Save(ctx context.Context, a *models.Analysis) error
+	// Metrics calls fn for every metric matching filter, oldest first,
+	// without holding them all in memory.
+	Metrics(ctx context.Context, filter MetricFilter, fn func(*models.AnalysisMetric) error) error
+	// Delete removes analyses and their results and returns how many existed.
+	Delete(ctx context.Context, ids []string) (int64, error)
+}
+
+// RuleSetRepository stores saved extraction rule sets.
+type RuleSetRepository interface {
+	Create(ctx context.Context, set *models.ExtractionRuleSet) error
+	// List returns every rule set ordered by name.
+	List(ctx context.Context) ([]models.ExtractionRuleSet, error)
+	// Get returns a rule set or ErrNotFound.
+	Get(ctx context.Context, id string) (*models.ExtractionRuleSet, error)
+	// Delete removes a rule set and returns ErrNotFound if it didn't exist.
+	Delete(ctx context.Context, id string) error
+}
+
+// AlertFilter selects alerts, newest first. Zero values don't filter.
+type AlertFilter struct {
+	RuleID string
+	// URL and Domain match exactly; Domain leaves out subdomains.
+	URL    string
+	Domain string
+	// Open selects unresolved alerts when true and resolved ones when false.
+	Open   *bool
+	Offset int
+	Limit  int
+}
+
+// AlertRepository stores alert rules, the state they keep per URL and the
+// alerts they raise.
+type AlertRepository interface {
+	CreateRule(ctx context.Context, rule *models.AlertRule) error
+	// ListRules returns every rule ordered by name.
+	ListRules(ctx context.Context) ([]models.AlertRule, error)
+	// GetRule returns a rule or ErrNotFound.
+	GetRule(ctx context.Context, id string) (*models.AlertRule, error)
+	// SaveRule writes every column of an existing rule.
+	SaveRule(ctx context.Context, rule *models.AlertRule) error
+	// DeleteRule removes a rule and its state, keeping its alerts, and
+	// returns ErrNotFound if it didn't exist.
+	DeleteRule(ctx context.Context, id string) error
+
+	// State returns what a rule remembers about a URL, or a new, unsaved
+	// state when it has nothing.
+	State(ctx context.Context, ruleID, url string) (*models.AlertState, error)
+	// SaveState creates or updates a state.
+	SaveState(ctx context.Context, state *models.AlertState) error
+
+	CreateAlert(ctx context.Context, alert *models.Alert) error
+	// GetAlert returns an alert or ErrNotFound.
+	GetAlert(ctx context.Context, id uint) (*models.Alert, error)
+	SaveAlert(ctx context.Context, alert *models.Alert) error
+	// ListAlerts returns one page of the alerts matching filter and the
+	// number of matches across all pages.
+	ListAlerts(ctx context.Context, filter AlertFilter) ([]models.Alert, int64, error)
+}
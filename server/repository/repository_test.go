@@ -0,0 +1,882 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/saqibroy/web-crawler-dashboard/server/db"
+	"github.com/saqibroy/web-crawler-dashboard/server/models"
+	"gorm.io/gorm"
+)
+
+// Both implementations run the same tests, so the in-memory one can stand in
+// for the database elsewhere.
+func repositories(t *testing.T) map[string]func(t *testing.T) (AnalysisRepository, RuleSetRepository) {
+	return map[string]func(t *testing.T) (AnalysisRepository, RuleSetRepository){
+		"memory": func(t *testing.T) (AnalysisRepository, RuleSetRepository) {
+			return NewMemoryAnalysisRepository(), NewMemoryRuleSetRepository()
+		},
+		"gorm": func(t *testing.T) (AnalysisRepository, RuleSetRepository) {
+			conn := openDatabase(t)
+			return NewGormAnalysisRepository(conn), NewGormRuleSetRepository(conn)
+		},
+	}
+}
+
+func alertRepositories() map[string]func(t *testing.T) AlertRepository {
+	return map[string]func(t *testing.T) AlertRepository{
+		"memory": func(t *testing.T) AlertRepository { return NewMemoryAlertRepository() },
+		"gorm":   func(t *testing.T) AlertRepository { return NewGormAlertRepository(openDatabase(t)) },
+	}
+}
+
+// openDatabase returns a migrated in-memory SQLite database.
+func openDatabase(t *testing.T) *gorm.DB {
+	conn, err := db.Open("sqlite://:memory:")
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	if err := db.Migrate(conn); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := conn.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return conn
+}
+
+func forEachRepository(t *testing.T, test func(t *testing.T, analyses AnalysisRepository, ruleSets RuleSetRepository)) {
+	for name, open := range repositories(t) {
+		t.Run(name, func(t *testing.T) {
+			analyses, ruleSets := open(t)
+			test(t, analyses, ruleSets)
+		})
+	}
+}
+
+func create(t *testing.T, repo AnalysisRepository, analyses ...*models.Analysis) {
+	t.Helper()
+	for _, a := range analyses {
+		if a.Status == "" {
+			a.Status = models.Queued
+		}
+		if err := repo.Create(context.Background(), a); err != nil {
+			t.Fatalf("create %s: %v", a.URL, err)
+		}
+		// Keep creation times apart so ordering is predictable
+		time.Sleep(2 * time.Millisecond)
+	}
+}
+
+func TestCreateAndGet(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repo AnalysisRepository, _ RuleSetRepository) {
+		ctx := context.Background()
+		a := &models.Analysis{URL: "https://example.com", Analyzers: models.StringList{"headings"}}
+		create(t, repo, a)
+
+		if len(a.ID) != 36 {
+			t.Fatalf("expected a UUID to be assigned, got %q", a.ID)
+		}
+
+		got, err := repo.Get(ctx, a.ID)
+		if err != nil {
+			t.Fatalf("get: %v", err)
+		}
+		if got.URL != a.URL || got.Status != models.Queued || len(got.Analyzers) != 1 {
+			t.Errorf("unexpected analysis: %+v", got)
+		}
+
+		if _, err := repo.Get(ctx, "00000000-0000-0000-0000-000000000000"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	})
+}
+
+func TestListFiltersSortsAndPaginates(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repo AnalysisRepository, _ RuleSetRepository) {
+		ctx := context.Background()
+		create(t, repo,
+			&models.Analysis{URL: "https://b.example.com", Title: "Beta", InternalLinks: 5, Status: models.Completed},
+			&models.Analysis{URL: "https://a.example.com", Title: "Alpha", InternalLinks: 9, Status: models.Failed},
+			&models.Analysis{URL: "https://c.example.org", Title: "Gamma", InternalLinks: 1},
+		)
+
+		all, total, err := repo.List(ctx, AnalysisFilter{})
+		if err != nil {
+			t.Fatalf("list: %v", err)
+		}
+		if total != 3 || len(all) != 3 {
+			t.Fatalf("expected 3 analyses, got %d (total %d)", len(all), total)
+		}
+		// Queued first, then the most recently updated
+		if all[0].URL != "https://c.example.org" || all[1].URL != "https://a.example.com" {
+			t.Errorf("unexpected default order: %s, %s, %s", all[0].URL, all[1].URL, all[2].URL)
+		}
+
+		found, total, _ := repo.List(ctx, AnalysisFilter{Search: "ALPHA"})
+		if total != 1 || found[0].Title != "Alpha" {
+			t.Errorf("search should match titles case-insensitively, got %d results", total)
+		}
+		found, total, _ = repo.List(ctx, AnalysisFilter{Search: "example.com"})
+		if total != 2 {
+			t.Errorf("search should match URLs, got %d results", total)
+		}
+
+		found, total, _ = repo.List(ctx, AnalysisFilter{Statuses: []models.AnalysisStatus{models.Completed}})
+		if total != 1 || found[0].Status != models.Completed {
+			t.Errorf("status filter returned %d results", total)
+		}
+
+		found, _, _ = repo.List(ctx, AnalysisFilter{Sort: []SortField{{Column: "internal_links", Desc: true}}})
+		if found[0].InternalLinks != 9 || found[2].InternalLinks != 1 {
+			t.Errorf("unexpected descending order by internal_links")
+		}
+		found, _, _ = repo.List(ctx, AnalysisFilter{Sort: []SortField{{Column: "url"}}})
+		if found[0].URL != "https://a.example.com" {
+			t.Errorf("unexpected ascending order by url: %s first", found[0].URL)
+		}
+
+		page, total, _ := repo.List(ctx, AnalysisFilter{Sort: []SortField{{Column: "url"}}, Offset: 1, Limit: 1})
+		if total != 3 || len(page) != 1 || page[0].URL != "https://b.example.com" {
+			t.Errorf("unexpected page: %d results, total %d", len(page), total)
+		}
+	})
+}
+
+func TestListAdvancedFilters(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repo AnalysisRepository, _ RuleSetRepository) {
+		ctx := context.Background()
+		day := func(d int) *time.Time {
+			at := time.Date(2025, 3, d, 12, 0, 0, 0, time.UTC)
+			return &at
+		}
+		links := func(n int) models.JSONMap {
+			broken := models.JSONMap{}
+			for i := 0; i < n; i++ {
+				broken[fmt.Sprintf("https://example.com/%d", i)] = "Broken"
+			}
+			return broken
+		}
+		create(t, repo,
+			&models.Analysis{URL: "https://Example.com/a", Status: models.Completed, HTMLVersion: "HTML5", HasLoginForm: true, BrokenLinks: links(3), CreatedAt: *day(1), CompletedAt: day(1)},
+			&models.Analysis{URL: "https://shop.example.com:8443/b?x=1", Status: models.Completed, HTMLVersion: "HTML 4.01 Strict", BrokenLinks: links(1), CreatedAt: *day(5), CompletedAt: day(6)},
+			&models.Analysis{URL: "https://notexample.com/c", Status: models.Failed, HTMLVersion: "HTML5", CreatedAt: *day(10)},
+			&models.Analysis{URL: "https://example.org/d", Status: models.Queued, CreatedAt: *day(20)},
+		)
+
+		urls := func(filter AnalysisFilter) []string {
+			t.Helper()
+			filter.Sort = []SortField{{Column: "created_at"}}
+			found, total, err := repo.List(ctx, filter)
+			if err != nil {
+				t.Fatalf("list: %v", err)
+			}
+			if int(total) != len(found) {
+				t.Fatalf("total %d for %d results", total, len(found))
+			}
+			urls := make([]string, len(found))
+			for i, a := range found {
+				urls[i] = a.URL
+			}
+			return urls
+		}
+		yes, one, two := true, 1, 2
+
+		tests := []struct {
+			name   string
+			filter AnalysisFilter
+			want   string
+		}{
+			{"statuses", AnalysisFilter{Statuses: []models.AnalysisStatus{models.Failed, models.Queued}}, "notexample.com/c example.org/d"},
+			{"html versions", AnalysisFilter{HTMLVersions: []string{"HTML5"}}, "Example.com/a notexample.com/c"},
+			{"login form", AnalysisFilter{HasLoginForm: &yes}, "Example.com/a"},
+			{"domain and subdomains", AnalysisFilter{Domain: "example.com"}, "Example.com/a shop.example.com:8443/b?x=1"},
+			{"created range", AnalysisFilter{CreatedFrom: *day(5), CreatedTo: *day(10)}, "shop.example.com:8443/b?x=1 notexample.com/c"},
+			{"completed range", AnalysisFilter{CompletedFrom: *day(2)}, "shop.example.com:8443/b?x=1"},
+			{"broken link range", AnalysisFilter{MinBrokenLinks: &one, MaxBrokenLinks: &two}, "shop.example.com:8443/b?x=1"},
+			{"combined", AnalysisFilter{Statuses: []models.AnalysisStatus{models.Completed}, MinBrokenLinks: &two}, "Example.com/a"},
+		}
+		for _, tt := range tests {
+			got := strings.ReplaceAll(strings.Join(urls(tt.filter), " "), "https://", "")
+			if got != tt.want {
+				t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+			}
+		}
+
+		found, _, _ := repo.List(ctx, AnalysisFilter{Sort: []SortField{{Column: "status"}, {Column: "broken_link_count", Desc: true}}})
+		var order []string
+		for _, a := range found {
+			order = append(order, fmt.Sprintf("%s/%d", a.Status, a.BrokenLinkCount))
+		}
+		if got := strings.Join(order, " "); got != "completed/3 completed/1 failed/0 queued/0" {
+			t.Errorf("unexpected multi-column order: %s", got)
+		}
+	})
+}
+
+func TestListPage(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repo AnalysisRepository, _ RuleSetRepository) {
+		ctx := context.Background()
+		day := func(d int) *time.Time {
+			at := time.Date(2025, 3, d, 12, 0, 0, 0, time.UTC)
+			return &at
+		}
+		// Repeated values and NULLs, so ties are broken by later keys and IDs
+		create(t, repo,
+			&models.Analysis{URL: "https://a.example.com", Title: "Same", Status: models.Completed, HasLoginForm: true, CompletedAt: day(2)},
+			&models.Analysis{URL: "https://b.example.com", Title: "Same", Status: models.Failed},
+			&models.Analysis{URL: "https://c.example.com", Title: "Other", Status: models.Completed, CompletedAt: day(2)},
+			&models.Analysis{URL: "https://d.example.com", Title: "Same"},
+			&models.Analysis{URL: "https://e.example.com", Title: "Other", Status: models.Completed, HasLoginForm: true, CompletedAt: day(1)},
+			&models.Analysis{URL: "https://f.example.com", Title: "Same", Status: models.Processing},
+			&models.Analysis{URL: "https://g.example.com", Title: "Other"},
+		)
+
+		ids := func(analyses []models.Analysis) string {
+			var ids []string
+			for _, a := range analyses {
+				ids = append(ids, a.ID)
+			}
+			return strings.Join(ids, " ")
+		}
+
+		sorts := [][]SortField{
+			nil,
+			{{Column: "title"}},
+			{{Column: "completed_at"}},
+			{{Column: "completed_at", Desc: true}, {Column: "title", Desc: true}},
+			{{Column: "has_login_form", Desc: true}, {Column: "status"}},
+		}
+		for _, sort := range sorts {
+			filter := AnalysisFilter{Sort: sort}
+			all, _, err := repo.List(ctx, filter)
+			if err != nil {
+				t.Fatalf("list: %v", err)
+			}
+
+			// Forwards through every page, then back again
+			var pages []*AnalysisPage
+			var forward []models.Analysis
+			for cursor := ""; ; {
+				page, err := repo.ListPage(ctx, filter, cursor, 3)
+				if err != nil {
+					t.Fatalf("%v: list page: %v", sort, err)
+				}
+				if page.Total != 7 {
+					t.Errorf("%v: expected a total of 7, got %d", sort, page.Total)
+				}
+				pages = append(pages, page)
+				forward = append(forward, page.Analyses...)
+				if cursor = page.Next; cursor == "" {
+					break
+				}
+			}
+			if got, want := ids(forward), ids(all); got != want || len(pages) != 3 {
+				t.Errorf("%v: paging forwards gave %d pages:\n%s\nwant\n%s", sort, len(pages), got, want)
+			}
+			if pages[0].Prev != "" {
+				t.Errorf("%v: expected no cursor before the first page", sort)
+			}
+
+			for i := len(pages) - 1; i > 0; i-- {
+				prev, err := repo.ListPage(ctx, filter, pages[i].Prev, 3)
+				if err != nil {
+					t.Fatalf("%v: list previous page: %v", sort, err)
+				}
+				if got, want := ids(prev.Analyses), ids(pages[i-1].Analyses); got != want {
+					t.Errorf("%v: paging back to page %d gave %s, want %s", sort, i, got, want)
+				}
+				if (prev.Prev == "") != (i == 1) || prev.Next == "" {
+					t.Errorf("%v: unexpected cursors on page %d: %+v", sort, i, prev)
+				}
+			}
+		}
+
+		// Analyses added ahead of the cursor don't shift the next page
+		filter := AnalysisFilter{Sort: []SortField{{Column: "url"}}}
+		first, _ := repo.ListPage(ctx, filter, "", 2)
+		create(t, repo, &models.Analysis{URL: "https://0.example.com"})
+		next, _ := repo.ListPage(ctx, filter, first.Next, 2)
+		if next.Analyses[0].URL != "https://c.example.com" || next.Total != 8 {
+			t.Errorf("expected the page to continue after the cursor, got %s of %d", next.Analyses[0].URL, next.Total)
+		}
+
+		filtered, _ := repo.ListPage(ctx, AnalysisFilter{Statuses: []models.AnalysisStatus{models.Completed}}, "", 2)
+		if filtered.Total != 3 || len(filtered.Analyses) != 2 || filtered.Analyses[0].Content != "" {
+			t.Errorf("unexpected filtered page: %+v", filtered)
+		}
+
+		if _, err := repo.ListPage(ctx, filter, "not a cursor", 2); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("expected ErrInvalidCursor for a malformed cursor, got %v", err)
+		}
+		if _, err := repo.ListPage(ctx, AnalysisFilter{}, first.Next, 2); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("expected ErrInvalidCursor for a cursor from another order, got %v", err)
+		}
+	})
+}
+
+func TestDomains(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repo AnalysisRepository, _ RuleSetRepository) {
+		ctx := context.Background()
+		day := func(d int) *time.Time {
+			at := time.Date(2025, 3, d, 12, 0, 0, 0, time.UTC)
+			return &at
+		}
+		broken := func(n int) models.JSONMap {
+			links := models.JSONMap{}
+			for i := 0; i < n; i++ {
+				links[fmt.Sprintf("https://example.com/%d", i)] = "Broken"
+			}
+			return links
+		}
+		create(t, repo,
+			// A page analysed twice only counts its latest broken links
+			&models.Analysis{URL: "https://example.com/a", Status: models.Completed, BrokenLinks: broken(4), CompletedAt: day(1)},
+			&models.Analysis{URL: "https://example.com/a", Status: models.Completed, BrokenLinks: broken(1), CompletedAt: day(3)},
+			&models.Analysis{URL: "https://EXAMPLE.com/b", Status: models.Completed, BrokenLinks: broken(2), CompletedAt: day(2)},
+			&models.Analysis{URL: "https://example.com/c", Status: models.Failed, FailureReason: "timeout"},
+			&models.Analysis{URL: "https://shop.example.com", Status: models.Queued},
+			&models.Analysis{URL: "https://other.org", Status: models.Completed, CompletedAt: day(5)},
+			&models.Analysis{URL: "not a url"},
+		)
+
+		stats, total, err := repo.Domains(ctx, DomainFilter{})
+		if err != nil {
+			t.Fatalf("domains: %v", err)
+		}
+		if total != 3 || len(stats) != 3 {
+			t.Fatalf("expected 3 domains, got %d (total %d)", len(stats), total)
+		}
+		got := stats[0]
+		if got.Domain != "example.com" || got.Analyses != 4 || got.Pages != 3 || got.Completed != 3 ||
+			got.Failed != 1 || got.BrokenLinks != 3 || got.LastAnalyzedAt == nil || !got.LastAnalyzedAt.Equal(*day(3)) {
+			t.Errorf("unexpected stats: %+v", got)
+		}
+		if stats[2].Domain != "shop.example.com" || stats[2].LastAnalyzedAt != nil {
+			t.Errorf("unexpected stats: %+v", stats[2])
+		}
+
+		domains := func(filter DomainFilter) string {
+			t.Helper()
+			stats, _, err := repo.Domains(ctx, filter)
+			if err != nil {
+				t.Fatalf("domains: %v", err)
+			}
+			var names []string
+			for _, s := range stats {
+				names = append(names, s.Domain)
+			}
+			return strings.Join(names, " ")
+		}
+		tests := []struct {
+			name   string
+			filter DomainFilter
+			want   string
+		}{
+			{"search", DomainFilter{Search: "EXAMPLE"}, "example.com shop.example.com"},
+			{"exact", DomainFilter{Domain: "example.com"}, "example.com"},
+			{"by broken links", DomainFilter{Sort: &SortField{Column: "broken_links", Desc: true}}, "example.com other.org shop.example.com"},
+			{"by last analysed, nulls last", DomainFilter{Sort: &SortField{Column: "last_analyzed_at", Desc: true}}, "other.org example.com shop.example.com"},
+			{"by name descending", DomainFilter{Sort: &SortField{Column: "domain", Desc: true}}, "shop.example.com other.org example.com"},
+			{"paginated", DomainFilter{Offset: 1, Limit: 1}, "other.org"},
+		}
+		for _, tt := range tests {
+			if got := domains(tt.filter); got != tt.want {
+				t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+			}
+		}
+	})
+}
+
+func TestDerivedColumns(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repo AnalysisRepository, _ RuleSetRepository) {
+		ctx := context.Background()
+		a := &models.Analysis{URL: "https://WWW.Example.com:8080/path"}
+		create(t, repo, a)
+
+		a.MarkAsCompleted(&models.Analysis{BrokenLinks: models.JSONMap{"https://example.com/x": "Broken"}})
+		if err := repo.Save(ctx, a); err != nil {
+			t.Fatalf("save: %v", err)
+		}
+		if _, err := repo.UpdateStatus(ctx, []string{a.ID}, models.Queued); err != nil {
+			t.Fatalf("update status: %v", err)
+		}
+
+		got, _ := repo.Get(ctx, a.ID)
+		if got.Domain != "www.example.com" || got.BrokenLinkCount != 1 {
+			t.Errorf("unexpected derived columns: %q, %d", got.Domain, got.BrokenLinkCount)
+		}
+	})
+}
+
+func TestEach(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repo AnalysisRepository, _ RuleSetRepository) {
+		create(t, repo,
+			&models.Analysis{URL: "https://a.example.com", Content: "page text"},
+			&models.Analysis{URL: "https://b.example.com"},
+			&models.Analysis{URL: "https://c.example.org"},
+		)
+
+		var urls []string
+		err := repo.Each(context.Background(), AnalysisFilter{Search: ".com", Sort: []SortField{{Column: "url"}}, Limit: 1}, func(a *models.Analysis) error {
+			urls = append(urls, a.URL)
+			if a.Content != "" {
+				t.Errorf("expected the page text to be left out, got %q", a.Content)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("each: %v", err)
+		}
+		if len(urls) != 2 || urls[0] != "https://a.example.com" {
+			t.Errorf("unexpected analyses: %v", urls)
+		}
+
+		var content string
+		err = repo.Each(context.Background(), AnalysisFilter{Search: "a.example", WithContent: true}, func(a *models.Analysis) error {
+			content = a.Content
+			return nil
+		})
+		if err != nil || content != "page text" {
+			t.Errorf("expected the page text with WithContent, got %q (%v)", content, err)
+		}
+
+		stop := errors.New("stop")
+		err = repo.Each(context.Background(), AnalysisFilter{}, func(*models.Analysis) error { return stop })
+		if !errors.Is(err, stop) {
+			t.Errorf("expected the callback's error, got %v", err)
+		}
+	})
+}
+
+func TestCountByStatus(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repo AnalysisRepository, _ RuleSetRepository) {
+		create(t, repo,
+			&models.Analysis{URL: "https://a.example.com"},
+			&models.Analysis{URL: "https://b.example.com"},
+			&models.Analysis{URL: "https://c.example.com", Status: models.Failed},
+		)
+
+		counts, err := repo.CountByStatus(context.Background())
+		if err != nil {
+			t.Fatalf("count: %v", err)
+		}
+		if counts[models.Queued] != 2 || counts[models.Failed] != 1 || counts[models.Completed] != 0 {
+			t.Errorf("unexpected counts: %v", counts)
+		}
+		if _, ok := counts[models.Cancelled]; !ok {
+			t.Errorf("every status should be counted, got %v", counts)
+		}
+	})
+}
+
+func TestClaimNext(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repo AnalysisRepository, _ RuleSetRepository) {
+		ctx := context.Background()
+		first := &models.Analysis{URL: "https://first.example.com"}
+		second := &models.Analysis{URL: "https://second.example.com"}
+		create(t, repo, &models.Analysis{URL: "https://done.example.com", Status: models.Completed}, first, second)
+
+		claimed, err := repo.ClaimNext(ctx)
+		if err != nil || claimed == nil {
+			t.Fatalf("claim: %v, %v", claimed, err)
+		}
+		if claimed.ID != first.ID || claimed.Status != models.Processing {
+			t.Errorf("expected the oldest queued analysis to be processing, got %s (%s)", claimed.URL, claimed.Status)
+		}
+		stored, _ := repo.Get(ctx, first.ID)
+		if stored.Status != models.Processing {
+			t.Errorf("claim wasn't stored, status is %s", stored.Status)
+		}
+
+		claimed, _ = repo.ClaimNext(ctx)
+		if claimed == nil || claimed.ID != second.ID {
+			t.Fatalf("expected the second analysis, got %v", claimed)
+		}
+
+		claimed, err = repo.ClaimNext(ctx)
+		if claimed != nil || err != nil {
+			t.Errorf("expected nothing left to claim, got %v, %v", claimed, err)
+		}
+	})
+}
+
+func TestClaimNextIsExclusive(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repo AnalysisRepository, _ RuleSetRepository) {
+		for i := 0; i < 10; i++ {
+			create(t, repo, &models.Analysis{URL: fmt.Sprintf("https://example.com/%d", i)})
+		}
+
+		var mu sync.Mutex
+		seen := make(map[string]bool)
+		var wg sync.WaitGroup
+		for w := 0; w < 4; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for {
+					a, err := repo.ClaimNext(context.Background())
+					if err != nil {
+						t.Errorf("claim: %v", err)
+						return
+					}
+					if a == nil {
+						return
+					}
+					mu.Lock()
+					if seen[a.ID] {
+						t.Errorf("%s claimed twice", a.URL)
+					}
+					seen[a.ID] = true
+					mu.Unlock()
+				}
+			}()
+		}
+		wg.Wait()
+
+		if len(seen) != 10 {
+			t.Errorf("expected 10 analyses claimed, got %d", len(seen))
+		}
+	})
+}
+
+func TestUpdateStatus(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repo AnalysisRepository, _ RuleSetRepository) {
+		ctx := context.Background()
+		done := &models.Analysis{URL: "https://done.example.com", Status: models.Completed}
+		running := &models.Analysis{URL: "https://running.example.com", Status: models.Processing}
+		create(t, repo, done, running)
+
+		n, err := repo.UpdateStatus(ctx, []string{done.ID, running.ID}, models.Queued, models.Completed, models.Failed)
+		if err != nil {
+			t.Fatalf("update: %v", err)
+		}
+		if n != 1 {
+			t.Errorf("expected 1 analysis updated, got %d", n)
+		}
+
+		got, _ := repo.Get(ctx, done.ID)
+		if got.Status != models.Queued {
+			t.Errorf("expected queued, got %s", got.Status)
+		}
+		got, _ = repo.Get(ctx, running.ID)
+		if got.Status != models.Processing {
+			t.Errorf("analysis outside the from statuses changed to %s", got.Status)
+		}
+
+		n, _ = repo.UpdateStatus(ctx, []string{running.ID}, models.Failed)
+		if n != 1 {
+			t.Errorf("without from statuses any analysis should change, got %d", n)
+		}
+	})
+}
+
+func TestSaveReplacesResults(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repo AnalysisRepository, _ RuleSetRepository) {
+		ctx := context.Background()
+		a := &models.Analysis{URL: "https://example.com"}
+		create(t, repo, a)
+
+		a.MarkAsCompleted(&models.Analysis{
+			Title:    "Example",
+			Headings: models.JSONMap{"h1": 1},
+			Results: []models.AnalyzerResult{
+				{Analyzer: "headings", Result: models.JSONRaw(`{"h1":1}`)},
+				{Analyzer: "links", Error: "timeout"},
+			},
+		})
+		if err := repo.Save(ctx, a); err != nil {
+			t.Fatalf("save: %v", err)
+		}
+
+		got, _ := repo.Get(ctx, a.ID)
+		if got.Status != models.Completed || got.Title != "Example" || got.CompletedAt == nil {
+			t.Errorf("unexpected analysis after save: %+v", got)
+		}
+		if len(got.Results) != 2 || got.Results[0].Analyzer != "headings" || got.Results[1].Error != "timeout" {
+			t.Fatalf("unexpected results: %+v", got.Results)
+		}
+
+		got.MarkAsCancelled()
+		if err := repo.Save(ctx, got); err != nil {
+			t.Fatalf("save: %v", err)
+		}
+		got, _ = repo.Get(ctx, a.ID)
+		if got.Status != models.Cancelled || got.Title != "" || len(got.Results) != 0 {
+			t.Errorf("cancelling should clear results, got %+v", got)
+		}
+	})
+}
+
+func TestMetrics(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repo AnalysisRepository, _ RuleSetRepository) {
+		ctx := context.Background()
+		page := &models.Analysis{URL: "https://example.com/a"}
+		other := &models.Analysis{URL: "https://example.com/b"}
+		sub := &models.Analysis{URL: "https://blog.example.com"}
+		create(t, repo, page, other, sub)
+
+		claimed, err := repo.ClaimNext(ctx)
+		if err != nil || claimed == nil || claimed.StartedAt == nil {
+			t.Fatalf("expected a started analysis, got %+v (%v)", claimed, err)
+		}
+		if got, _ := repo.Get(ctx, claimed.ID); got.StartedAt == nil {
+			t.Error("expected started_at to be stored")
+		}
+
+		for _, a := range []*models.Analysis{claimed, other, sub} {
+			a.MarkAsCompleted(&models.Analysis{
+				InternalLinks: 3,
+				BrokenLinks:   models.JSONMap{"https://example.com/x": "Broken"},
+				Performance:   models.PerformanceMetrics{TotalMs: 120},
+			})
+			if err := repo.Save(ctx, a); err != nil {
+				t.Fatalf("save: %v", err)
+			}
+			if a.Metric != nil {
+				t.Error("expected the metric to be cleared once stored")
+			}
+		}
+		// Saving without completing again records nothing
+		if err := repo.Save(ctx, other); err != nil {
+			t.Fatalf("save: %v", err)
+		}
+
+		var metrics []models.AnalysisMetric
+		collect := func(filter MetricFilter) {
+			t.Helper()
+			metrics = nil
+			if err := repo.Metrics(ctx, filter, func(m *models.AnalysisMetric) error {
+				metrics = append(metrics, *m)
+				return nil
+			}); err != nil {
+				t.Fatalf("metrics: %v", err)
+			}
+		}
+
+		collect(MetricFilter{Domain: "example.com"})
+		if len(metrics) != 2 {
+			t.Fatalf("expected 2 metrics for the domain, got %+v", metrics)
+		}
+		collect(MetricFilter{URL: page.URL})
+		if len(metrics) != 1 {
+			t.Fatalf("expected 1 metric for the page, got %+v", metrics)
+		}
+		m := metrics[0]
+		if m.AnalysisID != page.ID || m.Domain != "example.com" || m.InternalLinks != 3 || m.BrokenLinks != 1 ||
+			m.CrawlMs == nil || m.PageLoadMs == nil || *m.PageLoadMs != 120 {
+			t.Errorf("unexpected metric: %+v", m)
+		}
+
+		collect(MetricFilter{From: time.Now().Add(time.Hour)})
+		if len(metrics) != 0 {
+			t.Errorf("expected no metrics in the future, got %+v", metrics)
+		}
+	})
+}
+
+func TestDelete(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repo AnalysisRepository, _ RuleSetRepository) {
+		ctx := context.Background()
+		a := &models.Analysis{URL: "https://a.example.com"}
+		b := &models.Analysis{URL: "https://b.example.com"}
+		create(t, repo, a, b)
+
+		n, err := repo.Delete(ctx, []string{a.ID, "00000000-0000-0000-0000-000000000000"})
+		if err != nil {
+			t.Fatalf("delete: %v", err)
+		}
+		if n != 1 {
+			t.Errorf("expected 1 deleted, got %d", n)
+		}
+		if _, err := repo.Get(ctx, a.ID); !errors.Is(err, ErrNotFound) {
+			t.Errorf("deleted analysis still found: %v", err)
+		}
+		if _, err := repo.Get(ctx, b.ID); err != nil {
+			t.Errorf("other analysis was deleted: %v", err)
+		}
+	})
+}
+
+func TestRuleSets(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, _ AnalysisRepository, repo RuleSetRepository) {
+		ctx := context.Background()
+		rules := models.ExtractionRules{{Name: "price", Selector: ".price"}}
+		zeta := &models.ExtractionRuleSet{Name: "zeta", Rules: rules}
+		alpha := &models.ExtractionRuleSet{Name: "alpha", Rules: rules}
+		for _, set := range []*models.ExtractionRuleSet{zeta, alpha} {
+			if err := repo.Create(ctx, set); err != nil {
+				t.Fatalf("create: %v", err)
+			}
+		}
+		if err := repo.Create(ctx, &models.ExtractionRuleSet{Name: "alpha"}); err == nil {
+			t.Error("expected duplicate names to be rejected")
+		}
+
+		sets, err := repo.List(ctx)
+		if err != nil {
+			t.Fatalf("list: %v", err)
+		}
+		if len(sets) != 2 || sets[0].Name != "alpha" {
+			t.Errorf("expected sets ordered by name, got %+v", sets)
+		}
+
+		got, err := repo.Get(ctx, zeta.ID)
+		if err != nil || len(got.Rules) != 1 || got.Rules[0].Selector != ".price" {
+			t.Errorf("unexpected rule set: %+v, %v", got, err)
+		}
+
+		if err := repo.Delete(ctx, zeta.ID); err != nil {
+			t.Fatalf("delete: %v", err)
+		}
+		if err := repo.Delete(ctx, zeta.ID); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound deleting twice, got %v", err)
+		}
+		if _, err := repo.Get(ctx, zeta.ID); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	})
+}
+
+func TestAlerts(t *testing.T) {
+	for name, open := range alertRepositories() {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := open(t)
+
+			rule := &models.AlertRule{Name: "broken", Scope: models.AlertScopeAll, Type: models.AlertBrokenLinks,
+				Channel: models.AlertChannelWebhook, Destination: "https://hooks.example.com", Enabled: true}
+			other := &models.AlertRule{Name: "another", Scope: models.AlertScopeAll, Type: models.AlertTitleChanged,
+				Channel: models.AlertChannelWebhook, Destination: "https://hooks.example.com"}
+			for _, r := range []*models.AlertRule{rule, other} {
+				if err := repo.CreateRule(ctx, r); err != nil {
+					t.Fatalf("create rule: %v", err)
+				}
+			}
+			rules, err := repo.ListRules(ctx)
+			if err != nil || len(rules) != 2 || rules[0].Name != "another" || rules[0].Enabled {
+				t.Fatalf("expected rules ordered by name, got %+v (%v)", rules, err)
+			}
+
+			rule.Threshold = 5
+			if err := repo.SaveRule(ctx, rule); err != nil {
+				t.Fatalf("save rule: %v", err)
+			}
+			if got, err := repo.GetRule(ctx, rule.ID); err != nil || got.Threshold != 5 || !got.Enabled {
+				t.Errorf("unexpected rule: %+v (%v)", got, err)
+			}
+
+			state, err := repo.State(ctx, rule.ID, "https://example.com")
+			if err != nil || state.ID != 0 || state.LastTitle != nil {
+				t.Fatalf("expected a new state, got %+v (%v)", state, err)
+			}
+			title := "Example"
+			state.Failures = 2
+			state.LastTitle = &title
+			if err := repo.SaveState(ctx, state); err != nil {
+				t.Fatalf("save state: %v", err)
+			}
+			state.Failures = 3
+			if err := repo.SaveState(ctx, state); err != nil {
+				t.Fatalf("save state: %v", err)
+			}
+			got, err := repo.State(ctx, rule.ID, "https://example.com")
+			if err != nil || got.ID != state.ID || got.Failures != 3 || got.LastTitle == nil || *got.LastTitle != title {
+				t.Errorf("unexpected state: %+v (%v)", got, err)
+			}
+
+			now := time.Now().UTC().Truncate(time.Second)
+			older := &models.Alert{RuleID: rule.ID, URL: "https://example.com", Domain: "example.com", Occurrences: 1,
+				CreatedAt: now.Add(-time.Hour), LastSeenAt: now.Add(-time.Hour)}
+			newer := &models.Alert{RuleID: other.ID, URL: "https://example.org", Domain: "example.org", Occurrences: 1,
+				CreatedAt: now, LastSeenAt: now}
+			for _, a := range []*models.Alert{older, newer} {
+				if err := repo.CreateAlert(ctx, a); err != nil {
+					t.Fatalf("create alert: %v", err)
+				}
+			}
+			older.ResolvedAt = &now
+			if err := repo.SaveAlert(ctx, older); err != nil {
+				t.Fatalf("save alert: %v", err)
+			}
+			if got, err := repo.GetAlert(ctx, older.ID); err != nil || got.ResolvedAt == nil {
+				t.Errorf("unexpected alert: %+v (%v)", got, err)
+			}
+			if _, err := repo.GetAlert(ctx, 999); !errors.Is(err, ErrNotFound) {
+				t.Errorf("expected ErrNotFound, got %v", err)
+			}
+
+			list, total, err := repo.ListAlerts(ctx, AlertFilter{})
+			if err != nil || total != 2 || list[0].ID != newer.ID {
+				t.Errorf("expected the newest alert first, got %+v (%d, %v)", list, total, err)
+			}
+			open := true
+			for _, tt := range []struct {
+				filter AlertFilter
+				want   uint
+			}{
+				{AlertFilter{RuleID: rule.ID}, older.ID},
+				{AlertFilter{URL: "https://example.org"}, newer.ID},
+				{AlertFilter{Domain: "example.com"}, older.ID},
+				{AlertFilter{Open: &open}, newer.ID},
+				{AlertFilter{Offset: 1, Limit: 1}, older.ID},
+			} {
+				list, _, err := repo.ListAlerts(ctx, tt.filter)
+				if err != nil || len(list) != 1 || list[0].ID != tt.want {
+					t.Errorf("%+v: expected alert %d, got %+v (%v)", tt.filter, tt.want, list, err)
+				}
+			}
+
+			// Deleting a rule forgets its state but keeps its alerts
+			if err := repo.DeleteRule(ctx, rule.ID); err != nil {
+				t.Fatalf("delete rule: %v", err)
+			}
+			if err := repo.DeleteRule(ctx, rule.ID); !errors.Is(err, ErrNotFound) {
+				t.Errorf("expected ErrNotFound deleting twice, got %v", err)
+			}
+			if got, _ := repo.State(ctx, rule.ID, "https://example.com"); got.ID != 0 {
+				t.Errorf("expected the state to be deleted, got %+v", got)
+			}
+			if _, total, _ := repo.ListAlerts(ctx, AlertFilter{}); total != 2 {
+				t.Errorf("expected alerts to be kept, got %d", total)
+			}
+		})
+	}
+}
+
+func TestStatusCountCache(t *testing.T) {
+	ctx := context.Background()
+	memory := NewMemoryAnalysisRepository()
+	cached := NewStatusCountCache(memory, time.Hour)
+
+	a := &models.Analysis{URL: "https://example.com", Status: models.Queued}
+	if err := cached.Create(ctx, a); err != nil {
+		t.Fatal(err)
+	}
+	if counts, _ := cached.CountByStatus(ctx); counts[models.Queued] != 1 {
+		t.Fatalf("unexpected counts: %v", counts)
+	}
+
+	// Changes behind the cache's back wait for the TTL
+	if _, err := memory.UpdateStatus(ctx, []string{a.ID}, models.Failed); err != nil {
+		t.Fatal(err)
+	}
+	if counts, _ := cached.CountByStatus(ctx); counts[models.Queued] != 1 {
+		t.Errorf("expected cached counts, got %v", counts)
+	}
+
+	// Changes through it take effect at once
+	if _, err := cached.UpdateStatus(ctx, []string{a.ID}, models.Completed); err != nil {
+		t.Fatal(err)
+	}
+	if counts, _ := cached.CountByStatus(ctx); counts[models.Completed] != 1 || counts[models.Failed] != 0 {
+		t.Errorf("expected fresh counts, got %v", counts)
+	}
+}
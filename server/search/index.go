@@ -0,0 +1,249 @@
+package search
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/saqibroy/web-crawler-dashboard/server/models"
+	"github.com/saqibroy/web-crawler-dashboard/server/repository"
+)
+
+// BM25 parameters, and the weight of a title word relative to one in the URL
+// or page text.
+const (
+	bm25K1      = 1.2
+	bm25B       = 0.75
+	titleWeight = 3
+)
+
+// Index is an inverted index of analyses held in memory, for databases
+// without a usable full-text index. It only knows about analyses added to it,
+// so it must be built at startup and kept current with IndexedRepository; a
+// second server sharing the database won't see this one's changes.
+type Index struct {
+	mu          sync.RWMutex
+	postings    map[string]map[string]*posting // word -> analysis ID
+	docs        map[string]indexedDoc          // analysis ID
+	totalLength int
+}
+
+type indexedDoc struct {
+	length int
+	words  []string
+}
+
+type posting struct {
+	freq      float64
+	positions []int
+}
+
+func NewIndex() *Index {
+	return &Index{
+		postings: make(map[string]map[string]*posting),
+		docs:     make(map[string]indexedDoc),
+	}
+}
+
+// Build indexes every stored analysis.
+func (i *Index) Build(ctx context.Context, analyses repository.AnalysisRepository) error {
+	return analyses.Each(ctx, repository.AnalysisFilter{WithContent: true}, func(a *models.Analysis) error {
+		i.Add(a)
+		return nil
+	})
+}
+
+// Add indexes an analysis, replacing what was indexed for it before.
+func (i *Index) Add(a *models.Analysis) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.remove(a.ID)
+
+	doc := indexedDoc{}
+	position := 0
+	for _, field := range []struct {
+		text   string
+		weight float64
+	}{{a.URL, 1}, {a.Title, titleWeight}, {a.Content, 1}} {
+		for _, t := range tokenize(field.text) {
+			docs := i.postings[t.word]
+			if docs == nil {
+				docs = make(map[string]*posting)
+				i.postings[t.word] = docs
+			}
+			p := docs[a.ID]
+			if p == nil {
+				p = &posting{}
+				docs[a.ID] = p
+				doc.words = append(doc.words, t.word)
+			}
+			p.freq += field.weight
+			p.positions = append(p.positions, position)
+			position++
+		}
+		// Leave a gap so phrases don't match across fields
+		position++
+	}
+
+	doc.length = position
+	i.docs[a.ID] = doc
+	i.totalLength += position
+}
+
+func (i *Index) Remove(id string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.remove(id)
+}
+
+func (i *Index) remove(id string) {
+	doc, ok := i.docs[id]
+	if !ok {
+		return
+	}
+	for _, word := range doc.words {
+		docs := i.postings[word]
+		delete(docs, id)
+		if len(docs) == 0 {
+			delete(i.postings, word)
+		}
+	}
+	delete(i.docs, id)
+	i.totalLength -= doc.length
+}
+
+// Match ranks the analyses containing every query term, and every phrase in
+// order, by BM25.
+func (i *Index) Match(ctx context.Context, q Query, offset, limit int) ([]Match, int64, error) {
+	if q.Empty() {
+		return nil, 0, nil
+	}
+
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	lists := make([]map[string]*posting, len(q.Terms))
+	for n, term := range q.Terms {
+		lists[n] = i.postings[term]
+		if len(lists[n]) == 0 {
+			return nil, 0, nil
+		}
+	}
+
+	// Walk the rarest term's analyses, checking the others for each
+	rarest := lists[0]
+	for _, docs := range lists[1:] {
+		if len(docs) < len(rarest) {
+			rarest = docs
+		}
+	}
+
+	count := float64(len(i.docs))
+	avgLength := float64(i.totalLength) / count
+	var matches []Match
+	for id := range rarest {
+		if !i.matches(id, q) {
+			continue
+		}
+
+		length := float64(i.docs[id].length)
+		score := 0.0
+		for _, docs := range lists {
+			idf := math.Log(1 + (count-float64(len(docs))+0.5)/(float64(len(docs))+0.5))
+			freq := docs[id].freq
+			score += idf * freq * (bm25K1 + 1) / (freq + bm25K1*(1-bm25B+bm25B*length/avgLength))
+		}
+		matches = append(matches, Match{ID: id, Score: score})
+	}
+
+	sort.Slice(matches, func(a, b int) bool {
+		if matches[a].Score != matches[b].Score {
+			return matches[a].Score > matches[b].Score
+		}
+		return matches[a].ID < matches[b].ID
+	})
+
+	total := int64(len(matches))
+	if offset >= len(matches) {
+		return nil, total, nil
+	}
+	matches = matches[offset:]
+	if limit > 0 && limit < len(matches) {
+		matches = matches[:limit]
+	}
+	return matches, total, nil
+}
+
+// matches reports whether the analysis has every term and phrase.
+func (i *Index) matches(id string, q Query) bool {
+	for _, term := range q.Terms {
+		if i.postings[term][id] == nil {
+			return false
+		}
+	}
+	for _, phrase := range q.Phrases {
+		if !i.hasPhrase(id, phrase) {
+			return false
+		}
+	}
+	return true
+}
+
+func (i *Index) hasPhrase(id string, phrase []string) bool {
+	for _, start := range i.postings[phrase[0]][id].positions {
+		found := true
+		for n, word := range phrase[1:] {
+			positions := i.postings[word][id].positions
+			at := sort.SearchInts(positions, start+n+1)
+			if at == len(positions) || positions[at] != start+n+1 {
+				found = false
+				break
+			}
+		}
+		if found {
+			return true
+		}
+	}
+	return false
+}
+
+// IndexedRepository keeps an Index current as analyses are created, saved
+// and deleted through it.
+type IndexedRepository struct {
+	repository.AnalysisRepository
+	index *Index
+}
+
+func NewIndexedRepository(analyses repository.AnalysisRepository, index *Index) *IndexedRepository {
+	return &IndexedRepository{AnalysisRepository: analyses, index: index}
+}
+
+func (r *IndexedRepository) Create(ctx context.Context, analyses ...*models.Analysis) error {
+	if err := r.AnalysisRepository.Create(ctx, analyses...); err != nil {
+		return err
+	}
+	for _, a := range analyses {
+		r.index.Add(a)
+	}
+	return nil
+}
+
+func (r *IndexedRepository) Save(ctx context.Context, a *models.Analysis) error {
+	if err := r.AnalysisRepository.Save(ctx, a); err != nil {
+		return err
+	}
+	r.index.Add(a)
+	return nil
+}
+
+func (r *IndexedRepository) Delete(ctx context.Context, ids []string) (int64, error) {
+	n, err := r.AnalysisRepository.Delete(ctx, ids)
+	if err != nil {
+		return n, err
+	}
+	for _, id := range ids {
+		r.index.Remove(id)
+	}
+	return n, nil
+}
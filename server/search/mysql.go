@@ -0,0 +1,63 @@
+package search
+
+import (
+	"context"
+	"strings"
+
+	"github.com/saqibroy/web-crawler-dashboard/server/models"
+	"gorm.io/gorm"
+)
+
+// matchAgainst uses the FULLTEXT index added by the 0006_add_content MySQL
+// migration, whose columns it must list exactly.
+const matchAgainst = "MATCH(url, title, content) AGAINST (? IN BOOLEAN MODE)"
+
+// MySQLEngine searches with MySQL's FULLTEXT index, ranked by MySQL's own
+// relevance. Words shorter than innodb_ft_min_token_size (3 by default) and
+// stopwords are ignored by MySQL.
+type MySQLEngine struct {
+	db *gorm.DB
+}
+
+func NewMySQLEngine(db *gorm.DB) *MySQLEngine {
+	return &MySQLEngine{db: db}
+}
+
+func (e *MySQLEngine) Match(ctx context.Context, q Query, offset, limit int) ([]Match, int64, error) {
+	if q.Empty() {
+		return nil, 0, nil
+	}
+	against := booleanQuery(q)
+	db := e.db.WithContext(ctx)
+
+	var total int64
+	if err := db.Model(&models.Analysis{}).Where(matchAgainst, against).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var matches []Match
+	err := db.Model(&models.Analysis{}).
+		Select("id, "+matchAgainst+" AS score", against).
+		Where(matchAgainst, against).
+		Order("score DESC, id").
+		Offset(offset).
+		Limit(limit).
+		Scan(&matches).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return matches, total, nil
+}
+
+// booleanQuery requires every term and phrase. Query words are only letters
+// and digits, so they can't contain boolean mode operators.
+func booleanQuery(q Query) string {
+	parts := make([]string, 0, len(q.Terms)+len(q.Phrases))
+	for _, term := range q.Terms {
+		parts = append(parts, "+"+term)
+	}
+	for _, phrase := range q.Phrases {
+		parts = append(parts, `+"`+strings.Join(phrase, " ")+`"`)
+	}
+	return strings.Join(parts, " ")
+}
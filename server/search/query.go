@@ -0,0 +1,151 @@
+package search
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+// Query is a parsed search. Every term must match; each phrase must also
+// appear with its words in order.
+type Query struct {
+	Terms   []string
+	Phrases [][]string
+}
+
+// ParseQuery splits a search into lower-cased words, treating text in double
+// quotes as a phrase. An unclosed quote runs to the end of the input.
+func ParseQuery(s string) Query {
+	var q Query
+	seen := make(map[string]bool)
+	add := func(words []string) {
+		for _, word := range words {
+			if !seen[word] {
+				seen[word] = true
+				q.Terms = append(q.Terms, word)
+			}
+		}
+	}
+
+	for i, part := range strings.Split(s, `"`) {
+		found := words(part)
+		// Odd parts sit between quotes
+		if i%2 == 1 && len(found) > 1 {
+			q.Phrases = append(q.Phrases, found)
+		}
+		add(found)
+	}
+	return q
+}
+
+func (q Query) Empty() bool {
+	return len(q.Terms) == 0
+}
+
+// token is a word in a text, with its byte offsets.
+type token struct {
+	word       string
+	start, end int
+}
+
+// tokenize splits text into lower-cased runs of letters and digits.
+func tokenize(text string) []token {
+	var tokens []token
+	start := -1
+	for i, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			tokens = append(tokens, token{strings.ToLower(text[start:i]), start, i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, token{strings.ToLower(text[start:]), start, len(text)})
+	}
+	return tokens
+}
+
+func words(text string) []string {
+	tokens := tokenize(text)
+	words := make([]string, len(tokens))
+	for i, t := range tokens {
+		words[i] = t.word
+	}
+	return words
+}
+
+const snippetWords = 30
+
+// Snippet returns an HTML-escaped excerpt of text around the first match of
+// the query, preferring a phrase, with every query term wrapped in <mark>.
+// Text that doesn't match at all yields its opening words.
+func Snippet(text string, q Query) string {
+	tokens := tokenize(text)
+	if len(tokens) == 0 {
+		return ""
+	}
+
+	terms := make(map[string]bool, len(q.Terms))
+	for _, term := range q.Terms {
+		terms[term] = true
+	}
+
+	from := 0
+	if at := firstMatch(tokens, q, terms); at >= 0 {
+		from = max(0, at-snippetWords/3)
+	}
+	to := min(len(tokens), from+snippetWords)
+
+	var b strings.Builder
+	if from > 0 {
+		b.WriteString("… ")
+	}
+	pos := tokens[from].start
+	for _, t := range tokens[from:to] {
+		if !terms[t.word] {
+			continue
+		}
+		b.WriteString(html.EscapeString(text[pos:t.start]))
+		b.WriteString("<mark>")
+		b.WriteString(html.EscapeString(text[t.start:t.end]))
+		b.WriteString("</mark>")
+		pos = t.end
+	}
+	b.WriteString(html.EscapeString(text[pos:tokens[to-1].end]))
+	if to < len(tokens) {
+		b.WriteString(" …")
+	}
+	return b.String()
+}
+
+// firstMatch returns the index of the token starting the first phrase match,
+// or failing that the first term, or -1.
+func firstMatch(tokens []token, q Query, terms map[string]bool) int {
+	for _, phrase := range q.Phrases {
+		for i := 0; i+len(phrase) <= len(tokens); i++ {
+			if phraseAt(tokens[i:], phrase) {
+				return i
+			}
+		}
+	}
+	for i, t := range tokens {
+		if terms[t.word] {
+			return i
+		}
+	}
+	return -1
+}
+
+func phraseAt(tokens []token, phrase []string) bool {
+	for j, word := range phrase {
+		if tokens[j].word != word {
+			return false
+		}
+	}
+	return true
+}
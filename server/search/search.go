@@ -0,0 +1,75 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/saqibroy/web-crawler-dashboard/server/models"
+	"github.com/saqibroy/web-crawler-dashboard/server/repository"
+)
+
+// Engine finds the analyses whose URL, title or page text match a query.
+type Engine interface {
+	// Match returns one page of matches, best first, and the total number
+	// of matches.
+	Match(ctx context.Context, q Query, offset, limit int) ([]Match, int64, error)
+}
+
+type Match struct {
+	ID    string
+	Score float64
+}
+
+// Hit is a search result with a highlighted excerpt of the page text.
+type Hit struct {
+	ID          string                `json:"id"`
+	URL         string                `json:"url"`
+	Title       string                `json:"title"`
+	Status      models.AnalysisStatus `json:"status"`
+	Score       float64               `json:"score"`
+	Snippet     string                `json:"snippet"`
+	CreatedAt   time.Time             `json:"created_at"`
+	CompletedAt *time.Time            `json:"completed_at"`
+}
+
+// Searcher runs queries on an engine and loads the matching analyses to
+// build snippets.
+type Searcher struct {
+	engine   Engine
+	analyses repository.AnalysisRepository
+}
+
+func New(engine Engine, analyses repository.AnalysisRepository) *Searcher {
+	return &Searcher{engine: engine, analyses: analyses}
+}
+
+func (s *Searcher) Search(ctx context.Context, q Query, offset, limit int) ([]Hit, int64, error) {
+	matches, total, err := s.engine.Match(ctx, q, offset, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	hits := make([]Hit, 0, len(matches))
+	for _, m := range matches {
+		a, err := s.analyses.Get(ctx, m.ID)
+		if errors.Is(err, repository.ErrNotFound) {
+			// Deleted since it was matched
+			continue
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		hits = append(hits, Hit{
+			ID:          a.ID,
+			URL:         a.URL,
+			Title:       a.Title,
+			Status:      a.Status,
+			Score:       m.Score,
+			Snippet:     Snippet(a.Content, q),
+			CreatedAt:   a.CreatedAt,
+			CompletedAt: a.CompletedAt,
+		})
+	}
+	return hits, total, nil
+}
@@ -0,0 +1,146 @@
+package search
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/saqibroy/web-crawler-dashboard/server/models"
+	"github.com/saqibroy/web-crawler-dashboard/server/repository"
+)
+
+func TestParseQuery(t *testing.T) {
+	q := ParseQuery(`Go "web Crawler" go-lang "single" "unclosed phrase`)
+
+	wantTerms := []string{"go", "web", "crawler", "lang", "single", "unclosed", "phrase"}
+	if !reflect.DeepEqual(q.Terms, wantTerms) {
+		t.Errorf("terms: got %v, want %v", q.Terms, wantTerms)
+	}
+	wantPhrases := [][]string{{"web", "crawler"}, {"unclosed", "phrase"}}
+	if !reflect.DeepEqual(q.Phrases, wantPhrases) {
+		t.Errorf("phrases: got %v, want %v", q.Phrases, wantPhrases)
+	}
+
+	if !ParseQuery(` "" -- `).Empty() {
+		t.Error("expected a query without words to be empty")
+	}
+}
+
+func TestSnippet(t *testing.T) {
+	tests := []struct {
+		name, text, query, want string
+	}{
+		{"highlights terms", "Fast crawlers <3 fast pages", "fast", "<mark>Fast</mark> crawlers &lt;3 <mark>fast</mark> pages"},
+		{"no match", "Nothing to see here", "missing", "Nothing to see here"},
+		{"empty", "", "anything", ""},
+		{
+			"windows long text",
+			"one two three four five six seven eight nine ten eleven twelve thirteen fourteen fifteen sixteen seventeen eighteen nineteen twenty twentyone twentytwo twentythree twentyfour twentyfive twentysix twentyseven twentyeight twentynine thirty thirtyone thirtytwo thirtythree thirtyfour thirtyfive thirtysix thirtyseven thirtyeight thirtynine forty",
+			"thirty",
+			"… twenty twentyone twentytwo twentythree twentyfour twentyfive twentysix twentyseven twentyeight twentynine <mark>thirty</mark> thirtyone thirtytwo thirtythree thirtyfour thirtyfive thirtysix thirtyseven thirtyeight thirtynine forty",
+		},
+		{
+			"prefers phrase",
+			"web pages and crawler logs, then a web crawler",
+			`"web crawler"`,
+			"<mark>web</mark> pages and <mark>crawler</mark> logs, then a <mark>web</mark> <mark>crawler</mark>",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Snippet(tt.text, ParseQuery(tt.query)); got != tt.want {
+				t.Errorf("got  %q\nwant %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIndexMatch(t *testing.T) {
+	index := NewIndex()
+	index.Add(&models.Analysis{ID: "a", URL: "https://a.example.com", Title: "Gardening tips", Content: "How to grow tomatoes in a small garden"})
+	index.Add(&models.Analysis{ID: "b", URL: "https://b.example.com", Title: "Cooking", Content: "Tomatoes and basil make a small salad. Grow your own tomatoes"})
+	index.Add(&models.Analysis{ID: "c", URL: "https://c.example.org/tomatoes", Title: "Tomatoes", Content: "All about tomatoes"})
+
+	ids := func(q string) []string {
+		t.Helper()
+		matches, total, err := index.Match(context.Background(), ParseQuery(q), 0, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if int(total) != len(matches) {
+			t.Fatalf("total %d for %d matches", total, len(matches))
+		}
+		var ids []string
+		for _, m := range matches {
+			ids = append(ids, m.ID)
+		}
+		return ids
+	}
+
+	if got := ids("tomatoes"); !reflect.DeepEqual(got, []string{"c", "b", "a"}) {
+		t.Errorf("expected title and URL matches to rank first, got %v", got)
+	}
+	if got := ids("grow SMALL"); !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("expected analyses with every term, got %v", got)
+	}
+	if got := ids(`"small garden"`); !reflect.DeepEqual(got, []string{"a"}) {
+		t.Errorf("expected the phrase match only, got %v", got)
+	}
+	if got := ids(`"tomatoes all"`); got != nil {
+		t.Errorf("phrases shouldn't match across fields, got %v", got)
+	}
+	if got := ids("example org"); !reflect.DeepEqual(got, []string{"c"}) {
+		t.Errorf("expected URL words to match, got %v", got)
+	}
+
+	matches, total, _ := index.Match(context.Background(), ParseQuery("tomatoes"), 1, 1)
+	if total != 3 || len(matches) != 1 || matches[0].ID != "b" {
+		t.Errorf("unexpected page: %v of %d", matches, total)
+	}
+
+	index.Add(&models.Analysis{ID: "c", URL: "https://c.example.org", Title: "Peppers"})
+	index.Remove("a")
+	if got := ids("tomatoes"); !reflect.DeepEqual(got, []string{"b"}) {
+		t.Errorf("expected replaced and removed analyses to drop out, got %v", got)
+	}
+}
+
+func TestSearcher(t *testing.T) {
+	ctx := context.Background()
+	index := NewIndex()
+	analyses := NewIndexedRepository(repository.NewMemoryAnalysisRepository(), index)
+
+	a := &models.Analysis{URL: "https://example.com", Status: models.Queued}
+	if err := analyses.Create(ctx, a); err != nil {
+		t.Fatal(err)
+	}
+	a.MarkAsCompleted(&models.Analysis{Title: "Example", Content: "A page about search engines"})
+	if err := analyses.Save(ctx, a); err != nil {
+		t.Fatal(err)
+	}
+
+	hits, total, err := New(index, analyses).Search(ctx, ParseQuery("search"), 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 1 || len(hits) != 1 || hits[0].ID != a.ID || hits[0].Status != models.Completed {
+		t.Fatalf("unexpected hits: %+v", hits)
+	}
+	if hits[0].Snippet != "A page about <mark>search</mark> engines" {
+		t.Errorf("unexpected snippet %q", hits[0].Snippet)
+	}
+
+	if _, err := analyses.Delete(ctx, []string{a.ID}); err != nil {
+		t.Fatal(err)
+	}
+	if _, total, _ := New(index, analyses).Search(ctx, ParseQuery("search"), 0, 10); total != 0 {
+		t.Errorf("expected deleted analyses to be removed from the index")
+	}
+}
+
+func TestBooleanQuery(t *testing.T) {
+	got := booleanQuery(ParseQuery(`crawler "web page"`))
+	if want := `+crawler +web +page +"web page"`; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,219 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/saqibroy/web-crawler-dashboard/server/models"
+	"golang.org/x/net/html"
+)
+
+const (
+	maxIssueExamples = 5
+	// Each occurrence of an issue costs its severity weight, up to
+	// maxPenalisedCount occurrences per rule, so one noisy rule can't zero the
+	// score on its own.
+	maxPenalisedCount = 5
+)
+
+var severityWeights = map[string]int{
+	"error":   4,
+	"warning": 2,
+}
+
+type accessibilityCheck struct {
+	rule     string
+	severity string
+	message  string
+	find     func(doc *goquery.Document) []string
+}
+
+var accessibilityChecks = []accessibilityCheck{
+	{"image-alt", "error", "Images are missing alt text", findImagesWithoutAlt},
+	{"label", "error", "Form inputs have no associated label", findUnlabelledInputs},
+	{"html-lang", "error", "The <html> element has no lang attribute", findMissingLang},
+	{"heading-order", "warning", "Heading levels are skipped", findSkippedHeadings},
+	{"link-name", "error", "Links have no discernible text", findEmptyElements("a[href]")},
+	{"button-name", "error", "Buttons have no discernible text", findEmptyElements("button, input[type='button'], input[type='submit'], input[type='reset']")},
+	{"duplicate-id", "warning", "Element IDs are not unique", findDuplicateIDs},
+}
+
+// auditAccessibility runs basic WCAG checks against the document and returns
+// a 0-100 score together with the issues found.
+func auditAccessibility(doc *goquery.Document) (int, models.AccessibilityIssues) {
+	issues := models.AccessibilityIssues{}
+	score := 100
+
+	for _, check := range accessibilityChecks {
+		found := check.find(doc)
+		if len(found) == 0 {
+			continue
+		}
+
+		issue := models.AccessibilityIssue{
+			Rule:     check.rule,
+			Severity: check.severity,
+			Message:  check.message,
+			Count:    len(found),
+			Examples: found[:min(len(found), maxIssueExamples)],
+		}
+		issues = append(issues, issue)
+		score -= severityWeights[check.severity] * min(issue.Count, maxPenalisedCount)
+	}
+
+	return max(score, 0), issues
+}
+
+func findImagesWithoutAlt(doc *goquery.Document) []string {
+	var found []string
+	doc.Find("img:not([alt])").Each(func(_ int, s *goquery.Selection) {
+		found = append(found, selectorPath(s))
+	})
+	return found
+}
+
+func findUnlabelledInputs(doc *goquery.Document) []string {
+	labelled := make(map[string]bool)
+	doc.Find("label[for]").Each(func(_ int, s *goquery.Selection) {
+		labelled[s.AttrOr("for", "")] = true
+	})
+
+	var found []string
+	doc.Find("input, select, textarea").Each(func(_ int, s *goquery.Selection) {
+		switch strings.ToLower(s.AttrOr("type", "")) {
+		case "hidden", "submit", "button", "reset", "image":
+			return
+		}
+		if hasAccessibleNameAttr(s) || s.Closest("label").Length() > 0 {
+			return
+		}
+		if id, ok := s.Attr("id"); ok && labelled[id] {
+			return
+		}
+		found = append(found, selectorPath(s))
+	})
+	return found
+}
+
+func findMissingLang(doc *goquery.Document) []string {
+	if strings.TrimSpace(doc.Find("html").AttrOr("lang", "")) == "" {
+		return []string{"html"}
+	}
+	return nil
+}
+
+func findSkippedHeadings(doc *goquery.Document) []string {
+	var found []string
+	previous := 0
+	doc.Find("h1, h2, h3, h4, h5, h6").Each(func(_ int, s *goquery.Selection) {
+		level := int(goquery.NodeName(s)[1] - '0')
+		if previous > 0 && level > previous+1 {
+			found = append(found, fmt.Sprintf("h%d followed by h%d (%s)", previous, level, selectorPath(s)))
+		}
+		previous = level
+	})
+	return found
+}
+
+func findEmptyElements(selector string) func(doc *goquery.Document) []string {
+	return func(doc *goquery.Document) []string {
+		var found []string
+		doc.Find(selector).Each(func(_ int, s *goquery.Selection) {
+			if hasAccessibleNameAttr(s) || strings.TrimSpace(s.Text()) != "" {
+				return
+			}
+			if goquery.NodeName(s) == "input" && strings.TrimSpace(s.AttrOr("value", "")) != "" {
+				return
+			}
+			altText := false
+			s.Find("img[alt]").Each(func(_ int, img *goquery.Selection) {
+				altText = altText || strings.TrimSpace(img.AttrOr("alt", "")) != ""
+			})
+			if !altText {
+				found = append(found, selectorPath(s))
+			}
+		})
+		return found
+	}
+}
+
+func findDuplicateIDs(doc *goquery.Document) []string {
+	counts := make(map[string]int)
+	var order []string
+	doc.Find("[id]").Each(func(_ int, s *goquery.Selection) {
+		id := s.AttrOr("id", "")
+		if id == "" {
+			return
+		}
+		if counts[id] == 0 {
+			order = append(order, id)
+		}
+		counts[id]++
+	})
+
+	var found []string
+	for _, id := range order {
+		if counts[id] > 1 {
+			found = append(found, fmt.Sprintf("#%s (%d elements)", id, counts[id]))
+		}
+	}
+	return found
+}
+
+func hasAccessibleNameAttr(s *goquery.Selection) bool {
+	for _, attr := range []string{"aria-label", "aria-labelledby", "title"} {
+		if strings.TrimSpace(s.AttrOr(attr, "")) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// selectorPath builds a CSS selector locating the element, anchored at the
+// nearest ancestor with an id or at <body>.
+func selectorPath(s *goquery.Selection) string {
+	var parts []string
+	for node := s.Get(0); node != nil && node.Type == html.ElementNode; node = node.Parent {
+		name := node.Data
+		if name == "html" || name == "body" {
+			break
+		}
+
+		if id := nodeAttr(node, "id"); id != "" && !strings.ContainsAny(id, " \t") {
+			parts = append(parts, name+"#"+id)
+			break
+		}
+
+		index, total := 0, 0
+		for sib := node.Parent.FirstChild; sib != nil; sib = sib.NextSibling {
+			if sib.Type == html.ElementNode && sib.Data == name {
+				total++
+				if sib == node {
+					index = total
+				}
+			}
+		}
+		if total > 1 {
+			name = fmt.Sprintf("%s:nth-of-type(%d)", name, index)
+		}
+		parts = append(parts, name)
+	}
+
+	for i, j := 0, len(parts)-1; i < j; i, j = i+1, j-1 {
+		parts[i], parts[j] = parts[j], parts[i]
+	}
+	if len(parts) == 0 {
+		return goquery.NodeName(s)
+	}
+	return strings.Join(parts, " > ")
+}
+
+func nodeAttr(node *html.Node, key string) string {
+	for _, attr := range node.Attr {
+		if attr.Key == key {
+			return attr.Val
+		}
+	}
+	return ""
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sync"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/saqibroy/web-crawler-dashboard/server/models"
+)
+
+// AnalyzerInput is the fetched page handed to every analyzer.
+type AnalyzerInput struct {
+	URL  *url.URL // URL the analysis was submitted with
+	Page *Page
+	Doc  *goquery.Document
+	// Request is the analysis's request configuration, nil for the defaults.
+	// Analyzers making their own requests should build clients from it.
+	Request *RequestConfig
+}
+
+// Analyzer is a single page check. Its result must be JSON-serialisable; it
+// is stored in the analyzer_results table under the analyzer's name.
+type Analyzer interface {
+	Name() string
+	Run(ctx context.Context, in *AnalyzerInput) (interface{}, error)
+}
+
+// columnWriter is implemented by the built-in analyzers whose results are
+// also copied into dedicated Analysis columns for existing API clients.
+type columnWriter interface {
+	apply(analysis *models.Analysis, result interface{})
+}
+
+var (
+	registryMu    sync.RWMutex
+	analyzers     = make(map[string]Analyzer)
+	analyzerOrder []string
+)
+
+// RegisterAnalyzer makes an analyzer available to crawls. Analyzers run in
+// registration order. It panics if the name is already registered.
+func RegisterAnalyzer(a Analyzer) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	name := a.Name()
+	if _, exists := analyzers[name]; exists {
+		panic("services: analyzer registered twice: " + name)
+	}
+	analyzers[name] = a
+	analyzerOrder = append(analyzerOrder, name)
+}
+
+// AnalyzerNames lists the registered analyzers in the order they run.
+func AnalyzerNames() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return append([]string(nil), analyzerOrder...)
+}
+
+// ValidateAnalyzers checks that every requested analyzer is registered.
+func ValidateAnalyzers(names []string) error {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	for _, name := range names {
+		if _, ok := analyzers[name]; !ok {
+			return fmt.Errorf("unknown analyzer %q", name)
+		}
+	}
+	return nil
+}
+
+// runAnalyzers runs the selected analyzers (all of them when none are
+// selected) in registration order. A failing analyzer records its error and
+// doesn't stop the others; only cancellation aborts the run.
+func runAnalyzers(ctx context.Context, in *AnalyzerInput, selected []string, analysis *models.Analysis) error {
+	want := make(map[string]bool, len(selected))
+	for _, name := range selected {
+		want[name] = true
+	}
+
+	for _, name := range AnalyzerNames() {
+		if len(want) > 0 && !want[name] {
+			continue
+		}
+
+		registryMu.RLock()
+		a := analyzers[name]
+		registryMu.RUnlock()
+
+		record := models.AnalyzerResult{Analyzer: name}
+		result, err := a.Run(ctx, in)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if err != nil {
+			record.Error = err.Error()
+		} else if raw, err := json.Marshal(result); err != nil {
+			record.Error = fmt.Sprintf("failed to encode result: %v", err)
+		} else {
+			record.Result = raw
+			if w, ok := a.(columnWriter); ok {
+				w.apply(analysis, result)
+			}
+		}
+
+		analysis.Results = append(analysis.Results, record)
+	}
+
+	return nil
+}
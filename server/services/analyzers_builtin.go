@@ -0,0 +1,172 @@
+package services
+
+import (
+	"context"
+
+	"github.com/saqibroy/web-crawler-dashboard/server/models"
+)
+
+// builtinAnalyzer adapts the crawler's original checks to the Analyzer
+// interface, copying their results into the matching Analysis columns.
+type builtinAnalyzer struct {
+	name  string
+	run   func(ctx context.Context, in *AnalyzerInput) (interface{}, error)
+	write func(a *models.Analysis, result interface{})
+}
+
+func (b *builtinAnalyzer) Name() string { return b.name }
+
+func (b *builtinAnalyzer) Run(ctx context.Context, in *AnalyzerInput) (interface{}, error) {
+	return b.run(ctx, in)
+}
+
+func (b *builtinAnalyzer) apply(a *models.Analysis, result interface{}) {
+	b.write(a, result)
+}
+
+type HTMLVersionResult struct {
+	Version string `json:"version"`
+	Doctype string `json:"doctype"`
+}
+
+type AuthFormsResult struct {
+	HasLoginForm bool             `json:"has_login_form"`
+	Forms        models.AuthForms `json:"forms"`
+}
+
+type LinksResult struct {
+	Internal int            `json:"internal"`
+	External int            `json:"external"`
+	Broken   models.JSONMap `json:"broken"`
+}
+
+type AccessibilityResult struct {
+	Score  int                        `json:"score"`
+	Issues models.AccessibilityIssues `json:"issues"`
+}
+
+func init() {
+	RegisterAnalyzer(&builtinAnalyzer{
+		name: "html_version",
+		run: func(_ context.Context, in *AnalyzerInput) (interface{}, error) {
+			version, doctype := detectHTMLVersion(in.Page.Body)
+			return &HTMLVersionResult{Version: version, Doctype: doctype}, nil
+		},
+		write: func(a *models.Analysis, result interface{}) {
+			r := result.(*HTMLVersionResult)
+			a.HTMLVersion, a.Doctype = r.Version, r.Doctype
+		},
+	})
+
+	RegisterAnalyzer(&builtinAnalyzer{
+		name: "headings",
+		run: func(_ context.Context, in *AnalyzerInput) (interface{}, error) {
+			return countHeadings(in.Doc), nil
+		},
+		write: func(a *models.Analysis, result interface{}) {
+			a.Headings = result.(map[string]interface{})
+		},
+	})
+
+	RegisterAnalyzer(&builtinAnalyzer{
+		name: "login_form",
+		run: func(_ context.Context, in *AnalyzerInput) (interface{}, error) {
+			forms := detectAuthForms(in.Doc)
+			return &AuthFormsResult{HasLoginForm: hasLoginForm(in.Doc, forms), Forms: forms}, nil
+		},
+		write: func(a *models.Analysis, result interface{}) {
+			r := result.(*AuthFormsResult)
+			a.HasLoginForm, a.AuthForms = r.HasLoginForm, r.Forms
+		},
+	})
+
+	RegisterAnalyzer(&builtinAnalyzer{
+		name: "seo",
+		run: func(_ context.Context, in *AnalyzerInput) (interface{}, error) {
+			return extractSEO(in.Doc, in.Page), nil
+		},
+		write: func(a *models.Analysis, result interface{}) {
+			r := result.(*SEOResult)
+			a.MetaDescription = r.MetaDescription
+			a.CanonicalURL = r.CanonicalURL
+			a.MetaRobots = r.MetaRobots
+			a.XRobotsTag = r.XRobotsTag
+			a.OpenGraph = r.OpenGraph
+			a.TwitterCard = r.TwitterCard
+			a.Hreflang = r.Hreflang
+			a.Viewport = r.Viewport
+			a.Charset = r.Charset
+			a.SEOWarnings = r.Warnings
+		},
+	})
+
+	RegisterAnalyzer(&builtinAnalyzer{
+		name: "accessibility",
+		run: func(_ context.Context, in *AnalyzerInput) (interface{}, error) {
+			score, issues := auditAccessibility(in.Doc)
+			return &AccessibilityResult{Score: score, Issues: issues}, nil
+		},
+		write: func(a *models.Analysis, result interface{}) {
+			r := result.(*AccessibilityResult)
+			a.AccessibilityScore, a.AccessibilityIssues = r.Score, r.Issues
+		},
+	})
+
+	RegisterAnalyzer(&builtinAnalyzer{
+		name: "redirects",
+		run: func(_ context.Context, in *AnalyzerInput) (interface{}, error) {
+			chain := in.Page.Redirects
+			if chain == nil {
+				chain = models.RedirectChain{}
+			}
+			return &RedirectsResult{Chain: chain, Warnings: redirectWarnings(chain)}, nil
+		},
+		write: func(a *models.Analysis, result interface{}) {
+			r := result.(*RedirectsResult)
+			a.Redirects, a.RedirectWarnings = r.Chain, r.Warnings
+		},
+	})
+
+	RegisterAnalyzer(&builtinAnalyzer{
+		name: "performance",
+		run: func(ctx context.Context, in *AnalyzerInput) (interface{}, error) {
+			return measurePerformance(ctx, in), nil
+		},
+		write: func(a *models.Analysis, result interface{}) {
+			a.Performance = *result.(*models.PerformanceMetrics)
+		},
+	})
+
+	RegisterAnalyzer(&builtinAnalyzer{
+		name: "security",
+		run: func(ctx context.Context, in *AnalyzerInput) (interface{}, error) {
+			return inspectSecurity(ctx, in), nil
+		},
+		write: func(a *models.Analysis, result interface{}) {
+			r := result.(*SecurityResult)
+			a.SecurityGrade, a.Security = r.Grade, r.Report
+		},
+	})
+
+	RegisterAnalyzer(&builtinAnalyzer{
+		name: "links",
+		run: func(ctx context.Context, in *AnalyzerInput) (interface{}, error) {
+			internal, external, broken := processLinks(ctx, in.Doc, in.URL, newLinkClient(in.Request))
+			return &LinksResult{Internal: internal, External: external, Broken: broken}, nil
+		},
+		write: func(a *models.Analysis, result interface{}) {
+			r := result.(*LinksResult)
+			a.InternalLinks, a.ExternalLinks, a.BrokenLinks = r.Internal, r.External, r.Broken
+		},
+	})
+
+	RegisterAnalyzer(&builtinAnalyzer{
+		name: "sitemap",
+		run: func(ctx context.Context, in *AnalyzerInput) (interface{}, error) {
+			return analyzeSitemap(ctx, in.URL, collectInternalLinks(in.Doc, in.URL), in.Request), nil
+		},
+		write: func(a *models.Analysis, result interface{}) {
+			a.Sitemap = result.(map[string]interface{})
+		},
+	})
+}
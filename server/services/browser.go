@@ -0,0 +1,628 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/saqibroy/web-crawler-dashboard/server/models"
+	"golang.org/x/net/websocket"
+)
+
+const (
+	browserTimeout = 30 * time.Second
+	// After the load event, give client-side frameworks a moment to render
+	browserSettleDelay = 500 * time.Millisecond
+)
+
+var browserCandidates = []string{"chromium", "chromium-browser", "google-chrome", "google-chrome-stable", "headless_shell"}
+
+// serializeDocument returns the post-JavaScript DOM, including its doctype so
+// detectHTMLVersion sees the same document the browser parsed.
+const serializeDocument = `(document.doctype ? new XMLSerializer().serializeToString(document.doctype) : "") + document.documentElement.outerHTML`
+
+// navigationTiming reads the main document's Navigation Timing entry.
+const navigationTiming = `JSON.stringify(performance.getEntriesByType("navigation")[0] || null)`
+
+// BrowserFetcher renders pages in a locally installed headless Chromium,
+// driven over the DevTools protocol, so single-page apps are analysed after
+// their scripts have run. The binary is taken from CHROME_PATH or looked up
+// on PATH.
+//
+// A RequestConfig's proxy, User-Agent, cookies, headers, basic auth and
+// timeout are applied as for HTTP fetches: requests to the analysed host are
+// intercepted to add the headers and answer its auth challenges, so neither
+// reaches the other sites the page loads from. Proxy credentials aren't
+// supported.
+type BrowserFetcher struct {
+	BinaryPath string
+	Request    *RequestConfig
+}
+
+func NewBrowserFetcher() *BrowserFetcher {
+	return &BrowserFetcher{BinaryPath: os.Getenv("CHROME_PATH")}
+}
+
+func (f *BrowserFetcher) binary() (string, error) {
+	if f.BinaryPath != "" {
+		return f.BinaryPath, nil
+	}
+	for _, name := range browserCandidates {
+		if path, err := exec.LookPath(name); err == nil {
+			return path, nil
+		}
+	}
+	return "", errors.New("no headless Chromium found; set CHROME_PATH")
+}
+
+func (f *BrowserFetcher) Fetch(ctx context.Context, targetURL string) (*Page, error) {
+	binary, err := f.binary()
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := browserTimeout
+	if f.Request != nil && f.Request.Timeout > 0 {
+		timeout = f.Request.Timeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	profile, err := os.MkdirTemp("", "crawler-chromium-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(profile)
+
+	args := []string{
+		"--headless=new",
+		"--disable-gpu",
+		"--no-sandbox",
+		"--no-first-run",
+		"--no-default-browser-check",
+		"--remote-debugging-port=0",
+		"--remote-allow-origins=*",
+		"--user-data-dir=" + profile,
+	}
+	if f.Request != nil && f.Request.Proxy != "" {
+		proxy, err := url.Parse(f.Request.Proxy)
+		if err != nil {
+			return nil, err
+		}
+		if proxy.User != nil {
+			return nil, errors.New("proxy credentials aren't supported in browser mode")
+		}
+		args = append(args, "--proxy-server="+proxy.String())
+	}
+
+	cmd := exec.CommandContext(ctx, binary, append(args, "about:blank")...)
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start browser: %w", err)
+	}
+	defer func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	}()
+
+	wsURL, err := devToolsURL(ctx, stderr)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := dialCDP(wsURL)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.close()
+
+	return conn.render(ctx, targetURL, f.Request)
+}
+
+// devToolsURL reads the browser's WebSocket endpoint from its startup output.
+func devToolsURL(ctx context.Context, stderr io.Reader) (string, error) {
+	found := make(chan string, 1)
+	go func() {
+		scanner := bufio.NewScanner(stderr)
+		for scanner.Scan() {
+			if _, after, ok := strings.Cut(scanner.Text(), "DevTools listening on "); ok {
+				found <- strings.TrimSpace(after)
+				break
+			}
+		}
+		// Keep draining so the browser never blocks on a full pipe
+		for scanner.Scan() {
+		}
+		close(found)
+	}()
+
+	select {
+	case u, ok := <-found:
+		if !ok {
+			return "", errors.New("browser exited before DevTools became available")
+		}
+		return u, nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+type cdpMessage struct {
+	ID        int             `json:"id,omitempty"`
+	SessionID string          `json:"sessionId,omitempty"`
+	Method    string          `json:"method,omitempty"`
+	Params    json.RawMessage `json:"params,omitempty"`
+	Result    json.RawMessage `json:"result,omitempty"`
+	Error     *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+type cdpConn struct {
+	ws       *websocket.Conn
+	mu       sync.Mutex
+	nextID   int
+	pending  map[int]chan cdpMessage
+	handlers map[string]func(cdpMessage)
+	// events queues the sessions' events for waitForLoad, however many
+	// arrive before it gets to them, and ready signals that it grew.
+	events []cdpMessage
+	ready  chan struct{}
+	done   chan struct{}
+}
+
+func dialCDP(wsURL string) (*cdpConn, error) {
+	ws, err := websocket.Dial(wsURL, "", "http://localhost/")
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to DevTools: %w", err)
+	}
+	ws.MaxPayloadBytes = maxPageSize * 2
+
+	c := &cdpConn{
+		ws:       ws,
+		pending:  make(map[int]chan cdpMessage),
+		handlers: make(map[string]func(cdpMessage)),
+		ready:    make(chan struct{}, 1),
+		done:     make(chan struct{}),
+	}
+	go c.readLoop()
+	return c, nil
+}
+
+func (c *cdpConn) readLoop() {
+	defer close(c.done)
+	for {
+		var msg cdpMessage
+		if err := websocket.JSON.Receive(c.ws, &msg); err != nil {
+			return
+		}
+
+		if msg.ID == 0 {
+			c.mu.Lock()
+			handler := c.handlers[msg.Method]
+			// Browser-wide events aren't about the page
+			if handler == nil && msg.SessionID != "" {
+				c.events = append(c.events, msg)
+			}
+			c.mu.Unlock()
+			if handler != nil {
+				// Handlers may call back into the browser, which needs this
+				// loop to read the response
+				go handler(msg)
+				continue
+			}
+			select {
+			case c.ready <- struct{}{}:
+			default: // Already signalled
+			}
+			continue
+		}
+
+		c.mu.Lock()
+		ch := c.pending[msg.ID]
+		delete(c.pending, msg.ID)
+		c.mu.Unlock()
+		if ch != nil {
+			ch <- msg
+		}
+	}
+}
+
+// nextEvent returns the oldest queued event, waiting for one if there are
+// none.
+func (c *cdpConn) nextEvent(ctx context.Context) (cdpMessage, error) {
+	for {
+		c.mu.Lock()
+		if len(c.events) > 0 {
+			msg := c.events[0]
+			c.events = c.events[1:]
+			c.mu.Unlock()
+			return msg, nil
+		}
+		c.mu.Unlock()
+
+		select {
+		case <-c.ready:
+		case <-c.done:
+			return cdpMessage{}, errors.New("browser connection closed")
+		case <-ctx.Done():
+			return cdpMessage{}, ctx.Err()
+		}
+	}
+}
+
+// onEvent has fn handle events of one method as they arrive, instead of
+// queueing them for waitForLoad.
+func (c *cdpConn) onEvent(method string, fn func(cdpMessage)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.handlers[method] = fn
+}
+
+func (c *cdpConn) call(ctx context.Context, sessionID, method string, params interface{}, result interface{}) error {
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+
+	ch := make(chan cdpMessage, 1)
+	c.mu.Lock()
+	c.nextID++
+	id := c.nextID
+	c.pending[id] = ch
+	c.mu.Unlock()
+
+	msg := cdpMessage{ID: id, SessionID: sessionID, Method: method, Params: raw}
+	if err := websocket.JSON.Send(c.ws, msg); err != nil {
+		return err
+	}
+
+	select {
+	case resp := <-ch:
+		if resp.Error != nil {
+			return fmt.Errorf("%s: %s", method, resp.Error.Message)
+		}
+		if result != nil {
+			return json.Unmarshal(resp.Result, result)
+		}
+		return nil
+	case <-c.done:
+		return errors.New("browser connection closed")
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (c *cdpConn) close() {
+	c.ws.Close()
+}
+
+func (c *cdpConn) render(ctx context.Context, targetURL string, request *RequestConfig) (*Page, error) {
+	var target struct {
+		TargetID string `json:"targetId"`
+	}
+	if err := c.call(ctx, "", "Target.createTarget", map[string]string{"url": "about:blank"}, &target); err != nil {
+		return nil, err
+	}
+
+	var attached struct {
+		SessionID string `json:"sessionId"`
+	}
+	if err := c.call(ctx, "", "Target.attachToTarget", map[string]interface{}{"targetId": target.TargetID, "flatten": true}, &attached); err != nil {
+		return nil, err
+	}
+	session := attached.SessionID
+
+	for _, domain := range []string{"Page.enable", "Network.enable"} {
+		if err := c.call(ctx, session, domain, struct{}{}, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := c.applyRequestConfig(ctx, session, targetURL, request); err != nil {
+		return nil, err
+	}
+
+	var nav struct {
+		FrameID   string `json:"frameId"`
+		LoaderID  string `json:"loaderId"`
+		ErrorText string `json:"errorText"`
+	}
+	if err := c.call(ctx, session, "Page.navigate", map[string]string{"url": targetURL}, &nav); err != nil {
+		return nil, err
+	}
+	if nav.ErrorText != "" {
+		return nil, fmt.Errorf("navigation failed: %s", nav.ErrorText)
+	}
+
+	page := &Page{Header: http.Header{}}
+	if err := c.waitForLoad(ctx, session, nav.LoaderID, page); err != nil {
+		return nil, err
+	}
+
+	select {
+	case <-time.After(browserSettleDelay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	var eval struct {
+		Result struct {
+			Value string `json:"value"`
+		} `json:"result"`
+		ExceptionDetails *struct {
+			Text string `json:"text"`
+		} `json:"exceptionDetails"`
+	}
+	if err := c.call(ctx, session, "Runtime.evaluate", map[string]interface{}{"expression": serializeDocument, "returnByValue": true}, &eval); err != nil {
+		return nil, err
+	}
+	if eval.ExceptionDetails != nil {
+		return nil, fmt.Errorf("failed to serialize DOM: %s", eval.ExceptionDetails.Text)
+	}
+	page.Body = []byte(eval.Result.Value)
+
+	var location struct {
+		Result struct {
+			Value string `json:"value"`
+		} `json:"result"`
+	}
+	if err := c.call(ctx, session, "Runtime.evaluate", map[string]interface{}{"expression": "location.href", "returnByValue": true}, &location); err == nil {
+		if u, err := url.Parse(location.Result.Value); err == nil {
+			page.URL = u
+		}
+	}
+	if page.URL == nil {
+		page.URL, _ = url.Parse(targetURL)
+	}
+
+	page.Timing = c.timing(ctx, session)
+
+	return page, nil
+}
+
+// applyRequestConfig sets the configured User-Agent, headers and cookies,
+// including any login session, on the tab before it navigates.
+func (c *cdpConn) applyRequestConfig(ctx context.Context, session, targetURL string, request *RequestConfig) error {
+	if request == nil {
+		return nil
+	}
+
+	if request.UserAgent != "" {
+		if err := c.call(ctx, session, "Network.setUserAgentOverride", map[string]string{"userAgent": request.UserAgent}, nil); err != nil {
+			return err
+		}
+	}
+
+	if u, err := url.Parse(targetURL); err == nil {
+		if err := c.interceptHost(ctx, session, u.Hostname(), request); err != nil {
+			return err
+		}
+	}
+
+	cookies := make([]map[string]string, 0, len(request.Cookies))
+	for name, value := range request.Cookies {
+		cookies = append(cookies, map[string]string{"name": name, "value": value, "url": targetURL})
+	}
+	// Carry over the session from a login recipe
+	if u, err := url.Parse(targetURL); err == nil && request.jar != nil {
+		for _, cookie := range request.jar.Cookies(u) {
+			cookies = append(cookies, map[string]string{"name": cookie.Name, "value": cookie.Value, "url": targetURL})
+		}
+	}
+	if len(cookies) > 0 {
+		if err := c.call(ctx, session, "Network.setCookies", map[string]interface{}{"cookies": cookies}, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// interceptHost pauses the tab's requests to host, adding the configured
+// headers before they continue and answering the host's basic auth
+// challenges. Requests to other hosts aren't touched, so they get neither.
+func (c *cdpConn) interceptHost(ctx context.Context, session, host string, request *RequestConfig) error {
+	basicAuth := request.Username != "" || request.Password != ""
+	if len(request.Headers) == 0 && !basicAuth {
+		return nil
+	}
+	onHost := func(rawURL string) bool {
+		u, err := url.Parse(rawURL)
+		return err == nil && strings.EqualFold(u.Hostname(), host)
+	}
+
+	c.onEvent("Fetch.requestPaused", func(msg cdpMessage) {
+		var ev struct {
+			RequestID string `json:"requestId"`
+			Request   struct {
+				URL     string            `json:"url"`
+				Headers map[string]string `json:"headers"`
+			} `json:"request"`
+		}
+		if msg.SessionID != session || json.Unmarshal(msg.Params, &ev) != nil {
+			return
+		}
+		params := map[string]interface{}{"requestId": ev.RequestID}
+		if len(request.Headers) > 0 && onHost(ev.Request.URL) {
+			// The headers given replace the request's own
+			headers := make([]map[string]string, 0, len(ev.Request.Headers)+len(request.Headers))
+			for name, value := range ev.Request.Headers {
+				if headerValue(request.Headers, name) == "" {
+					headers = append(headers, map[string]string{"name": name, "value": value})
+				}
+			}
+			for name, value := range request.Headers {
+				headers = append(headers, map[string]string{"name": name, "value": value})
+			}
+			params["headers"] = headers
+		}
+		c.call(ctx, session, "Fetch.continueRequest", params, nil)
+	})
+
+	// Credentials are offered once per request, so wrong ones end in the
+	// site's 401 rather than a loop
+	var answered sync.Map
+	c.onEvent("Fetch.authRequired", func(msg cdpMessage) {
+		var ev struct {
+			RequestID string `json:"requestId"`
+			Request   struct {
+				URL string `json:"url"`
+			} `json:"request"`
+			AuthChallenge struct {
+				Source string `json:"source"`
+			} `json:"authChallenge"`
+		}
+		if msg.SessionID != session || json.Unmarshal(msg.Params, &ev) != nil {
+			return
+		}
+		response := map[string]string{"response": "CancelAuth"}
+		if _, seen := answered.LoadOrStore(ev.RequestID, true); !seen && basicAuth &&
+			ev.AuthChallenge.Source != "Proxy" && onHost(ev.Request.URL) {
+			response = map[string]string{"response": "ProvideCredentials", "username": request.Username, "password": request.Password}
+		}
+		c.call(ctx, session, "Fetch.continueWithAuth", map[string]interface{}{"requestId": ev.RequestID, "authChallengeResponse": response}, nil)
+	})
+
+	patterns := []map[string]string{{"urlPattern": "*://" + host + "/*"}, {"urlPattern": "*://" + host + ":*"}}
+	return c.call(ctx, session, "Fetch.enable", map[string]interface{}{"patterns": patterns, "handleAuthRequests": basicAuth}, nil)
+}
+
+// timing converts the browser's Navigation Timing entry into a Timing. It
+// returns nil when the entry isn't available.
+func (c *cdpConn) timing(ctx context.Context, session string) *Timing {
+	var eval struct {
+		Result struct {
+			Value string `json:"value"`
+		} `json:"result"`
+	}
+	if err := c.call(ctx, session, "Runtime.evaluate", map[string]interface{}{"expression": navigationTiming, "returnByValue": true}, &eval); err != nil {
+		return nil
+	}
+
+	var nav struct {
+		DomainLookupStart     float64 `json:"domainLookupStart"`
+		DomainLookupEnd       float64 `json:"domainLookupEnd"`
+		ConnectStart          float64 `json:"connectStart"`
+		ConnectEnd            float64 `json:"connectEnd"`
+		SecureConnectionStart float64 `json:"secureConnectionStart"`
+		ResponseStart         float64 `json:"responseStart"`
+		ResponseEnd           float64 `json:"responseEnd"`
+		EncodedBodySize       int64   `json:"encodedBodySize"`
+		DecodedBodySize       int64   `json:"decodedBodySize"`
+	}
+	if json.Unmarshal([]byte(eval.Result.Value), &nav) != nil || nav.ResponseEnd == 0 {
+		return nil
+	}
+
+	ms := func(d float64) time.Duration { return time.Duration(d * float64(time.Millisecond)) }
+	t := &Timing{
+		DNSLookup:    ms(nav.DomainLookupEnd - nav.DomainLookupStart),
+		Connect:      ms(nav.ConnectEnd - nav.ConnectStart),
+		TTFB:         ms(nav.ResponseStart),
+		Download:     ms(nav.ResponseEnd - nav.ResponseStart),
+		Total:        ms(nav.ResponseEnd),
+		TransferSize: nav.EncodedBodySize,
+		BodySize:     nav.DecodedBodySize,
+	}
+	if nav.SecureConnectionStart > 0 {
+		t.TLSHandshake = ms(nav.ConnectEnd - nav.SecureConnectionStart)
+	}
+	return t
+}
+
+// waitForLoad records the main document's response and any redirects that
+// led to it, and returns once the page's load event has fired.
+func (c *cdpConn) waitForLoad(ctx context.Context, session, loaderID string, page *Page) error {
+	// DevTools timestamps are in seconds; each hop is timed from its request
+	var hopStart float64
+	for {
+		msg, err := c.nextEvent(ctx)
+		if err != nil {
+			return err
+		}
+		if msg.SessionID != session {
+			continue
+		}
+		switch msg.Method {
+		case "Network.requestWillBeSent":
+			var ev struct {
+				LoaderID         string  `json:"loaderId"`
+				Type             string  `json:"type"`
+				Timestamp        float64 `json:"timestamp"`
+				RedirectResponse *struct {
+					URL     string            `json:"url"`
+					Status  int               `json:"status"`
+					Headers map[string]string `json:"headers"`
+				} `json:"redirectResponse"`
+			}
+			if json.Unmarshal(msg.Params, &ev) != nil || ev.Type != "Document" || ev.LoaderID != loaderID {
+				continue
+			}
+			if ev.RedirectResponse != nil {
+				page.Redirects = append(page.Redirects, models.RedirectHop{
+					URL:        ev.RedirectResponse.URL,
+					StatusCode: ev.RedirectResponse.Status,
+					Location:   headerValue(ev.RedirectResponse.Headers, "Location"),
+					DurationMs: int64((ev.Timestamp - hopStart) * 1000),
+				})
+			}
+			hopStart = ev.Timestamp
+		case "Network.responseReceived":
+			var ev struct {
+				LoaderID  string  `json:"loaderId"`
+				Type      string  `json:"type"`
+				Timestamp float64 `json:"timestamp"`
+				Response  struct {
+					URL     string            `json:"url"`
+					Status  int               `json:"status"`
+					Headers map[string]string `json:"headers"`
+				} `json:"response"`
+			}
+			if json.Unmarshal(msg.Params, &ev) != nil || ev.Type != "Document" || ev.LoaderID != loaderID {
+				continue
+			}
+			page.StatusCode = ev.Response.Status
+			page.Redirects = append(page.Redirects, models.RedirectHop{
+				URL:        ev.Response.URL,
+				StatusCode: ev.Response.Status,
+				DurationMs: int64((ev.Timestamp - hopStart) * 1000),
+			})
+			for k, v := range ev.Response.Headers {
+				// DevTools joins repeated headers with newlines
+				for _, value := range strings.Split(v, "\n") {
+					page.Header.Add(k, value)
+				}
+			}
+		case "Page.loadEventFired":
+			if page.StatusCode == 0 {
+				// Served from cache or a non-network scheme
+				page.StatusCode = 200
+			}
+			return nil
+		}
+	}
+}
+
+// headerValue looks up a header in a DevTools header map, whose keys keep the
+// server's casing.
+func headerValue(headers map[string]string, name string) string {
+	for k, v := range headers {
+		if strings.EqualFold(k, name) {
+			return v
+		}
+	}
+	return ""
+}
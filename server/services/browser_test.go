@@ -0,0 +1,134 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+// fakeBrowser is a DevTools endpoint that acknowledges every command,
+// passes the commands it receives to the test, and sends the events it's
+// given once the trigger command arrives.
+func fakeBrowser(t *testing.T, trigger string, events []cdpMessage) (*cdpConn, <-chan cdpMessage) {
+	t.Helper()
+	commands := make(chan cdpMessage, 100)
+	server := httptest.NewServer(websocket.Handler(func(ws *websocket.Conn) {
+		for {
+			var msg cdpMessage
+			if err := websocket.JSON.Receive(ws, &msg); err != nil {
+				return
+			}
+			websocket.JSON.Send(ws, cdpMessage{ID: msg.ID, SessionID: msg.SessionID, Result: json.RawMessage("{}")})
+			commands <- msg
+			if msg.Method == trigger {
+				for _, ev := range events {
+					websocket.JSON.Send(ws, ev)
+				}
+			}
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := dialCDP("ws" + strings.TrimPrefix(server.URL, "http"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(c.close)
+	return c, commands
+}
+
+func event(method, params string) cdpMessage {
+	return cdpMessage{SessionID: "S", Method: method, Params: json.RawMessage(params)}
+}
+
+func TestInterceptHostScopesCredentials(t *testing.T) {
+	c, commands := fakeBrowser(t, "Fetch.enable", []cdpMessage{
+		event("Fetch.requestPaused", `{"requestId":"1","request":{"url":"https://example.com/","headers":{"Accept":"*/*","x-api-key":"old"}}}`),
+		event("Fetch.requestPaused", `{"requestId":"2","request":{"url":"https://example.com.evil.net/","headers":{}}}`),
+		event("Fetch.authRequired", `{"requestId":"1","request":{"url":"https://example.com/"},"authChallenge":{"source":"Server"}}`),
+		event("Fetch.authRequired", `{"requestId":"3","request":{"url":"https://cdn.example.net/"},"authChallenge":{"source":"Server"}}`),
+		event("Fetch.authRequired", `{"requestId":"4","request":{"url":"https://example.com/"},"authChallenge":{"source":"Proxy"}}`),
+	})
+	request := &RequestConfig{Headers: map[string]string{"X-Api-Key": "secret"}, Username: "user", Password: "pass"}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := c.interceptHost(ctx, "S", "example.com", request); err != nil {
+		t.Fatal(err)
+	}
+
+	type command struct {
+		RequestID string              `json:"requestId"`
+		Headers   []map[string]string `json:"headers"`
+		Response  map[string]string   `json:"authChallengeResponse"`
+	}
+	got := make(map[string]command)
+	for len(got) < 5 {
+		select {
+		case msg := <-commands:
+			if msg.Method == "Fetch.enable" {
+				if !strings.Contains(string(msg.Params), `"handleAuthRequests":true`) {
+					t.Errorf("expected auth requests to be handled: %s", msg.Params)
+				}
+				continue
+			}
+			var cmd command
+			json.Unmarshal(msg.Params, &cmd)
+			got[msg.Method+" "+cmd.RequestID] = cmd
+		case <-ctx.Done():
+			t.Fatalf("timed out with %v", got)
+		}
+	}
+
+	headers := make(map[string]string)
+	for _, h := range got["Fetch.continueRequest 1"].Headers {
+		headers[h["name"]] = h["value"]
+	}
+	if len(headers) != 2 || headers["Accept"] != "*/*" || headers["X-Api-Key"] != "secret" {
+		t.Errorf("expected the header to replace the analysed host's own, got %v", headers)
+	}
+	if headers := got["Fetch.continueRequest 2"].Headers; headers != nil {
+		t.Errorf("expected other hosts' requests untouched, got %v", headers)
+	}
+	if r := got["Fetch.continueWithAuth 1"].Response; r["response"] != "ProvideCredentials" || r["username"] != "user" {
+		t.Errorf("expected credentials for the analysed host, got %v", r)
+	}
+	for _, id := range []string{"3", "4"} {
+		if r := got["Fetch.continueWithAuth "+id].Response; r["response"] != "CancelAuth" || r["password"] != "" {
+			t.Errorf("request %s: expected the challenge cancelled, got %v", id, r)
+		}
+	}
+}
+
+func TestWaitForLoadKeepsEveryEvent(t *testing.T) {
+	var events []cdpMessage
+	// More events than the page load needs, all sent before it's awaited
+	for i := 0; i < 2000; i++ {
+		events = append(events, event("Network.dataReceived", `{"dataLength":100}`))
+	}
+	events = append(events,
+		event("Network.responseReceived", `{"loaderId":"L","type":"Document","response":{"url":"https://example.com/","status":201,"headers":{}}}`),
+		event("Page.loadEventFired", `{}`),
+	)
+	c, _ := fakeBrowser(t, "Page.navigate", events)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := c.call(ctx, "S", "Page.navigate", map[string]string{"url": "https://example.com/"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	page := &Page{Header: http.Header{}}
+	if err := c.waitForLoad(ctx, "S", "L", page); err != nil {
+		t.Fatal(err)
+	}
+	if page.StatusCode != 201 {
+		t.Errorf("expected the document's status, got %d", page.StatusCode)
+	}
+}
@@ -1,8 +1,8 @@
 package services
 
 import (
+	"bytes"
 	"context"
-	"errors"
 	"net/http"
 	"net/url"
 	"strings"
@@ -13,76 +13,87 @@ import (
 	"github.com/PuerkitoBio/goquery"
 )
 
-func Crawl(ctx context.Context, targetURL string) (*models.Analysis, error) {
+const maxPageSize = 10 << 20
+
+// Options controls how a single analysis is crawled.
+type Options struct {
+	// RenderMode selects the fetcher when Fetcher is nil.
+	RenderMode string
+	Fetcher    Fetcher
+	// Analyzers limits the checks that run; empty runs every registered
+	// analyzer.
+	Analyzers []string
+	// ExtractionRules pull custom values out of the page.
+	ExtractionRules models.ExtractionRules
+	// Request customises the HTTP requests made for the analysis.
+	Request *RequestConfig
+}
+
+func Crawl(ctx context.Context, targetURL string, opts Options) (*models.Analysis, error) {
 	if ctx.Err() != nil {
 		return nil, ctx.Err()
 	}
 
-	client := &http.Client{
-		Timeout: 10 * time.Second,
-		CheckRedirect: func(req *http.Request, via []*http.Request) error {
-			if len(via) >= 10 {
-				return errors.New("too many redirects")
-			}
-			return nil
-		},
+	parsedURL, err := url.Parse(targetURL)
+	if err != nil {
+		return nil, err
 	}
 
-	resp, err := client.Get(targetURL)
-	if err != nil {
+	if err := opts.Request.Validate(); err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
+	request := opts.Request.forHost(parsedURL.Hostname())
+	defer request.closeIdleConnections()
+	if request != nil && request.Login != nil {
+		if err := request.login(ctx); err != nil {
+			return nil, &LoginError{Err: err}
+		}
+	}
 
-	if resp.StatusCode != 200 {
-		return nil, errors.New("non-200 status code")
+	fetcher := opts.Fetcher
+	if fetcher == nil {
+		if fetcher, err = FetcherFor(opts.RenderMode, request); err != nil {
+			return nil, err
+		}
 	}
 
-	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	page, err := fetcher.Fetch(ctx, targetURL)
 	if err != nil {
 		return nil, err
 	}
 
-	parsedURL, err := url.Parse(targetURL)
+	if isRedirectStatus(page.StatusCode) && page.Header.Get("Location") != "" {
+		return nil, &RedirectError{Chain: page.Redirects, Warnings: redirectWarnings(page.Redirects)}
+	}
+	if page.StatusCode != 200 {
+		return nil, &StatusError{StatusCode: page.StatusCode}
+	}
+
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(page.Body))
 	if err != nil {
 		return nil, err
 	}
 
 	analysis := &models.Analysis{
-		URL:          targetURL,
-		HTMLVersion:  detectHTMLVersion(doc),
-		Title:        strings.TrimSpace(doc.Find("title").Text()),
-		Headings:     countHeadings(doc),
-		HasLoginForm: hasLoginForm(doc),
+		URL:     targetURL,
+		Title:   pageTitle(doc),
+		Content: pageText(doc),
 	}
 
-	analysis.InternalLinks, analysis.ExternalLinks, analysis.BrokenLinks = processLinks(ctx, doc, parsedURL)
-
-	return analysis, nil
-}
-
-func detectHTMLVersion(doc *goquery.Document) string {
-	htmlContent, err := doc.Html()
-	if err != nil {
-		return "Unknown"
+	input := &AnalyzerInput{URL: parsedURL, Page: page, Doc: doc, Request: request}
+	if err := runAnalyzers(ctx, input, opts.Analyzers, analysis); err != nil {
+		return nil, err
 	}
 
-	lower := strings.ToLower(htmlContent)
-
-	if strings.Contains(lower, "<!doctype html>") {
-		return "HTML5"
-	}
-	if strings.Contains(lower, "xhtml") {
-		return "XHTML"
-	}
-	if strings.Contains(lower, "html 4.01") {
-		return "HTML 4.01"
-	}
-	if doc.Find("html").Length() > 0 {
-		return "HTML5"
+	if len(opts.ExtractionRules) > 0 {
+		analysis.Extracted = extractValues(doc, opts.ExtractionRules)
 	}
 
-	return "Unknown"
+	return analysis, nil
+}
+
+func pageTitle(doc *goquery.Document) string {
+	return strings.TrimSpace(doc.Find("title").First().Text())
 }
 
 func countHeadings(doc *goquery.Document) map[string]interface{} {
@@ -94,59 +105,15 @@ func countHeadings(doc *goquery.Document) map[string]interface{} {
 	return headings
 }
 
-func hasLoginForm(doc *goquery.Document) bool {
-	// Simple check for password input
-	if doc.Find("input[type='password']").Length() > 0 {
-		return true
-	}
-
-	// Check for login form patterns
-	hasLogin := false
-	doc.Find("form").Each(func(i int, form *goquery.Selection) {
-		action := strings.ToLower(form.AttrOr("action", ""))
-		class := strings.ToLower(form.AttrOr("class", ""))
-
-		loginWords := []string{"login", "signin", "sign-in", "auth"}
-		for _, word := range loginWords {
-			if strings.Contains(action, word) || strings.Contains(class, word) {
-				hasLogin = true
-				return
-			}
-		}
-	})
-
-	return hasLogin
-}
-
-func processLinks(ctx context.Context, doc *goquery.Document, baseURL *url.URL) (int, int, map[string]interface{}) {
+func processLinks(ctx context.Context, doc *goquery.Document, baseURL *url.URL, client *http.Client) (int, int, map[string]interface{}) {
 	internalCount := 0
 	externalCount := 0
 	brokenLinks := make(map[string]interface{})
 	checkedLinks := make(map[string]bool)
 
 	doc.Find("a[href]").Each(func(_ int, s *goquery.Selection) {
-		href, exists := s.Attr("href")
-		if !exists || href == "" || strings.HasPrefix(href, "#") || strings.HasPrefix(href, "javascript:") {
-			return
-		}
-
-		// Skip non-HTTP protocols
-		if strings.HasPrefix(href, "mailto:") || strings.HasPrefix(href, "tel:") || strings.HasPrefix(href, "ftp:") {
-			return
-		}
-
-		linkURL, err := url.Parse(href)
-		if err != nil {
-			return
-		}
-
-		// Resolve relative URLs
-		if linkURL.Host == "" {
-			linkURL = baseURL.ResolveReference(linkURL)
-		}
-
-		// Only process HTTP/HTTPS links
-		if linkURL.Scheme != "http" && linkURL.Scheme != "https" {
+		linkURL, ok := resolveLink(s, baseURL)
+		if !ok {
 			return
 		}
 
@@ -161,7 +128,7 @@ func processLinks(ctx context.Context, doc *goquery.Document, baseURL *url.URL)
 		fullURL := linkURL.String()
 		if !checkedLinks[fullURL] {
 			checkedLinks[fullURL] = true
-			if isBrokenLink(fullURL) {
+			if isBrokenLink(client, fullURL) {
 				brokenLinks[fullURL] = "Broken"
 			}
 		}
@@ -170,17 +137,59 @@ func processLinks(ctx context.Context, doc *goquery.Document, baseURL *url.URL)
 	return internalCount, externalCount, brokenLinks
 }
 
-func isBrokenLink(linkURL string) bool {
-	client := &http.Client{
-		Timeout: 5 * time.Second,
-		CheckRedirect: func(req *http.Request, via []*http.Request) error {
-			if len(via) >= 5 {
-				return errors.New("too many redirects")
-			}
-			return nil
-		},
+// resolveLink returns the absolute HTTP(S) URL an anchor points to.
+func resolveLink(s *goquery.Selection, baseURL *url.URL) (*url.URL, bool) {
+	href, exists := s.Attr("href")
+	if !exists || href == "" || strings.HasPrefix(href, "#") || strings.HasPrefix(href, "javascript:") {
+		return nil, false
+	}
+
+	// Skip non-HTTP protocols
+	if strings.HasPrefix(href, "mailto:") || strings.HasPrefix(href, "tel:") || strings.HasPrefix(href, "ftp:") {
+		return nil, false
 	}
 
+	linkURL, err := url.Parse(href)
+	if err != nil {
+		return nil, false
+	}
+
+	// Resolve relative URLs
+	if linkURL.Host == "" {
+		linkURL = baseURL.ResolveReference(linkURL)
+	}
+
+	// Only process HTTP/HTTPS links
+	if linkURL.Scheme != "http" && linkURL.Scheme != "https" {
+		return nil, false
+	}
+
+	return linkURL, true
+}
+
+func collectInternalLinks(doc *goquery.Document, baseURL *url.URL) []string {
+	seen := make(map[string]bool)
+	var links []string
+	doc.Find("a[href]").Each(func(_ int, s *goquery.Selection) {
+		linkURL, ok := resolveLink(s, baseURL)
+		if !ok || !strings.EqualFold(linkURL.Host, baseURL.Host) {
+			return
+		}
+		linkURL.Fragment = ""
+		if link := linkURL.String(); !seen[link] {
+			seen[link] = true
+			links = append(links, link)
+		}
+	})
+	return links
+}
+
+// newLinkClient returns the client used to check whether links are broken.
+func newLinkClient(request *RequestConfig) *http.Client {
+	return request.client(5*time.Second, limitRedirects(5))
+}
+
+func isBrokenLink(client *http.Client, linkURL string) bool {
 	resp, err := client.Head(linkURL)
 	if err != nil {
 		// Fallback to GET if HEAD fails
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+var doctypePattern = regexp.MustCompile(`(?is)^\s*(\S+)(?:\s+public\s+["']([^"']*)["'](?:\s+["']([^"']*)["'])?|\s+system\s+["']([^"']*)["'])?`)
+
+// Known public identifiers, matched case-insensitively.
+var doctypeVersions = map[string]string{
+	"-//w3c//dtd html 4.01//en":              "HTML 4.01 Strict",
+	"-//w3c//dtd html 4.01 transitional//en": "HTML 4.01 Transitional",
+	"-//w3c//dtd html 4.01 frameset//en":     "HTML 4.01 Frameset",
+	"-//w3c//dtd html 4.0//en":               "HTML 4.0 Strict",
+	"-//w3c//dtd html 4.0 transitional//en":  "HTML 4.0 Transitional",
+	"-//w3c//dtd html 4.0 frameset//en":      "HTML 4.0 Frameset",
+	"-//w3c//dtd html 3.2 final//en":         "HTML 3.2",
+	"-//w3c//dtd html 3.2//en":               "HTML 3.2",
+	"-//ietf//dtd html 2.0//en":              "HTML 2.0",
+	"-//ietf//dtd html//en":                  "HTML 2.0",
+	"-//w3c//dtd xhtml 1.0 strict//en":       "XHTML 1.0 Strict",
+	"-//w3c//dtd xhtml 1.0 transitional//en": "XHTML 1.0 Transitional",
+	"-//w3c//dtd xhtml 1.0 frameset//en":     "XHTML 1.0 Frameset",
+	"-//w3c//dtd xhtml 1.1//en":              "XHTML 1.1",
+	"-//w3c//dtd xhtml basic 1.0//en":        "XHTML Basic 1.0",
+	"-//w3c//dtd xhtml basic 1.1//en":        "XHTML Basic 1.1",
+	"-//w3c//dtd xhtml+rdfa 1.0//en":         "XHTML+RDFa 1.0",
+	"-//w3c//dtd xhtml+rdfa 1.1//en":         "XHTML+RDFa 1.1",
+	"-//wapforum//dtd xhtml mobile 1.0//en":  "XHTML Mobile 1.0",
+	"-//wapforum//dtd xhtml mobile 1.2//en":  "XHTML Mobile 1.2",
+}
+
+// detectHTMLVersion reads the doctype token from the raw response body rather
+// than searching goquery's re-serialised document, which may not reflect the
+// original doctype and can't tell a doctype apart from text that mentions
+// one. It returns the detected version and the doctype as it appeared in the
+// page.
+func detectHTMLVersion(body []byte) (string, string) {
+	body = bytes.TrimPrefix(body, []byte("\xef\xbb\xbf")) // UTF-8 byte order mark
+	z := html.NewTokenizer(bytes.NewReader(body))
+	for {
+		switch z.Next() {
+		case html.ErrorToken, html.StartTagToken, html.SelfClosingTagToken, html.EndTagToken:
+			// The doctype must precede the first element
+			return "Quirks mode (no doctype)", ""
+		case html.TextToken:
+			if len(bytes.TrimSpace(z.Text())) > 0 {
+				return "Quirks mode (no doctype)", ""
+			}
+		case html.DoctypeToken:
+			raw := strings.TrimSpace(string(z.Raw()))
+			return versionFromDoctype(string(z.Text())), raw
+		}
+	}
+}
+
+func versionFromDoctype(doctype string) string {
+	m := doctypePattern.FindStringSubmatch(doctype)
+	if m == nil || !strings.EqualFold(m[1], "html") {
+		return "Quirks mode"
+	}
+
+	publicID := strings.ToLower(strings.TrimSpace(m[2]))
+	systemID := strings.TrimSpace(m[3] + m[4])
+
+	if publicID == "" {
+		switch {
+		case systemID == "":
+			return "HTML5"
+		case strings.EqualFold(systemID, "about:legacy-compat"):
+			return "HTML5 (legacy-compat)"
+		}
+	}
+
+	version, ok := doctypeVersions[publicID]
+	if !ok {
+		if publicID == "" {
+			return "Unknown"
+		}
+		return "Unknown (" + m[2] + ")"
+	}
+
+	// Without a system identifier these doctypes put browsers into quirks
+	// mode rather than almost-standards mode.
+	if systemID == "" && (version == "HTML 4.01 Transitional" || version == "HTML 4.01 Frameset") {
+		return version + " (quirks mode)"
+	}
+	return version
+}
@@ -0,0 +1,129 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/andybalholm/cascadia"
+	"github.com/antchfx/htmlquery"
+	"github.com/antchfx/xpath"
+	"github.com/saqibroy/web-crawler-dashboard/server/models"
+	"golang.org/x/net/html"
+)
+
+const (
+	RuleCSS   = "css"
+	RuleXPath = "xpath"
+
+	maxExtractionRules  = 50
+	maxExtractedValues  = 500
+	maxExtractedValueSz = 2000
+)
+
+// ValidateExtractionRules checks rule names are unique and every selector
+// compiles, filling in the default rule type.
+func ValidateExtractionRules(rules models.ExtractionRules) error {
+	if len(rules) > maxExtractionRules {
+		return fmt.Errorf("at most %d extraction rules are allowed", maxExtractionRules)
+	}
+
+	names := make(map[string]bool, len(rules))
+	for i := range rules {
+		rule := &rules[i]
+		rule.Name = strings.TrimSpace(rule.Name)
+		if rule.Name == "" {
+			return fmt.Errorf("rule %d: name is required", i+1)
+		}
+		if names[rule.Name] {
+			return fmt.Errorf("rule %q: duplicate name", rule.Name)
+		}
+		names[rule.Name] = true
+
+		if rule.Selector == "" {
+			return fmt.Errorf("rule %q: selector is required", rule.Name)
+		}
+
+		switch rule.Type {
+		case "", RuleCSS:
+			rule.Type = RuleCSS
+			if _, err := cascadia.Compile(rule.Selector); err != nil {
+				return fmt.Errorf("rule %q: invalid CSS selector: %v", rule.Name, err)
+			}
+		case RuleXPath:
+			if _, err := xpath.Compile(rule.Selector); err != nil {
+				return fmt.Errorf("rule %q: invalid XPath expression: %v", rule.Name, err)
+			}
+		default:
+			return fmt.Errorf("rule %q: type must be css or xpath", rule.Name)
+		}
+	}
+	return nil
+}
+
+// extractValues runs the rules against the document. Single-value rules
+// yield the first match or null; multiple-value rules yield a list.
+func extractValues(doc *goquery.Document, rules models.ExtractionRules) models.JSONMap {
+	extracted := models.JSONMap{}
+	for _, rule := range rules {
+		values, err := matchRule(doc, rule)
+		if err != nil {
+			extracted[rule.Name] = map[string]interface{}{"error": err.Error()}
+			continue
+		}
+
+		if rule.Multiple {
+			if len(values) > maxExtractedValues {
+				values = values[:maxExtractedValues]
+			}
+			extracted[rule.Name] = values
+		} else if len(values) > 0 {
+			extracted[rule.Name] = values[0]
+		} else {
+			extracted[rule.Name] = nil
+		}
+	}
+	return extracted
+}
+
+func matchRule(doc *goquery.Document, rule models.ExtractionRule) ([]string, error) {
+	var nodes []*html.Node
+	switch rule.Type {
+	case RuleXPath:
+		if len(doc.Nodes) == 0 {
+			return nil, errors.New("empty document")
+		}
+		var err error
+		if nodes, err = htmlquery.QueryAll(doc.Nodes[0], rule.Selector); err != nil {
+			return nil, err
+		}
+	default:
+		nodes = doc.Find(rule.Selector).Nodes
+	}
+
+	values := []string{}
+	for _, node := range nodes {
+		// XPath attribute results (//a/@href) come back as an element
+		// wrapping the value, so InnerText covers them too
+		value := htmlquery.InnerText(node)
+		if rule.Attribute != "" {
+			var ok bool
+			if value, ok = attrValue(node, rule.Attribute); !ok {
+				continue
+			}
+		}
+
+		values = append(values, truncateBytes(strings.Join(strings.Fields(value), " "), maxExtractedValueSz))
+	}
+	return values, nil
+}
+
+func attrValue(node *html.Node, key string) (string, bool) {
+	for _, attr := range node.Attr {
+		if strings.EqualFold(attr.Key, key) {
+			return attr.Val, true
+		}
+	}
+	return "", false
+}
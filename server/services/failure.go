@@ -0,0 +1,82 @@
+package services
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+)
+
+// Failure reasons group crawl errors whose messages differ, such as the same
+// timeout on different hosts.
+const (
+	FailureTimeout           = "timeout"
+	FailureDNS               = "dns_error"
+	FailureConnectionRefused = "connection_refused"
+	FailureTLS               = "tls_error"
+	FailureRedirectLoop      = "redirect_loop"
+	FailureTooManyRedirects  = "too_many_redirects"
+	FailureLogin             = "login_failed"
+	FailureInvalidConfig     = "invalid_config"
+	FailureOther             = "other"
+)
+
+// StatusError is returned by Crawl when the page responds with a status
+// other than 200.
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("non-200 status code %d", e.StatusCode)
+}
+
+// LoginError is returned by Crawl when the login recipe fails.
+type LoginError struct {
+	Err error
+}
+
+func (e *LoginError) Error() string { return e.Err.Error() }
+func (e *LoginError) Unwrap() error { return e.Err }
+
+// FailureReason classifies a crawl error as one of the Failure reasons, or
+// "http_" and the status code for pages that responded with an error.
+func FailureReason(err error) string {
+	var loginErr *LoginError
+	var statusErr *StatusError
+	var redirectErr *RedirectError
+	var dnsErr *net.DNSError
+	var netErr net.Error
+	var certErr *tls.CertificateVerificationError
+	var unknownAuthority x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var invalidCert x509.CertificateInvalidError
+	var recordErr tls.RecordHeaderError
+
+	switch {
+	case err == nil:
+		return ""
+	case errors.As(err, &loginErr):
+		return FailureLogin
+	case errors.As(err, &statusErr):
+		return fmt.Sprintf("http_%d", statusErr.StatusCode)
+	case errors.As(err, &redirectErr):
+		if redirectErr.loop() {
+			return FailureRedirectLoop
+		}
+		return FailureTooManyRedirects
+	case errors.As(err, &dnsErr):
+		return FailureDNS
+	case errors.As(err, &certErr), errors.As(err, &unknownAuthority), errors.As(err, &hostnameErr),
+		errors.As(err, &invalidCert), errors.As(err, &recordErr):
+		return FailureTLS
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return FailureConnectionRefused
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return FailureTimeout
+	}
+	return FailureOther
+}
@@ -0,0 +1,167 @@
+package services
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/saqibroy/web-crawler-dashboard/server/models"
+)
+
+const (
+	RenderHTTP    = "http"
+	RenderBrowser = "browser"
+)
+
+// Page is a fetched document ready for analysis.
+type Page struct {
+	URL        *url.URL // final URL after redirects
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	// Timing is nil when the fetcher can't measure the load.
+	Timing *Timing
+	// TLS is the connection state of an HTTPS fetch, when the fetcher has it.
+	TLS *tls.ConnectionState
+	// Redirects lists every response from the requested URL to URL,
+	// ending with the page itself.
+	Redirects models.RedirectChain
+}
+
+// Timing breaks down how long the page took to load. TTFB and Total are
+// measured from the start of the fetch, so they include any redirects.
+type Timing struct {
+	DNSLookup    time.Duration
+	Connect      time.Duration
+	TLSHandshake time.Duration
+	TTFB         time.Duration
+	Download     time.Duration
+	Total        time.Duration
+	// TransferSize is the body size as sent over the wire, before
+	// decompression, and BodySize its size once decoded.
+	TransferSize int64
+	BodySize     int64
+}
+
+// Fetcher retrieves the document that the analyzers run against.
+type Fetcher interface {
+	Fetch(ctx context.Context, targetURL string) (*Page, error)
+}
+
+func IsValidRenderMode(mode string) bool {
+	return mode == "" || mode == RenderHTTP || mode == RenderBrowser
+}
+
+// FetcherFor returns the fetcher for a render mode, defaulting to plain HTTP.
+// request may be nil.
+func FetcherFor(mode string, request *RequestConfig) (Fetcher, error) {
+	switch mode {
+	case "", RenderHTTP:
+		return newHTTPFetcher(request), nil
+	case RenderBrowser:
+		b := NewBrowserFetcher()
+		b.Request = request
+		return b, nil
+	default:
+		return nil, fmt.Errorf("unknown render mode %q", mode)
+	}
+}
+
+// HTTPFetcher downloads the raw HTML with net/http, without running scripts.
+type HTTPFetcher struct {
+	Client *http.Client
+}
+
+func NewHTTPFetcher() *HTTPFetcher {
+	return newHTTPFetcher(nil)
+}
+
+func newHTTPFetcher(request *RequestConfig) *HTTPFetcher {
+	return &HTTPFetcher{Client: request.client(10*time.Second, checkRedirect)}
+}
+
+func (f *HTTPFetcher) Fetch(ctx context.Context, targetURL string) (*Page, error) {
+	timing := &Timing{}
+	var dnsStart, connectStart, tlsStart, firstByte time.Time
+	trace := &httptrace.ClientTrace{
+		DNSStart:             func(httptrace.DNSStartInfo) { dnsStart = time.Now() },
+		DNSDone:              func(httptrace.DNSDoneInfo) { timing.DNSLookup += time.Since(dnsStart) },
+		ConnectStart:         func(string, string) { connectStart = time.Now() },
+		ConnectDone:          func(string, string, error) { timing.Connect += time.Since(connectStart) },
+		TLSHandshakeStart:    func() { tlsStart = time.Now() },
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { timing.TLSHandshake += time.Since(tlsStart) },
+		GotFirstResponseByte: func() { firstByte = time.Now() },
+	}
+
+	start := time.Now()
+	ctx, redirects := withRedirectRecorder(httptrace.WithClientTrace(ctx, trace), start)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, targetURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	// Asking for gzip explicitly stops the transport from decompressing
+	// transparently, so the transfer size can be measured.
+	req.Header.Set("Accept-Encoding", "gzip")
+
+	resp, err := f.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	redirects.record(resp)
+
+	counter := &countingReader{r: resp.Body}
+	var reader io.Reader = counter
+	if strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		gz, err := gzip.NewReader(counter)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		reader = gz
+	}
+
+	body, err := io.ReadAll(io.LimitReader(reader, maxPageSize))
+	if err != nil {
+		return nil, err
+	}
+
+	end := time.Now()
+	timing.TTFB = firstByte.Sub(start)
+	timing.Download = end.Sub(firstByte)
+	timing.Total = end.Sub(start)
+	timing.TransferSize = counter.n
+	timing.BodySize = int64(len(body))
+
+	// The body has been decoded, so drop headers that no longer describe it
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+
+	return &Page{
+		URL:        resp.Request.URL,
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       body,
+		Timing:     timing,
+		TLS:        resp.TLS,
+		Redirects:  redirects.hops(),
+	}, nil
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
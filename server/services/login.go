@@ -0,0 +1,195 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/saqibroy/web-crawler-dashboard/server/models"
+)
+
+const (
+	FormLogin         = "login"
+	FormSignup        = "signup"
+	FormPasswordReset = "password_reset"
+	FormTwoFactor     = "two_factor"
+	FormOAuth         = "oauth"
+
+	maxOAuthDetections = 10
+)
+
+var (
+	loginWords   = regexp.MustCompile(`(?i)\b(log[ -]?in|sign[ -]?in|auth)\b`)
+	signupWords  = regexp.MustCompile(`(?i)\b(sign[ -]?up|register|create (an )?account|join)\b`)
+	resetWords   = regexp.MustCompile(`(?i)forgot|reset|recover`)
+	usernameHint = regexp.MustCompile(`(?i)user|email|login|account|phone`)
+	otpHint      = regexp.MustCompile(`(?i)otp|totp|2fa|mfa|one[-_ ]?time|verification|auth[-_]?code|security[-_]?code`)
+	oauthText    = regexp.MustCompile(`(?i)\b((sign|log) ?in with|continue with|sso|single sign[- ]on)\b`)
+	oauthHref    = regexp.MustCompile(`(?i)/oauth|/sso|/saml|accounts\.google\.com|login\.microsoftonline\.com|github\.com/login|facebook\.com/.*dialog/oauth|appleid\.apple\.com/auth|okta\.com|auth0\.com`)
+)
+
+// detectAuthForms classifies the page's authentication forms and SSO buttons,
+// recording for each the selector path and the evidence that matched.
+func detectAuthForms(doc *goquery.Document) models.AuthForms {
+	forms := models.AuthForms{}
+
+	doc.Find("form").Each(func(_ int, form *goquery.Selection) {
+		if detection, ok := classifyForm(form); ok {
+			forms = append(forms, detection)
+		}
+	})
+
+	// Password fields rendered outside a <form>, common in script-driven pages
+	doc.Find("input[type='password']").Each(func(_ int, input *goquery.Selection) {
+		if input.Closest("form").Length() == 0 {
+			forms = append(forms, models.AuthForm{
+				Type:     FormLogin,
+				Selector: selectorPath(input),
+				Evidence: []string{"password input outside a form"},
+			})
+		}
+	})
+
+	oauth := 0
+	doc.Find("a, button").EachWithBreak(func(_ int, el *goquery.Selection) bool {
+		if evidence := oauthEvidence(el); len(evidence) > 0 {
+			forms = append(forms, models.AuthForm{
+				Type:     FormOAuth,
+				Selector: selectorPath(el),
+				Evidence: evidence,
+			})
+			oauth++
+		}
+		return oauth < maxOAuthDetections
+	})
+
+	return forms
+}
+
+// hasLoginForm keeps the has_login_form flag's original meaning: the page
+// has a password input, or a form whose action or class names a login. The
+// finer breakdown is left to the detected forms.
+func hasLoginForm(doc *goquery.Document, forms models.AuthForms) bool {
+	if doc.Find("input[type='password']").Length() > 0 {
+		return true
+	}
+	for _, f := range forms {
+		if f.Type == FormLogin {
+			return true
+		}
+	}
+	return false
+}
+
+func classifyForm(form *goquery.Selection) (models.AuthForm, bool) {
+	var evidence []string
+	passwords, newPasswords, currentPasswords := 0, 0, 0
+	username, otp := "", ""
+
+	form.Find("input").Each(func(_ int, input *goquery.Selection) {
+		inputType := strings.ToLower(input.AttrOr("type", "text"))
+		autocomplete := strings.ToLower(input.AttrOr("autocomplete", ""))
+		hints := input.AttrOr("name", "") + " " + input.AttrOr("id", "") + " " + input.AttrOr("placeholder", "")
+
+		switch {
+		case inputType == "password":
+			passwords++
+			if autocomplete == "new-password" {
+				newPasswords++
+			} else if autocomplete == "current-password" {
+				currentPasswords++
+			}
+		case autocomplete == "one-time-code" || (inputType != "hidden" && otpHint.MatchString(hints)):
+			if otp == "" {
+				otp = inputDescription(input)
+			}
+		case inputType == "email" || autocomplete == "username" || autocomplete == "email" ||
+			(inputType == "text" && usernameHint.MatchString(hints)):
+			if username == "" {
+				username = inputDescription(input)
+			}
+		}
+	})
+
+	wording := strings.Join([]string{
+		form.AttrOr("action", ""),
+		form.AttrOr("id", ""),
+		form.AttrOr("class", ""),
+		form.AttrOr("name", ""),
+		form.Find("button, input[type='submit'], legend, h1, h2, h3").Text(),
+	}, " ")
+	for _, btn := range form.Find("input[type='submit']").Nodes {
+		wording += " " + nodeAttr(btn, "value")
+	}
+
+	// Words are matched whole, and names like login_form hold words too
+	underscores := strings.NewReplacer("_", " ")
+	wording = underscores.Replace(wording)
+	attrs := underscores.Replace(form.AttrOr("action", "") + " " + form.AttrOr("class", ""))
+
+	formType := ""
+	switch {
+	case otp != "" && passwords == 0:
+		formType = FormTwoFactor
+		evidence = append(evidence, "one-time code input "+otp)
+	case passwords >= 2 || newPasswords > 0 || (passwords > 0 && currentPasswords == 0 && signupWords.MatchString(wording)):
+		formType = FormSignup
+		evidence = append(evidence, fmt.Sprintf("%d password inputs", passwords))
+		if newPasswords > 0 {
+			evidence = append(evidence, `autocomplete="new-password"`)
+		}
+		if m := signupWords.FindString(wording); m != "" {
+			evidence = append(evidence, fmt.Sprintf("signup wording %q", m))
+		}
+	case passwords == 1:
+		formType = FormLogin
+		if username != "" {
+			evidence = append(evidence, "username input "+username+" with password input")
+		} else {
+			evidence = append(evidence, "password input")
+		}
+		if currentPasswords > 0 {
+			evidence = append(evidence, `autocomplete="current-password"`)
+		}
+	case username != "" && resetWords.MatchString(wording):
+		formType = FormPasswordReset
+		evidence = append(evidence, "username input "+username, fmt.Sprintf("reset wording %q", resetWords.FindString(wording)))
+	case loginWords.MatchString(attrs):
+		formType = FormLogin
+		evidence = append(evidence, fmt.Sprintf("form action/class contains %q", loginWords.FindString(attrs)))
+	default:
+		return models.AuthForm{}, false
+	}
+
+	if otp != "" && formType != FormTwoFactor {
+		evidence = append(evidence, "one-time code input "+otp)
+	}
+
+	return models.AuthForm{
+		Type:     formType,
+		Selector: selectorPath(form),
+		Evidence: evidence,
+	}, true
+}
+
+func oauthEvidence(el *goquery.Selection) []string {
+	var evidence []string
+	text := strings.Join(strings.Fields(el.Text()+" "+el.AttrOr("aria-label", "")), " ")
+	if oauthText.MatchString(text) {
+		evidence = append(evidence, fmt.Sprintf("button text %q", text))
+	}
+	if href := el.AttrOr("href", ""); oauthHref.MatchString(href) {
+		evidence = append(evidence, "identity provider link "+href)
+	}
+	return evidence
+}
+
+func inputDescription(input *goquery.Selection) string {
+	for _, attr := range []string{"name", "id", "autocomplete", "type"} {
+		if v := input.AttrOr(attr, ""); v != "" {
+			return fmt.Sprintf("%s=%q", attr, v)
+		}
+	}
+	return "input"
+}
@@ -0,0 +1,70 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestDetectAuthForms(t *testing.T) {
+	tests := []struct {
+		name, html string
+		want       []string
+	}{
+		{"login action", `<form action="/login"><input name="q"></form>`, []string{FormLogin}},
+		{"login class with underscore", `<form class="user_login"><input name="q"></form>`, []string{FormLogin}},
+		{"auth action", `<form action="/auth/session"><input name="q"></form>`, []string{FormLogin}},
+		{"author action", `<form action="/authors/search"><input name="q"></form>`, nil},
+		{"oauth class", `<form class="oauth-settings"><input name="q"></form>`, nil},
+		{"password", `<form><input type="email"><input type="password"></form>`, []string{FormLogin}},
+		{"signup wording", `<form><input type="password"><button>Join now</button></form>`, []string{FormSignup}},
+		{"joined wording", `<form><input type="password"><button>Members joined today</button></form>`, []string{FormLogin}},
+		{"registration wording", `<form><input type="password"><button>Register</button></form>`, []string{FormSignup}},
+		{"sso button", `<button>Log in with SSO</button>`, []string{FormOAuth}},
+		{"continue with", `<a href="#">Continue with Google</a>`, []string{FormOAuth}},
+		{"single sign-on", `<a href="#">Single sign-on</a>`, []string{FormOAuth}},
+		{"sso inside a word", `<a href="#">Espresso machines</a>`, nil},
+		{"continue without", `<a href="#">Continue without saving</a>`, nil},
+		{"identity provider link", `<a href="https://accounts.google.com/o/oauth2">Google</a>`, []string{FormOAuth}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader(tt.html))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, form := range detectAuthForms(doc) {
+				got = append(got, form.Type)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHasLoginForm(t *testing.T) {
+	tests := []struct {
+		name, html string
+		want       bool
+	}{
+		{"login", `<form><input type="email"><input type="password"></form>`, true},
+		{"signup", `<form><input type="password"><button>Register</button></form>`, true},
+		{"login action", `<form action="/login"><input name="q"></form>`, true},
+		{"sso link only", `<a href="https://example.okta.com/app">Okta</a>`, false},
+		{"two-factor", `<form><input autocomplete="one-time-code"></form>`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader(tt.html))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := hasLoginForm(doc, detectAuthForms(doc)); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
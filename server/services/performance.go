@@ -0,0 +1,185 @@
+package services
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/saqibroy/web-crawler-dashboard/server/models"
+)
+
+const (
+	// Only the first resources of each page are sized so heavy pages don't
+	// stall the crawl.
+	maxSizedResources   = 60
+	resourceConcurrency = 8
+	resourceTimeout     = 5 * time.Second
+)
+
+type resourceKind int
+
+const (
+	resourceScript resourceKind = iota
+	resourceStylesheet
+	resourceImage
+)
+
+type pageResource struct {
+	kind resourceKind
+	url  string
+}
+
+// measurePerformance combines the fetcher's timings with the number and total
+// size of the scripts, stylesheets and images the page references.
+func measurePerformance(ctx context.Context, in *AnalyzerInput) *models.PerformanceMetrics {
+	m := &models.PerformanceMetrics{PageSize: int64(len(in.Page.Body))}
+
+	if t := in.Page.Timing; t != nil {
+		m.DNSLookupMs = t.DNSLookup.Milliseconds()
+		m.ConnectMs = t.Connect.Milliseconds()
+		m.TLSHandshakeMs = t.TLSHandshake.Milliseconds()
+		m.TTFBMs = t.TTFB.Milliseconds()
+		m.DownloadMs = t.Download.Milliseconds()
+		m.TotalMs = t.Total.Milliseconds()
+		m.TransferSize = t.TransferSize
+		// In browser mode Body is the rendered DOM, not what the server sent
+		if t.BodySize > 0 {
+			m.PageSize = t.BodySize
+		}
+		if t.TransferSize > 0 {
+			m.CompressionRatio = float64(m.PageSize) / float64(t.TransferSize)
+		}
+	}
+
+	resources := collectResources(in.Doc, in.Page.URL)
+	for _, r := range resources {
+		switch r.kind {
+		case resourceScript:
+			m.ScriptCount++
+		case resourceStylesheet:
+			m.StylesheetCount++
+		case resourceImage:
+			m.ImageCount++
+		}
+	}
+
+	if len(resources) > maxSizedResources {
+		resources = resources[:maxSizedResources]
+	}
+	sizes := resourceSizes(ctx, in.Request.client(resourceTimeout, limitRedirects(5)), resources)
+	for i, r := range resources {
+		switch r.kind {
+		case resourceScript:
+			m.ScriptBytes += sizes[i]
+		case resourceStylesheet:
+			m.StylesheetBytes += sizes[i]
+		case resourceImage:
+			m.ImageBytes += sizes[i]
+		}
+	}
+
+	return m
+}
+
+// collectResources lists the distinct external scripts, stylesheets and
+// images referenced by the page.
+func collectResources(doc *goquery.Document, baseURL *url.URL) []pageResource {
+	var resources []pageResource
+	seen := make(map[string]bool)
+
+	add := func(kind resourceKind, href string) {
+		ref, err := url.Parse(strings.TrimSpace(href))
+		if err != nil || href == "" {
+			return
+		}
+		u := baseURL.ResolveReference(ref)
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return
+		}
+		u.Fragment = ""
+		if link := u.String(); !seen[link] {
+			seen[link] = true
+			resources = append(resources, pageResource{kind: kind, url: link})
+		}
+	}
+
+	doc.Find("script[src]").Each(func(_ int, s *goquery.Selection) {
+		add(resourceScript, s.AttrOr("src", ""))
+	})
+	doc.Find("link[rel][href]").Each(func(_ int, s *goquery.Selection) {
+		for _, rel := range strings.Fields(strings.ToLower(s.AttrOr("rel", ""))) {
+			if rel == "stylesheet" {
+				add(resourceStylesheet, s.AttrOr("href", ""))
+				return
+			}
+		}
+	})
+	doc.Find("img[src]").Each(func(_ int, s *goquery.Selection) {
+		add(resourceImage, s.AttrOr("src", ""))
+	})
+
+	return resources
+}
+
+// resourceSizes fetches the size of each resource in parallel. Resources
+// whose size can't be determined count as zero.
+func resourceSizes(ctx context.Context, client *http.Client, resources []pageResource) []int64 {
+	sizes := make([]int64, len(resources))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < resourceConcurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				sizes[i] = resourceSize(ctx, client, resources[i].url)
+			}
+		}()
+	}
+
+	for i := range resources {
+		if ctx.Err() != nil {
+			break
+		}
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return sizes
+}
+
+// resourceSize returns the Content-Length reported for a HEAD request,
+// downloading the resource when the server doesn't send one.
+func resourceSize(ctx context.Context, client *http.Client, resourceURL string) int64 {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, resourceURL, nil)
+	if err != nil {
+		return 0
+	}
+	if resp, err := client.Do(req); err == nil {
+		resp.Body.Close()
+		if resp.StatusCode < 400 && resp.ContentLength > 0 {
+			return resp.ContentLength
+		}
+	}
+
+	req, err = http.NewRequestWithContext(ctx, http.MethodGet, resourceURL, nil)
+	if err != nil {
+		return 0
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return 0
+	}
+	n, _ := io.Copy(io.Discard, io.LimitReader(resp.Body, maxPageSize))
+	return n
+}
@@ -0,0 +1,150 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/saqibroy/web-crawler-dashboard/server/models"
+)
+
+const (
+	maxRedirects = 10
+	// Chains longer than this slow down every visit and dilute link equity
+	maxRedirectHops = 3
+)
+
+// RedirectsResult is the redirect chain followed to reach the page.
+type RedirectsResult struct {
+	Chain    models.RedirectChain `json:"chain"`
+	Warnings models.StringList    `json:"warnings"`
+}
+
+// RedirectError is returned by Crawl when the target never stops
+// redirecting, so the chain can still be stored with the failed analysis.
+type RedirectError struct {
+	Chain    models.RedirectChain
+	Warnings models.StringList
+}
+
+func (e *RedirectError) Error() string {
+	if e.loop() {
+		return "redirect loop detected"
+	}
+	return fmt.Sprintf("stopped after %d redirects", maxRedirects)
+}
+
+func (e *RedirectError) loop() bool {
+	for _, w := range e.Warnings {
+		if w == "redirect_loop" {
+			return true
+		}
+	}
+	return false
+}
+
+// redirectRecorder collects the hops of a single fetch. The HTTP client is
+// shared, so it travels in the request context.
+type redirectRecorder struct {
+	mu    sync.Mutex
+	last  time.Time
+	chain models.RedirectChain
+}
+
+type redirectRecorderKey struct{}
+
+func withRedirectRecorder(ctx context.Context, start time.Time) (context.Context, *redirectRecorder) {
+	rec := &redirectRecorder{last: start}
+	return context.WithValue(ctx, redirectRecorderKey{}, rec), rec
+}
+
+func recorderFrom(ctx context.Context) *redirectRecorder {
+	rec, _ := ctx.Value(redirectRecorderKey{}).(*redirectRecorder)
+	return rec
+}
+
+func (r *redirectRecorder) record(resp *http.Response) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	r.chain = append(r.chain, models.RedirectHop{
+		URL:        resp.Request.URL.String(),
+		StatusCode: resp.StatusCode,
+		Location:   resp.Header.Get("Location"),
+		DurationMs: now.Sub(r.last).Milliseconds(),
+	})
+	r.last = now
+}
+
+func (r *redirectRecorder) hops() models.RedirectChain {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append(models.RedirectChain{}, r.chain...)
+}
+
+// checkRedirect records each redirect response and stops following once the
+// chain loops or grows past maxRedirects. Stopping hands back the last
+// redirect response instead of an error so the chain isn't lost.
+func checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxRedirects {
+		return http.ErrUseLastResponse
+	}
+	for _, prev := range via {
+		if prev.URL.String() == req.URL.String() {
+			return http.ErrUseLastResponse
+		}
+	}
+
+	// The response we stop on is recorded by the fetcher as the final hop
+	if rec := recorderFrom(req.Context()); rec != nil && req.Response != nil {
+		rec.record(req.Response)
+	}
+	return nil
+}
+
+// redirectWarnings flags loops, HTTPS to HTTP downgrades and long chains.
+func redirectWarnings(chain models.RedirectChain) models.StringList {
+	warnings := models.StringList{}
+
+	seen := make(map[string]bool)
+	loop := false
+	for _, hop := range chain {
+		if seen[hop.URL] {
+			loop = true
+		}
+		seen[hop.URL] = true
+	}
+	// A chain that ends on a redirect went back to a URL it already visited
+	if n := len(chain); n > 0 && isRedirectStatus(chain[n-1].StatusCode) && chain[n-1].Location != "" {
+		if next, err := url.Parse(chain[n-1].URL); err == nil {
+			if loc, err := next.Parse(chain[n-1].Location); err == nil && seen[loc.String()] {
+				loop = true
+			}
+		}
+	}
+	if loop {
+		warnings = append(warnings, "redirect_loop")
+	}
+
+	for i := 1; i < len(chain); i++ {
+		if strings.HasPrefix(chain[i-1].URL, "https://") && strings.HasPrefix(chain[i].URL, "http://") {
+			warnings = append(warnings, "https_downgrade")
+			break
+		}
+	}
+
+	if len(chain)-1 > maxRedirectHops {
+		warnings = append(warnings, "long_redirect_chain")
+	}
+
+	return warnings
+}
+
+func isRedirectStatus(code int) bool {
+	return code >= 300 && code < 400
+}
@@ -0,0 +1,227 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/saqibroy/web-crawler-dashboard/server/models"
+	"golang.org/x/net/http/httpguts"
+)
+
+const maxRequestTimeout = 120 * time.Second
+
+// RequestConfig customises the requests made while crawling one analysis:
+// the page fetch, link checks, sitemap lookups and resource sizing. The proxy,
+// User-Agent and timeout apply to every request; headers, cookies and basic
+// auth are only sent to the analysed site's own host so they don't leak to
+// the sites it links to. A nil config uses the defaults.
+type RequestConfig struct {
+	// Proxy is an http, https or socks5 URL, including any credentials.
+	Proxy     string
+	Headers   map[string]string
+	Cookies   map[string]string
+	UserAgent string
+	Timeout   time.Duration
+	Username  string
+	Password  string
+	// Login signs in before the page is fetched, when set.
+	Login *LoginConfig
+
+	host string
+	jar  http.CookieJar
+	// transport is shared by the config's clients, so one crawl keeps one
+	// pool of connections.
+	transport *sharedTransport
+}
+
+// sharedTransport builds its transport on first use.
+type sharedTransport struct {
+	once      sync.Once
+	transport *http.Transport
+}
+
+// NewRequestConfig combines an analysis's stored fetch settings and login
+// recipe with its decrypted credentials. It returns nil when nothing is
+// customised.
+func NewRequestConfig(cfg models.FetchConfig, login *models.LoginRecipe, creds models.FetchCredentials) *RequestConfig {
+	r := &RequestConfig{
+		Proxy:     cfg.Proxy,
+		Headers:   creds.Headers,
+		Cookies:   creds.Cookies,
+		UserAgent: cfg.UserAgent,
+		Timeout:   time.Duration(cfg.TimeoutSeconds) * time.Second,
+		Username:  creds.Username,
+		Password:  creds.Password,
+	}
+
+	if r.Proxy != "" && (creds.ProxyUsername != "" || creds.ProxyPassword != "") {
+		if proxy, err := url.Parse(r.Proxy); err == nil {
+			proxy.User = url.UserPassword(creds.ProxyUsername, creds.ProxyPassword)
+			r.Proxy = proxy.String()
+		}
+	}
+
+	if login != nil && login.URL != "" {
+		r.Login = &LoginConfig{
+			URL:             login.URL,
+			FormSelector:    login.FormSelector,
+			Fields:          make(map[string]string, len(login.Fields)),
+			SuccessSelector: login.SuccessSelector,
+			SuccessText:     login.SuccessText,
+			SuccessURL:      login.SuccessURL,
+		}
+		for _, name := range login.Fields {
+			r.Login.Fields[name] = creds.LoginFields[name]
+		}
+	}
+
+	if r.Proxy == "" && len(r.Headers) == 0 && len(r.Cookies) == 0 && r.UserAgent == "" &&
+		r.Timeout == 0 && r.Username == "" && r.Password == "" && r.Login == nil {
+		return nil
+	}
+	return r
+}
+
+// Validate checks the config before it's stored, so crawls don't fail on it
+// later.
+func (r *RequestConfig) Validate() error {
+	if r == nil {
+		return nil
+	}
+
+	if r.Proxy != "" {
+		u, err := url.Parse(r.Proxy)
+		if err != nil || u.Host == "" {
+			return errors.New("invalid proxy URL")
+		}
+		switch u.Scheme {
+		case "http", "https", "socks5", "socks5h":
+		default:
+			return fmt.Errorf("unsupported proxy scheme %q", u.Scheme)
+		}
+	}
+
+	for name, value := range r.Headers {
+		if !httpguts.ValidHeaderFieldName(name) || !httpguts.ValidHeaderFieldValue(value) {
+			return fmt.Errorf("invalid header %q", name)
+		}
+	}
+	for name, value := range r.Cookies {
+		if name == "" || strings.ContainsAny(name, "=;, \t\r\n") || strings.ContainsAny(value, ";\r\n") {
+			return fmt.Errorf("invalid cookie %q", name)
+		}
+	}
+	if !httpguts.ValidHeaderFieldValue(r.UserAgent) {
+		return errors.New("invalid user agent")
+	}
+	if r.Timeout < 0 || r.Timeout > maxRequestTimeout {
+		return fmt.Errorf("timeout must be between 1 and %d seconds", int(maxRequestTimeout.Seconds()))
+	}
+	if r.Login != nil {
+		return r.Login.validate()
+	}
+	return nil
+}
+
+// forHost returns a copy of the config scoped to the analysed site's host.
+func (r *RequestConfig) forHost(host string) *RequestConfig {
+	if r == nil {
+		return nil
+	}
+	scoped := *r
+	scoped.host = host
+	scoped.transport = &sharedTransport{}
+	return &scoped
+}
+
+// closeIdleConnections closes the connections the config's clients left
+// open, once a crawl is done with them.
+func (r *RequestConfig) closeIdleConnections() {
+	if r != nil && r.transport != nil && r.transport.transport != nil {
+		r.transport.transport.CloseIdleConnections()
+	}
+}
+
+// baseTransport returns the transport that applies the config's proxy,
+// building it once per scoped config.
+func (r *RequestConfig) baseTransport() *http.Transport {
+	build := func() *http.Transport {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		if r.Proxy != "" {
+			// Validate has already rejected unparseable proxies
+			if proxy, err := url.Parse(r.Proxy); err == nil {
+				transport.Proxy = http.ProxyURL(proxy)
+			}
+		}
+		return transport
+	}
+	if r.transport == nil {
+		return build()
+	}
+	r.transport.once.Do(func() { r.transport.transport = build() })
+	return r.transport.transport
+}
+
+// client builds an HTTP client that applies the config. timeout is used
+// unless the config overrides it.
+func (r *RequestConfig) client(timeout time.Duration, checkRedirect func(*http.Request, []*http.Request) error) *http.Client {
+	if r == nil {
+		return &http.Client{Timeout: timeout, CheckRedirect: checkRedirect}
+	}
+
+	if r.Timeout > 0 {
+		timeout = r.Timeout
+	}
+
+	return &http.Client{
+		Timeout:       timeout,
+		CheckRedirect: checkRedirect,
+		Transport:     &configTransport{base: r.baseTransport(), config: r},
+		Jar:           r.jar,
+	}
+}
+
+// configTransport adds the configured headers to each outgoing request.
+type configTransport struct {
+	base   http.RoundTripper
+	config *RequestConfig
+}
+
+func (t *configTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	cfg := t.config
+	req = req.Clone(req.Context())
+
+	if cfg.UserAgent != "" {
+		req.Header.Set("User-Agent", cfg.UserAgent)
+	}
+
+	if strings.EqualFold(req.URL.Hostname(), cfg.host) {
+		for name, value := range cfg.Headers {
+			req.Header.Set(name, value)
+		}
+		for name, value := range cfg.Cookies {
+			req.AddCookie(&http.Cookie{Name: name, Value: value})
+		}
+		if cfg.Username != "" || cfg.Password != "" {
+			req.SetBasicAuth(cfg.Username, cfg.Password)
+		}
+	}
+
+	return t.base.RoundTrip(req)
+}
+
+// limitRedirects returns a CheckRedirect function that gives up after max
+// redirects.
+func limitRedirects(max int) func(*http.Request, []*http.Request) error {
+	return func(req *http.Request, via []*http.Request) error {
+		if len(via) >= max {
+			return errors.New("too many redirects")
+		}
+		return nil
+	}
+}
@@ -0,0 +1,305 @@
+package services
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/saqibroy/web-crawler-dashboard/server/models"
+)
+
+const (
+	gradePass = "pass"
+	gradeWarn = "warn"
+	gradeFail = "fail"
+
+	// HSTS max-age below six months is too short to protect returning visitors
+	minHSTSMaxAge = 180 * 24 * 60 * 60
+
+	maxMixedContent = 50
+	tlsDialTimeout  = 5 * time.Second
+)
+
+// SecurityResult is the security report and the letter grade derived from it.
+type SecurityResult struct {
+	Grade  string                `json:"grade"`
+	Report models.SecurityReport `json:"report"`
+}
+
+// inspectSecurity checks the site's certificate, its security headers and, on
+// HTTPS pages, resources loaded over plain HTTP.
+func inspectSecurity(ctx context.Context, in *AnalyzerInput) *SecurityResult {
+	report := models.SecurityReport{
+		Headers:      gradeSecurityHeaders(in.Page),
+		MixedContent: []string{},
+	}
+
+	if in.Page.URL.Scheme == "https" {
+		state := in.Page.TLS
+		if state == nil {
+			// The browser fetcher doesn't expose the connection, so dial the
+			// site ourselves
+			state = dialTLS(ctx, in.Page.URL)
+		}
+		if state != nil {
+			report.TLS = describeTLS(state, in.Page.URL.Hostname())
+		}
+		report.MixedContent = findMixedContent(in.Doc, in.Page.URL)
+	}
+
+	return &SecurityResult{Grade: securityGrade(in.Page.URL, &report), Report: report}
+}
+
+func dialTLS(ctx context.Context, pageURL *url.URL) *tls.ConnectionState {
+	host := pageURL.Host
+	if pageURL.Port() == "" {
+		host = net.JoinHostPort(pageURL.Hostname(), "443")
+	}
+
+	dialer := &tls.Dialer{
+		NetDialer: &net.Dialer{Timeout: tlsDialTimeout},
+		// Verification happens in describeTLS so invalid chains can be reported
+		Config: &tls.Config{ServerName: pageURL.Hostname(), InsecureSkipVerify: true},
+	}
+	conn, err := dialer.DialContext(ctx, "tcp", host)
+	if err != nil {
+		return nil
+	}
+	defer conn.Close()
+
+	state := conn.(*tls.Conn).ConnectionState()
+	return &state
+}
+
+func describeTLS(state *tls.ConnectionState, hostname string) *models.TLSInfo {
+	info := &models.TLSInfo{
+		Protocol:    tls.VersionName(state.Version),
+		CipherSuite: tls.CipherSuiteName(state.CipherSuite),
+		Chain:       []models.CertificateInfo{},
+	}
+
+	for _, cert := range state.PeerCertificates {
+		info.Chain = append(info.Chain, models.CertificateInfo{
+			Subject:   cert.Subject.String(),
+			Issuer:    cert.Issuer.String(),
+			NotBefore: cert.NotBefore,
+			NotAfter:  cert.NotAfter,
+			SANs:      cert.DNSNames,
+		})
+	}
+	if len(state.PeerCertificates) == 0 {
+		info.Error = "no certificate presented"
+		return info
+	}
+
+	leaf := state.PeerCertificates[0]
+	info.DaysUntilExpiry = int(time.Until(leaf.NotAfter).Hours() / 24)
+
+	if len(state.VerifiedChains) > 0 {
+		info.Valid = true
+		return info
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range state.PeerCertificates[1:] {
+		intermediates.AddCert(cert)
+	}
+	if _, err := leaf.Verify(x509.VerifyOptions{DNSName: hostname, Intermediates: intermediates}); err != nil {
+		info.Error = err.Error()
+	} else {
+		info.Valid = true
+	}
+	return info
+}
+
+func gradeSecurityHeaders(page *Page) []models.SecurityHeader {
+	header := page.Header
+	https := page.URL.Scheme == "https"
+	csp := header.Get("Content-Security-Policy")
+
+	check := func(name string, grade func(value string) (string, string)) models.SecurityHeader {
+		value := strings.TrimSpace(header.Get(name))
+		g, msg := grade(value)
+		return models.SecurityHeader{Name: name, Value: value, Grade: g, Message: msg}
+	}
+
+	return []models.SecurityHeader{
+		check("Strict-Transport-Security", func(v string) (string, string) {
+			if !https {
+				return gradeFail, "page is not served over HTTPS"
+			}
+			if v == "" {
+				return gradeFail, "header is missing"
+			}
+			maxAge, ok := directiveValue(v, "max-age")
+			if age, err := strconv.Atoi(maxAge); !ok || err != nil || age < minHSTSMaxAge {
+				return gradeWarn, "max-age is shorter than six months"
+			}
+			return gradePass, ""
+		}),
+		check("Content-Security-Policy", func(v string) (string, string) {
+			if v == "" {
+				return gradeFail, "header is missing"
+			}
+			if strings.Contains(v, "'unsafe-inline'") || strings.Contains(v, "'unsafe-eval'") {
+				return gradeWarn, "policy allows unsafe-inline or unsafe-eval"
+			}
+			return gradePass, ""
+		}),
+		check("X-Frame-Options", func(v string) (string, string) {
+			switch strings.ToUpper(v) {
+			case "DENY", "SAMEORIGIN":
+				return gradePass, ""
+			}
+			if _, ok := directiveValue(csp, "frame-ancestors"); ok {
+				return gradePass, "framing is restricted by the CSP frame-ancestors directive"
+			}
+			if v == "" {
+				return gradeFail, "header is missing"
+			}
+			return gradeWarn, "value should be DENY or SAMEORIGIN"
+		}),
+		check("X-Content-Type-Options", func(v string) (string, string) {
+			if strings.EqualFold(v, "nosniff") {
+				return gradePass, ""
+			}
+			if v == "" {
+				return gradeFail, "header is missing"
+			}
+			return gradeWarn, "value should be nosniff"
+		}),
+		check("Referrer-Policy", func(v string) (string, string) {
+			if v == "" {
+				return gradeFail, "header is missing"
+			}
+			// With a fallback list the last supported policy wins
+			policies := strings.Split(v, ",")
+			switch strings.ToLower(strings.TrimSpace(policies[len(policies)-1])) {
+			case "unsafe-url", "no-referrer-when-downgrade":
+				return gradeWarn, "policy leaks the full URL to other origins"
+			}
+			return gradePass, ""
+		}),
+		check("Permissions-Policy", func(v string) (string, string) {
+			if v != "" {
+				return gradePass, ""
+			}
+			if header.Get("Feature-Policy") != "" {
+				return gradeWarn, "only the deprecated Feature-Policy header is set"
+			}
+			return gradeFail, "header is missing"
+		}),
+	}
+}
+
+// directiveValue finds a directive in a header made of ; separated
+// directives, such as HSTS or CSP.
+func directiveValue(header, name string) (string, bool) {
+	for _, part := range strings.Split(header, ";") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		fields := strings.Fields(key)
+		if len(fields) > 0 && strings.EqualFold(fields[0], name) {
+			if value == "" {
+				value = strings.Join(fields[1:], " ")
+			}
+			return strings.Trim(strings.TrimSpace(value), `"`), true
+		}
+	}
+	return "", false
+}
+
+// findMixedContent lists the subresources an HTTPS page loads over plain HTTP.
+func findMixedContent(doc *goquery.Document, pageURL *url.URL) []string {
+	found := []string{}
+	seen := make(map[string]bool)
+
+	sources := []struct{ selector, attr string }{
+		{"script[src]", "src"},
+		{"link[rel][href]", "href"},
+		{"img[src]", "src"},
+		{"iframe[src]", "src"},
+		{"audio[src]", "src"},
+		{"video[src]", "src"},
+		{"video[poster]", "poster"},
+		{"source[src]", "src"},
+		{"embed[src]", "src"},
+		{"object[data]", "data"},
+		{"input[type=image][src]", "src"},
+		{"form[action]", "action"},
+	}
+	for _, src := range sources {
+		attr := src.attr
+		doc.Find(src.selector).Each(func(_ int, s *goquery.Selection) {
+			if s.Is("link") && !loadsResource(s.AttrOr("rel", "")) {
+				return
+			}
+			ref, err := url.Parse(strings.TrimSpace(s.AttrOr(attr, "")))
+			if err != nil {
+				return
+			}
+			u := pageURL.ResolveReference(ref)
+			if u.Scheme != "http" || seen[u.String()] || len(found) >= maxMixedContent {
+				return
+			}
+			seen[u.String()] = true
+			found = append(found, u.String())
+		})
+	}
+	return found
+}
+
+// loadsResource reports whether a link element's rel makes the browser fetch
+// its href, as opposed to a plain navigation hint like rel=alternate.
+func loadsResource(rel string) bool {
+	for _, r := range strings.Fields(strings.ToLower(rel)) {
+		switch r {
+		case "stylesheet", "icon", "preload", "modulepreload", "manifest":
+			return true
+		}
+	}
+	return false
+}
+
+// securityGrade turns the report into a letter grade from A to F. Each header
+// scores two points for a pass and one for a warning; an invalid or expired
+// certificate fails the page outright and mixed content caps it at C.
+func securityGrade(pageURL *url.URL, report *models.SecurityReport) string {
+	if pageURL.Scheme != "https" || (report.TLS != nil && (!report.TLS.Valid || report.TLS.DaysUntilExpiry < 0)) {
+		return "F"
+	}
+
+	score := 0
+	for _, h := range report.Headers {
+		switch h.Grade {
+		case gradePass:
+			score += 2
+		case gradeWarn:
+			score++
+		}
+	}
+	ratio := float64(score) / float64(2*len(report.Headers))
+
+	grade := "F"
+	switch {
+	case ratio >= 0.9:
+		grade = "A"
+	case ratio >= 0.75:
+		grade = "B"
+	case ratio >= 0.6:
+		grade = "C"
+	case ratio >= 0.4:
+		grade = "D"
+	}
+
+	// Letters sort alphabetically, so this only lowers an A or B
+	if len(report.MixedContent) > 0 && grade < "C" {
+		grade = "C"
+	}
+	return grade
+}
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/saqibroy/web-crawler-dashboard/server/models"
+)
+
+const maxTitleLength = 60
+
+// SEOResult is the search-related metadata found on a page.
+type SEOResult struct {
+	MetaDescription string            `json:"meta_description"`
+	CanonicalURL    string            `json:"canonical_url"`
+	MetaRobots      string            `json:"meta_robots"`
+	XRobotsTag      string            `json:"x_robots_tag"`
+	OpenGraph       models.JSONMap    `json:"open_graph"`
+	TwitterCard     models.JSONMap    `json:"twitter_card"`
+	Hreflang        models.JSONMap    `json:"hreflang"`
+	Viewport        string            `json:"viewport"`
+	Charset         string            `json:"charset"`
+	Warnings        models.StringList `json:"warnings"`
+}
+
+// extractSEO collects the page's search-related metadata and flags common
+// problems as SEO warnings.
+func extractSEO(doc *goquery.Document, page *Page) *SEOResult {
+	pageURL := page.URL
+	r := &SEOResult{
+		MetaDescription: metaContent(doc, "name", "description"),
+		MetaRobots:      metaContent(doc, "name", "robots"),
+		XRobotsTag:      strings.Join(page.Header.Values("X-Robots-Tag"), ", "),
+		Viewport:        metaContent(doc, "name", "viewport"),
+		Charset:         detectCharset(doc, page.Header),
+		OpenGraph:       prefixedMeta(doc, "property", "og:"),
+		TwitterCard:     prefixedMeta(doc, "name", "twitter:"),
+		Hreflang:        models.JSONMap{},
+	}
+
+	if href, ok := doc.Find("link[rel='canonical']").First().Attr("href"); ok {
+		r.CanonicalURL = resolveURL(pageURL, href)
+	}
+
+	doc.Find("link[rel='alternate'][hreflang]").Each(func(_ int, s *goquery.Selection) {
+		lang := strings.ToLower(strings.TrimSpace(s.AttrOr("hreflang", "")))
+		if href, ok := s.Attr("href"); ok && lang != "" {
+			r.Hreflang[lang] = resolveURL(pageURL, href)
+		}
+	})
+
+	r.Warnings = seoWarnings(doc, pageURL, pageTitle(doc), r)
+	return r
+}
+
+func seoWarnings(doc *goquery.Document, pageURL *url.URL, title string, r *SEOResult) models.StringList {
+	warnings := models.StringList{}
+
+	switch h1 := doc.Find("h1").Length(); {
+	case h1 == 0:
+		warnings = append(warnings, "missing_h1")
+	case h1 > 1:
+		warnings = append(warnings, "duplicate_h1")
+	}
+
+	if title == "" {
+		warnings = append(warnings, "missing_title")
+	} else if utf8.RuneCountInString(title) > maxTitleLength {
+		warnings = append(warnings, "title_too_long")
+	}
+
+	if r.MetaDescription == "" {
+		warnings = append(warnings, "missing_meta_description")
+	}
+
+	if r.CanonicalURL != "" && normalizeSitemapURL(r.CanonicalURL) != normalizeSitemapURL(pageURL.String()) {
+		warnings = append(warnings, "canonical_points_elsewhere")
+	}
+
+	if r.Viewport == "" {
+		warnings = append(warnings, "missing_viewport")
+	}
+
+	robots := strings.ToLower(r.MetaRobots + "," + r.XRobotsTag)
+	if strings.Contains(robots, "noindex") {
+		warnings = append(warnings, "noindex")
+	}
+
+	return warnings
+}
+
+func metaContent(doc *goquery.Document, attr, value string) string {
+	content := ""
+	doc.Find("meta[" + attr + "]").EachWithBreak(func(_ int, s *goquery.Selection) bool {
+		if strings.EqualFold(s.AttrOr(attr, ""), value) {
+			content = strings.TrimSpace(s.AttrOr("content", ""))
+			return false
+		}
+		return true
+	})
+	return content
+}
+
+func prefixedMeta(doc *goquery.Document, attr, prefix string) models.JSONMap {
+	tags := models.JSONMap{}
+	doc.Find("meta[" + attr + "]").Each(func(_ int, s *goquery.Selection) {
+		key := strings.ToLower(s.AttrOr(attr, ""))
+		if !strings.HasPrefix(key, prefix) {
+			return
+		}
+		// Keep the first value for repeated tags such as og:image
+		if _, exists := tags[key]; !exists {
+			tags[key] = strings.TrimSpace(s.AttrOr("content", ""))
+		}
+	})
+	return tags
+}
+
+func detectCharset(doc *goquery.Document, header http.Header) string {
+	if charset, ok := doc.Find("meta[charset]").First().Attr("charset"); ok {
+		return strings.ToLower(strings.TrimSpace(charset))
+	}
+
+	if equiv := metaContent(doc, "http-equiv", "content-type"); equiv != "" {
+		if _, params, err := mime.ParseMediaType(equiv); err == nil && params["charset"] != "" {
+			return strings.ToLower(params["charset"])
+		}
+	}
+
+	if _, params, err := mime.ParseMediaType(header.Get("Content-Type")); err == nil {
+		return strings.ToLower(params["charset"])
+	}
+	return ""
+}
+
+func resolveURL(base *url.URL, href string) string {
+	ref, err := url.Parse(strings.TrimSpace(href))
+	if err != nil {
+		return href
+	}
+	return base.ResolveReference(ref).String()
+}
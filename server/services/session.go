@@ -0,0 +1,203 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/andybalholm/cascadia"
+)
+
+const loginTimeout = 15 * time.Second
+
+// LoginConfig is a login recipe with its field values filled in.
+type LoginConfig struct {
+	URL string
+	// FormSelector picks the login form; by default it's the first form
+	// with a password field.
+	FormSelector string
+	Fields       map[string]string
+	// At least one of these must match the response for the login to count
+	// as successful. With none set, the response mustn't contain a password
+	// field.
+	SuccessSelector string
+	SuccessText     string
+	SuccessURL      string
+}
+
+func (l *LoginConfig) validate() error {
+	if u, err := url.ParseRequestURI(l.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return errors.New("login URL must be an absolute http or https URL")
+	}
+	if len(l.Fields) == 0 {
+		return errors.New("login recipe needs at least one field")
+	}
+	for _, selector := range []string{l.FormSelector, l.SuccessSelector} {
+		if selector == "" {
+			continue
+		}
+		if _, err := cascadia.Compile(selector); err != nil {
+			return fmt.Errorf("invalid selector %q: %v", selector, err)
+		}
+	}
+	return nil
+}
+
+// login runs the recipe and keeps the resulting session cookies in a jar that
+// every client built from r afterwards shares.
+func (r *RequestConfig) login(ctx context.Context) error {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return err
+	}
+	r.jar = jar
+
+	recipe := r.Login
+	client := r.client(loginTimeout, limitRedirects(10))
+
+	loginURL, doc, err := getDocument(ctx, client, http.MethodGet, recipe.URL, nil)
+	if err != nil {
+		return fmt.Errorf("login: failed to load login page: %w", err)
+	}
+
+	form := findLoginForm(doc, recipe.FormSelector)
+	if form.Length() == 0 {
+		return errors.New("login: login form not found")
+	}
+
+	values := formValues(form)
+	for name, value := range recipe.Fields {
+		values.Set(name, value)
+	}
+
+	action := loginURL
+	if href, ok := form.Attr("action"); ok && strings.TrimSpace(href) != "" {
+		ref, err := url.Parse(strings.TrimSpace(href))
+		if err != nil {
+			return fmt.Errorf("login: invalid form action: %w", err)
+		}
+		action = loginURL.ResolveReference(ref)
+	}
+
+	var finalURL *url.URL
+	if strings.EqualFold(form.AttrOr("method", "get"), "post") {
+		finalURL, doc, err = getDocument(ctx, client, http.MethodPost, action.String(), values)
+	} else {
+		query := *action
+		query.RawQuery = values.Encode()
+		finalURL, doc, err = getDocument(ctx, client, http.MethodGet, query.String(), nil)
+	}
+	if err != nil {
+		return fmt.Errorf("login: failed to submit login form: %w", err)
+	}
+
+	if !loginSucceeded(recipe, finalURL, doc) {
+		return errors.New("login: success check did not match after signing in")
+	}
+	return nil
+}
+
+// getDocument requests a page, sending values as a form body when set, and
+// parses the response. It returns the final URL after redirects.
+func getDocument(ctx context.Context, client *http.Client, method, target string, values url.Values) (*url.URL, *goquery.Document, error) {
+	var body io.Reader
+	if values != nil {
+		body = strings.NewReader(values.Encode())
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return nil, nil, err
+	}
+	if values != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return nil, nil, fmt.Errorf("status code %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxPageSize))
+	if err != nil {
+		return nil, nil, err
+	}
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, nil, err
+	}
+	return resp.Request.URL, doc, nil
+}
+
+func findLoginForm(doc *goquery.Document, selector string) *goquery.Selection {
+	if selector != "" {
+		return doc.Find(selector).First()
+	}
+	return doc.Find("form").FilterFunction(func(_ int, s *goquery.Selection) bool {
+		return s.Find("input[type='password']").Length() > 0
+	}).First()
+}
+
+// formValues collects the values a browser would submit without user input,
+// such as hidden CSRF tokens and pre-selected options.
+func formValues(form *goquery.Selection) url.Values {
+	values := url.Values{}
+
+	form.Find("input[name]").Each(func(_ int, s *goquery.Selection) {
+		name := s.AttrOr("name", "")
+		switch strings.ToLower(s.AttrOr("type", "text")) {
+		case "submit", "button", "image", "reset", "file":
+			return
+		case "checkbox", "radio":
+			if _, checked := s.Attr("checked"); !checked {
+				return
+			}
+			values.Add(name, s.AttrOr("value", "on"))
+		default:
+			values.Add(name, s.AttrOr("value", ""))
+		}
+	})
+
+	form.Find("textarea[name]").Each(func(_ int, s *goquery.Selection) {
+		values.Add(s.AttrOr("name", ""), s.Text())
+	})
+
+	form.Find("select[name]").Each(func(_ int, s *goquery.Selection) {
+		option := s.Find("option[selected]").First()
+		if option.Length() == 0 {
+			option = s.Find("option").First()
+		}
+		if option.Length() > 0 {
+			values.Add(s.AttrOr("name", ""), option.AttrOr("value", strings.TrimSpace(option.Text())))
+		}
+	})
+
+	return values
+}
+
+func loginSucceeded(recipe *LoginConfig, finalURL *url.URL, doc *goquery.Document) bool {
+	if recipe.SuccessSelector == "" && recipe.SuccessText == "" && recipe.SuccessURL == "" {
+		return doc.Find("input[type='password']").Length() == 0
+	}
+
+	if recipe.SuccessSelector != "" && doc.Find(recipe.SuccessSelector).Length() > 0 {
+		return true
+	}
+	if recipe.SuccessText != "" && strings.Contains(doc.Text(), recipe.SuccessText) {
+		return true
+	}
+	return recipe.SuccessURL != "" && strings.Contains(finalURL.String(), recipe.SuccessURL)
+}
@@ -0,0 +1,308 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	maxSitemapSize     = 50 << 20 // sitemaps.org limit of 50MB uncompressed
+	maxSitemapDepth    = 3
+	maxSitemapFiles    = 20
+	maxSitemapChecks   = 50
+	sitemapConcurrency = 8
+	maxReportedMissing = 100
+	staleAfter         = 365 * 24 * time.Hour
+)
+
+type sitemapDocument struct {
+	URLs     []sitemapURL `xml:"url"`
+	Sitemaps []sitemapURL `xml:"sitemap"`
+}
+
+type sitemapURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod"`
+}
+
+type sitemapFetcher struct {
+	ctx     context.Context
+	client  *http.Client
+	visited map[string]bool
+	urls    []sitemapURL
+	// errs holds the errors of child sitemaps, which don't fail their
+	// index, and truncated is set when sitemaps were skipped for exceeding
+	// maxSitemapFiles or maxSitemapDepth.
+	errs      []string
+	truncated bool
+}
+
+func newSitemapFetcher(ctx context.Context, request *RequestConfig) *sitemapFetcher {
+	return &sitemapFetcher{
+		ctx:     ctx,
+		client:  request.client(15*time.Second, nil),
+		visited: make(map[string]bool),
+	}
+}
+
+// SitemapURLs are the page URLs read from a sitemap and its children.
+type SitemapURLs struct {
+	URLs []string
+	// Errors holds the errors of child sitemaps that couldn't be read, and
+	// Truncated is set when sitemaps were skipped for exceeding the limits.
+	Errors    []string
+	Truncated bool
+}
+
+// FetchSitemapURLs downloads a sitemap, following sitemap indexes, and returns
+// the page URLs listed in it.
+func FetchSitemapURLs(ctx context.Context, sitemapURL string) (*SitemapURLs, error) {
+	f := newSitemapFetcher(ctx, nil)
+	if err := f.fetch(sitemapURL, 0); err != nil {
+		return nil, err
+	}
+
+	if len(f.urls) == 0 {
+		if len(f.errs) > 0 {
+			return nil, fmt.Errorf("sitemap contains no URLs: %s", strings.Join(f.errs, "; "))
+		}
+		return nil, errors.New("sitemap contains no URLs")
+	}
+
+	result := &SitemapURLs{URLs: make([]string, 0, len(f.urls)), Errors: f.errs, Truncated: f.truncated}
+	for _, u := range f.urls {
+		result.URLs = append(result.URLs, u.Loc)
+	}
+	return result, nil
+}
+
+func (f *sitemapFetcher) fetch(sitemapURL string, depth int) error {
+	if f.visited[sitemapURL] {
+		return nil
+	}
+	if len(f.visited) >= maxSitemapFiles {
+		f.truncated = true
+		return nil
+	}
+	f.visited[sitemapURL] = true
+
+	body, err := f.get(sitemapURL)
+	if err != nil {
+		return err
+	}
+
+	if len(body) > 2 && body[0] == 0x1f && body[1] == 0x8b {
+		gz, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return fmt.Errorf("invalid gzip sitemap %s: %w", sitemapURL, err)
+		}
+		body, err = io.ReadAll(io.LimitReader(gz, maxSitemapSize))
+		gz.Close()
+		if err != nil {
+			return fmt.Errorf("invalid gzip sitemap %s: %w", sitemapURL, err)
+		}
+	}
+
+	var doc sitemapDocument
+	if err := xml.Unmarshal(body, &doc); err != nil {
+		return fmt.Errorf("invalid sitemap %s: %w", sitemapURL, err)
+	}
+
+	for _, u := range doc.URLs {
+		if u.Loc = strings.TrimSpace(u.Loc); u.Loc != "" {
+			f.urls = append(f.urls, u)
+		}
+	}
+
+	if depth >= maxSitemapDepth {
+		f.truncated = f.truncated || len(doc.Sitemaps) > 0
+		return nil
+	}
+	for _, s := range doc.Sitemaps {
+		if loc := strings.TrimSpace(s.Loc); loc != "" {
+			// A broken child sitemap shouldn't discard the rest of the index
+			if err := f.fetch(loc, depth+1); err != nil {
+				f.errs = append(f.errs, err.Error())
+			}
+		}
+	}
+	return nil
+}
+
+func (f *sitemapFetcher) get(target string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(f.ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("%s returned status %d", target, resp.StatusCode)
+	}
+
+	return io.ReadAll(io.LimitReader(resp.Body, maxSitemapSize))
+}
+
+// discover returns the sitemaps declared in robots.txt, falling back
+// to the conventional /sitemap.xml location.
+func (f *sitemapFetcher) discover(baseURL *url.URL) []string {
+	root := &url.URL{Scheme: baseURL.Scheme, Host: baseURL.Host}
+
+	var sitemaps []string
+	if body, err := f.get(root.JoinPath("robots.txt").String()); err == nil {
+		scanner := bufio.NewScanner(bytes.NewReader(body))
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if len(line) > 8 && strings.EqualFold(line[:8], "sitemap:") {
+				if loc := strings.TrimSpace(line[8:]); loc != "" {
+					sitemaps = append(sitemaps, loc)
+				}
+			}
+		}
+	}
+
+	if len(sitemaps) == 0 {
+		sitemaps = append(sitemaps, root.JoinPath("sitemap.xml").String())
+	}
+	return sitemaps
+}
+
+// analyzeSitemap discovers the site's sitemaps and reports broken sitemap
+// entries, internal links missing from the sitemap and stale lastmod values.
+func analyzeSitemap(ctx context.Context, baseURL *url.URL, internalLinks []string, request *RequestConfig) map[string]interface{} {
+	f := newSitemapFetcher(ctx, request)
+	linkClient := newLinkClient(request)
+	sitemaps := f.discover(baseURL)
+
+	for _, s := range sitemaps {
+		if err := f.fetch(s, 0); err != nil {
+			f.errs = append(f.errs, err.Error())
+		}
+	}
+
+	result := map[string]interface{}{
+		"sitemaps":  sitemaps,
+		"url_count": len(f.urls),
+	}
+	if len(f.errs) > 0 {
+		result["errors"] = f.errs
+	}
+	if f.truncated {
+		result["truncated"] = true
+	}
+	if len(f.urls) == 0 {
+		result["found"] = false
+		return result
+	}
+	result["found"] = true
+
+	checked := f.urls[:min(len(f.urls), maxSitemapChecks)]
+	brokenURLs := make(map[string]interface{})
+	for i, broken := range checkSitemapURLs(ctx, linkClient, checked) {
+		if broken {
+			brokenURLs[checked[i].Loc] = "Broken"
+		}
+	}
+
+	listed := make(map[string]bool, len(f.urls))
+	staleCount := 0
+	var oldest time.Time
+
+	for _, u := range f.urls {
+		listed[normalizeSitemapURL(u.Loc)] = true
+
+		if lastMod, ok := parseLastMod(u.LastMod); ok {
+			if time.Since(lastMod) > staleAfter {
+				staleCount++
+			}
+			if oldest.IsZero() || lastMod.Before(oldest) {
+				oldest = lastMod
+			}
+		}
+	}
+
+	missing := []string{}
+	for _, link := range internalLinks {
+		if !listed[normalizeSitemapURL(link)] && len(missing) < maxReportedMissing {
+			missing = append(missing, link)
+		}
+	}
+	sort.Strings(missing)
+
+	result["checked_urls"] = len(checked)
+	result["broken_urls"] = brokenURLs
+	result["missing_internal_links"] = missing
+	result["stale_urls"] = staleCount
+	result["stale_after_days"] = int(staleAfter.Hours() / 24)
+	if !oldest.IsZero() {
+		result["oldest_lastmod"] = oldest.Format(time.RFC3339)
+	}
+
+	return result
+}
+
+// checkSitemapURLs reports which of the URLs are broken, checking a few at a
+// time.
+func checkSitemapURLs(ctx context.Context, client *http.Client, urls []sitemapURL) []bool {
+	broken := make([]bool, len(urls))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < sitemapConcurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				broken[i] = isBrokenLink(client, urls[i].Loc)
+			}
+		}()
+	}
+
+	for i := range urls {
+		if ctx.Err() != nil {
+			break
+		}
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return broken
+}
+
+func normalizeSitemapURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+	u.Fragment = ""
+	u.Host = strings.ToLower(u.Host)
+	u.Path = strings.TrimSuffix(u.Path, "/")
+	return u.String()
+}
+
+func parseLastMod(value string) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04Z07:00", "2006-01-02"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,56 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestAnalyzeSitemap(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/robots.txt":
+			fmt.Fprintf(w, "Sitemap: %s/index.xml\n", server.URL)
+		case "/index.xml":
+			// A broken child, one with pages, and more than are read
+			fmt.Fprintf(w, `<sitemapindex><sitemap><loc>%[1]s/missing.xml</loc></sitemap><sitemap><loc>%[1]s/pages.xml</loc></sitemap>`, server.URL)
+			for i := 0; i < maxSitemapFiles; i++ {
+				fmt.Fprintf(w, `<sitemap><loc>%s/empty-%d.xml</loc></sitemap>`, server.URL, i)
+			}
+			fmt.Fprint(w, `</sitemapindex>`)
+		case "/pages.xml":
+			fmt.Fprintf(w, `<urlset><url><loc>%[1]s/</loc></url><url><loc>%[1]s/gone</loc></url></urlset>`, server.URL)
+		case "/", "/about":
+		default:
+			if strings.HasPrefix(r.URL.Path, "/empty-") {
+				fmt.Fprint(w, `<urlset></urlset>`)
+				return
+			}
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	base, _ := url.Parse(server.URL)
+	result := analyzeSitemap(context.Background(), base, []string{server.URL + "/about"}, nil)
+
+	errs, _ := result["errors"].([]string)
+	if len(errs) != 1 || !strings.Contains(errs[0], "/missing.xml returned status 404") {
+		t.Errorf("expected the broken child sitemap reported, got %v", result["errors"])
+	}
+	if result["truncated"] != true {
+		t.Error("expected the sitemap to be reported as truncated")
+	}
+	broken := result["broken_urls"].(map[string]interface{})
+	if len(broken) != 1 || broken[server.URL+"/gone"] == nil || result["checked_urls"] != 2 {
+		t.Errorf("unexpected checks: %v of %v", broken, result["checked_urls"])
+	}
+	if missing := result["missing_internal_links"].([]string); len(missing) != 1 || missing[0] != server.URL+"/about" {
+		t.Errorf("unexpected missing links: %v", missing)
+	}
+}
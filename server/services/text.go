@@ -0,0 +1,83 @@
+package services
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+// maxContentSize caps the visible text stored for search.
+const maxContentSize = 512 << 10
+
+// hiddenElements never contribute visible text.
+var hiddenElements = map[string]bool{
+	"script": true, "style": true, "noscript": true, "template": true,
+	"svg": true, "iframe": true, "object": true, "head": true,
+}
+
+// inlineElements run on with the text around them; every other element
+// separates words.
+var inlineElements = map[string]bool{
+	"a": true, "abbr": true, "b": true, "bdi": true, "bdo": true, "cite": true,
+	"code": true, "data": true, "dfn": true, "em": true, "i": true, "kbd": true,
+	"mark": true, "q": true, "s": true, "samp": true, "small": true, "span": true,
+	"strong": true, "sub": true, "sup": true, "time": true, "u": true, "var": true,
+}
+
+// pageText returns the text a visitor would read on the page, with
+// whitespace collapsed, for full-text search.
+func pageText(doc *goquery.Document) string {
+	var b strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		switch n.Type {
+		case html.TextNode:
+			b.WriteString(n.Data)
+			return
+		case html.ElementNode:
+			if hiddenElements[n.Data] || hasAttr(n, "hidden") {
+				return
+			}
+		}
+
+		block := n.Type == html.ElementNode && !inlineElements[n.Data]
+		if block {
+			b.WriteByte(' ')
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+		if block {
+			b.WriteByte(' ')
+		}
+	}
+	for _, n := range doc.Nodes {
+		walk(n)
+	}
+
+	text := strings.Join(strings.Fields(b.String()), " ")
+	return truncateBytes(text, maxContentSize)
+}
+
+// truncateBytes cuts s to at most n bytes, backing off to the start of a rune
+// so a multi-byte character isn't split.
+func truncateBytes(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+func hasAttr(n *html.Node, name string) bool {
+	for _, attr := range n.Attr {
+		if attr.Key == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,27 @@
+package services
+
+import (
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncateBytes(t *testing.T) {
+	tests := []struct {
+		s    string
+		n    int
+		want string
+	}{
+		{"short", 10, "short"},
+		{"exactly", 7, "exactly"},
+		{"ascii text", 5, "ascii"},
+		{"café", 4, "caf"},
+		{"日本語", 7, "日本"},
+		{"\U0001F600", 3, ""},
+	}
+	for _, tt := range tests {
+		got := truncateBytes(tt.s, tt.n)
+		if got != tt.want || !utf8.ValidString(got) {
+			t.Errorf("truncateBytes(%q, %d) = %q, want %q", tt.s, tt.n, got, tt.want)
+		}
+	}
+}
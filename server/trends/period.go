@@ -0,0 +1,53 @@
+package trends
+
+import "time"
+
+// Period is the width of a trend bucket.
+type Period string
+
+const (
+	Hour  Period = "hour"
+	Day   Period = "day"
+	Week  Period = "week"
+	Month Period = "month"
+)
+
+var Periods = []Period{Hour, Day, Week, Month}
+
+func (p Period) Valid() bool {
+	for _, period := range Periods {
+		if p == period {
+			return true
+		}
+	}
+	return false
+}
+
+// Start returns the start of the bucket containing t, in UTC. Weeks start on
+// Monday.
+func (p Period) Start(t time.Time) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	switch p {
+	case Hour:
+		return t.Truncate(time.Hour)
+	case Week:
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	case Month:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+	return day
+}
+
+// Next returns the start of the bucket after the one starting at start.
+func (p Period) Next(start time.Time) time.Time {
+	switch p {
+	case Hour:
+		return start.Add(time.Hour)
+	case Week:
+		return start.AddDate(0, 0, 7)
+	case Month:
+		return start.AddDate(0, 1, 0)
+	}
+	return start.AddDate(0, 0, 1)
+}
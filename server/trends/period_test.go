@@ -0,0 +1,36 @@
+package trends
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPeriodStart(t *testing.T) {
+	// A Sunday evening in UTC+2
+	at := time.Date(2025, 3, 16, 23, 30, 0, 0, time.FixedZone("", 2*60*60))
+
+	tests := []struct {
+		period Period
+		want   time.Time
+	}{
+		{Hour, time.Date(2025, 3, 16, 21, 0, 0, 0, time.UTC)},
+		{Day, time.Date(2025, 3, 16, 0, 0, 0, 0, time.UTC)},
+		{Week, time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)},
+		{Month, time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		if got := tt.period.Start(at); !got.Equal(tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.period, got, tt.want)
+		}
+	}
+}
+
+func TestPeriodNext(t *testing.T) {
+	start := time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC)
+	if got := Month.Next(Month.Start(start)); !got.Equal(time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("got %v", got)
+	}
+	if got := Week.Next(Week.Start(start)); !got.Equal(time.Date(2025, 2, 3, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("got %v", got)
+	}
+}
@@ -0,0 +1,148 @@
+package trends
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/saqibroy/web-crawler-dashboard/server/models"
+	"github.com/saqibroy/web-crawler-dashboard/server/repository"
+)
+
+// MaxBuckets limits how many periods a series can span.
+const MaxBuckets = 1000
+
+var ErrTooManyBuckets = errors.New("too many buckets")
+
+// Directions in which a site's broken links are heading.
+const (
+	Improving = "improving"
+	Worsening = "worsening"
+	Stable    = "stable"
+)
+
+// Query selects the metrics a series is built from: those of one URL, one
+// domain (without subdomains) or, with neither, every analysis, recorded
+// between From and To.
+type Query struct {
+	URL      string
+	Domain   string
+	Period   Period
+	From, To time.Time
+}
+
+// Series is a metric trend with a bucket for every period between From and
+// To, including periods without samples.
+type Series struct {
+	Period  Period    `json:"period"`
+	From    time.Time `json:"from"`
+	To      time.Time `json:"to"`
+	Buckets []Bucket  `json:"buckets"`
+	// Direction compares the average broken links of the first and last
+	// buckets with samples. It's empty with fewer than two such buckets.
+	Direction string `json:"direction"`
+}
+
+// Bucket summarises the samples recorded in one period. Its values are nil
+// when the period has no samples, and the durations when none of its samples
+// measured them.
+type Bucket struct {
+	Start            time.Time `json:"start"`
+	Samples          int       `json:"samples"`
+	AvgBrokenLinks   *float64  `json:"avg_broken_links"`
+	MaxBrokenLinks   *int      `json:"max_broken_links"`
+	AvgInternalLinks *float64  `json:"avg_internal_links"`
+	AvgExternalLinks *float64  `json:"avg_external_links"`
+	AvgCrawlMs       *float64  `json:"avg_crawl_ms"`
+	AvgPageLoadMs    *float64  `json:"avg_page_load_ms"`
+}
+
+// totals accumulates the samples of one bucket.
+type totals struct {
+	samples                                   int
+	brokenLinks, internalLinks, externalLinks int
+	maxBrokenLinks                            int
+	crawlMs, pageLoadMs                       int64
+	crawlSamples, pageLoadSamples             int
+}
+
+// Build reads the metrics matching q and buckets them. It returns
+// ErrTooManyBuckets when the range spans more than MaxBuckets periods.
+func Build(ctx context.Context, analyses repository.AnalysisRepository, q Query) (*Series, error) {
+	series := &Series{Period: q.Period, From: q.From, To: q.To, Buckets: []Bucket{}}
+	index := make(map[time.Time]int)
+	for start := q.Period.Start(q.From); !start.After(q.To); start = q.Period.Next(start) {
+		if len(series.Buckets) == MaxBuckets {
+			return nil, ErrTooManyBuckets
+		}
+		index[start] = len(series.Buckets)
+		series.Buckets = append(series.Buckets, Bucket{Start: start})
+	}
+
+	sums := make([]totals, len(series.Buckets))
+	filter := repository.MetricFilter{URL: q.URL, Domain: q.Domain, From: q.From, To: q.To}
+	err := analyses.Metrics(ctx, filter, func(m *models.AnalysisMetric) error {
+		i, ok := index[q.Period.Start(m.RecordedAt)]
+		if !ok {
+			return nil
+		}
+		t := &sums[i]
+		t.samples++
+		t.brokenLinks += m.BrokenLinks
+		t.internalLinks += m.InternalLinks
+		t.externalLinks += m.ExternalLinks
+		t.maxBrokenLinks = max(t.maxBrokenLinks, m.BrokenLinks)
+		if m.CrawlMs != nil {
+			t.crawlMs += *m.CrawlMs
+			t.crawlSamples++
+		}
+		if m.PageLoadMs != nil {
+			t.pageLoadMs += *m.PageLoadMs
+			t.pageLoadSamples++
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var first, last *float64
+	for i, t := range sums {
+		if t.samples == 0 {
+			continue
+		}
+		b := &series.Buckets[i]
+		b.Samples = t.samples
+		b.AvgBrokenLinks = average(int64(t.brokenLinks), t.samples)
+		b.MaxBrokenLinks = &t.maxBrokenLinks
+		b.AvgInternalLinks = average(int64(t.internalLinks), t.samples)
+		b.AvgExternalLinks = average(int64(t.externalLinks), t.samples)
+		b.AvgCrawlMs = average(t.crawlMs, t.crawlSamples)
+		b.AvgPageLoadMs = average(t.pageLoadMs, t.pageLoadSamples)
+
+		if first == nil {
+			first = b.AvgBrokenLinks
+		} else {
+			last = b.AvgBrokenLinks
+		}
+	}
+	if last != nil {
+		switch {
+		case *last < *first:
+			series.Direction = Improving
+		case *last > *first:
+			series.Direction = Worsening
+		default:
+			series.Direction = Stable
+		}
+	}
+	return series, nil
+}
+
+func average(sum int64, n int) *float64 {
+	if n == 0 {
+		return nil
+	}
+	avg := float64(sum) / float64(n)
+	return &avg
+}
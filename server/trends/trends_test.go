@@ -0,0 +1,84 @@
+package trends
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/saqibroy/web-crawler-dashboard/server/models"
+	"github.com/saqibroy/web-crawler-dashboard/server/repository"
+)
+
+func TestBuild(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryAnalysisRepository()
+	day := func(d int) time.Time { return time.Date(2025, 3, d, 12, 0, 0, 0, time.UTC) }
+	ms := func(n int64) *int64 { return &n }
+
+	record := func(url string, metric models.AnalysisMetric) {
+		a := &models.Analysis{URL: url, Status: models.Queued}
+		if err := repo.Create(ctx, a); err != nil {
+			t.Fatal(err)
+		}
+		a.Status = models.Completed
+		a.Metric = &metric
+		if err := repo.Save(ctx, a); err != nil {
+			t.Fatal(err)
+		}
+	}
+	record("https://example.com", models.AnalysisMetric{RecordedAt: day(1), BrokenLinks: 4, InternalLinks: 10, CrawlMs: ms(100)})
+	record("https://example.com", models.AnalysisMetric{RecordedAt: day(1), BrokenLinks: 2, InternalLinks: 20})
+	record("https://example.com", models.AnalysisMetric{RecordedAt: day(3), BrokenLinks: 1, ExternalLinks: 3, PageLoadMs: ms(50)})
+	// Other pages and earlier samples are left out
+	record("https://example.com/other", models.AnalysisMetric{RecordedAt: day(2), BrokenLinks: 100})
+	record("https://example.com", models.AnalysisMetric{RecordedAt: time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC), BrokenLinks: 100})
+
+	series, err := Build(ctx, repo, Query{
+		URL:    "https://example.com",
+		Period: Day,
+		From:   time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
+		To:     time.Date(2025, 3, 3, 23, 59, 59, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(series.Buckets) != 3 || !series.Buckets[1].Start.Equal(time.Date(2025, 3, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected a bucket per day, got %+v", series.Buckets)
+	}
+
+	first := series.Buckets[0]
+	if first.Samples != 2 || *first.AvgBrokenLinks != 3 || *first.MaxBrokenLinks != 4 || *first.AvgInternalLinks != 15 ||
+		*first.AvgCrawlMs != 100 || first.AvgPageLoadMs != nil {
+		t.Errorf("unexpected bucket: %+v", first)
+	}
+	if empty := series.Buckets[1]; empty.Samples != 0 || empty.AvgBrokenLinks != nil || empty.MaxBrokenLinks != nil {
+		t.Errorf("expected an empty bucket, got %+v", empty)
+	}
+	if last := series.Buckets[2]; last.Samples != 1 || *last.AvgExternalLinks != 3 || *last.AvgPageLoadMs != 50 || last.AvgCrawlMs != nil {
+		t.Errorf("unexpected bucket: %+v", last)
+	}
+	if series.Direction != Improving {
+		t.Errorf("expected %s, got %q", Improving, series.Direction)
+	}
+
+	series, err = Build(ctx, repo, Query{Domain: "example.com", Period: Week, From: day(1), To: day(2)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(series.Buckets) != 1 || series.Buckets[0].Samples != 3 || series.Direction != "" {
+		t.Errorf("unexpected series: %+v", series)
+	}
+}
+
+func TestBuildTooManyBuckets(t *testing.T) {
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	_, err := Build(context.Background(), repository.NewMemoryAnalysisRepository(), Query{
+		Period: Hour,
+		From:   from,
+		To:     from.Add(MaxBuckets * time.Hour),
+	})
+	if !errors.Is(err, ErrTooManyBuckets) {
+		t.Errorf("expected ErrTooManyBuckets, got %v", err)
+	}
+}
@@ -2,73 +2,146 @@ package worker
 
 import (
 	"context"
+	"errors"
 	"log"
+	"sync"
 	"time"
 
-	"github.com/saqibroy/web-crawler-dashboard/server/db"
+	"github.com/saqibroy/web-crawler-dashboard/server/auth"
 	"github.com/saqibroy/web-crawler-dashboard/server/models"
+	"github.com/saqibroy/web-crawler-dashboard/server/repository"
 	"github.com/saqibroy/web-crawler-dashboard/server/services"
 )
 
-var cancelFuncs = make(map[string]context.CancelFunc)
+// Worker crawls queued analyses one at a time.
+type Worker struct {
+	analyses repository.AnalysisRepository
+	onFinish []func(context.Context, *models.Analysis)
 
-func StopAnalysis(id string) {
-	if cancel, ok := cancelFuncs[id]; ok {
+	mu          sync.Mutex
+	cancelFuncs map[string]context.CancelFunc
+}
+
+func New(analyses repository.AnalysisRepository) *Worker {
+	return &Worker{analyses: analyses, cancelFuncs: make(map[string]context.CancelFunc)}
+}
+
+// OnFinish registers fn to be called with every analysis that completes or
+// fails, once it's saved.
+func (w *Worker) OnFinish(fn func(ctx context.Context, analysis *models.Analysis)) {
+	w.onFinish = append(w.onFinish, fn)
+}
+
+// StopAnalysis cancels the crawl of an analysis if it is running.
+func (w *Worker) StopAnalysis(id string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if cancel, ok := w.cancelFuncs[id]; ok {
 		cancel()
-		delete(cancelFuncs, id)
+		delete(w.cancelFuncs, id)
 	}
 }
 
-func StartWorker() {
+// Start processes queued analyses in the background.
+func (w *Worker) Start() {
 	go func() {
 		for {
-			analysis := getNextQueuedAnalysis()
-			if analysis == nil {
+			if !w.ProcessNext(context.Background()) {
 				time.Sleep(5 * time.Second)
 				continue
 			}
-
-			if analysis.Status == models.Cancelled {
-				continue
-			}
-
-			processAnalysis(analysis)
 			time.Sleep(1 * time.Second)
 		}
 	}()
 }
 
-func getNextQueuedAnalysis() *models.Analysis {
-	var analysis models.Analysis
-	result := db.DB.Where("status = ?", "queued").First(&analysis)
-	if result.Error != nil {
-		if result.Error.Error() != "record not found" {
-			log.Printf("DB error: %v", result.Error)
-		}
-		return nil
+// ProcessNext claims and crawls the next queued analysis. It returns false
+// when nothing is queued.
+func (w *Worker) ProcessNext(ctx context.Context) bool {
+	analysis, err := w.analyses.ClaimNext(ctx)
+	if err != nil {
+		log.Printf("DB error: %v", err)
+		return false
+	}
+	if analysis == nil {
+		return false
 	}
-	return &analysis
+
+	w.process(ctx, analysis)
+	return true
 }
 
-func processAnalysis(analysis *models.Analysis) {
-	analysis.MarkAsProcessing(db.DB)
+func (w *Worker) process(ctx context.Context, analysis *models.Analysis) {
+	request, err := requestConfig(analysis)
+	if err != nil {
+		log.Printf("Crawl failed for %s: %v", analysis.URL, err)
+		analysis.MarkAsFailed(services.FailureInvalidConfig, err.Error())
+		w.save(analysis)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	w.mu.Lock()
+	w.cancelFuncs[analysis.ID] = cancel
+	w.mu.Unlock()
 
-	ctx, cancel := context.WithCancel(context.Background())
-	cancelFuncs[analysis.ID] = cancel
+	result, err := services.Crawl(ctx, analysis.URL, services.Options{
+		RenderMode:      analysis.RenderMode,
+		Analyzers:       analysis.Analyzers,
+		ExtractionRules: analysis.ExtractionRules,
+		Request:         request,
+	})
 
-	result, err := services.Crawl(ctx, analysis.URL)
-	delete(cancelFuncs, analysis.ID)
+	w.mu.Lock()
+	delete(w.cancelFuncs, analysis.ID)
+	w.mu.Unlock()
 
 	if err != nil {
-		if err == context.Canceled {
-			analysis.MarkAsCancelled(db.DB)
+		if errors.Is(err, context.Canceled) {
+			analysis.MarkAsCancelled()
 			log.Printf("Analysis %s cancelled", analysis.ID)
 		} else {
 			log.Printf("Crawl failed for %s: %v", analysis.URL, err)
-			analysis.MarkAsFailed(db.DB)
+			// Keep the chain so the dashboard can show where the redirects went
+			var redirectErr *services.RedirectError
+			if errors.As(err, &redirectErr) {
+				analysis.Redirects = redirectErr.Chain
+				analysis.RedirectWarnings = redirectErr.Warnings
+			}
+			analysis.MarkAsFailed(services.FailureReason(err), err.Error())
 		}
+		w.save(analysis)
 		return
 	}
 
-	analysis.MarkAsCompleted(db.DB, result)
+	analysis.MarkAsCompleted(result)
+	w.save(analysis)
+}
+
+// save uses a fresh context, as the crawl's own is cancelled when it is
+// stopped.
+func (w *Worker) save(analysis *models.Analysis) {
+	if err := w.analyses.Save(context.Background(), analysis); err != nil {
+		log.Printf("Failed to save analysis %s: %v", analysis.ID, err)
+		return
+	}
+	if analysis.Status == models.Completed || analysis.Status == models.Failed {
+		for _, fn := range w.onFinish {
+			fn(context.Background(), analysis)
+		}
+	}
+}
+
+// requestConfig decrypts the analysis's stored credentials and combines them
+// with its fetch settings and login recipe.
+func requestConfig(analysis *models.Analysis) (*services.RequestConfig, error) {
+	var creds models.FetchCredentials
+	if analysis.FetchCredentials != "" {
+		if err := auth.DecryptJSON(analysis.FetchCredentials, &creds); err != nil {
+			return nil, err
+		}
+	}
+	return services.NewRequestConfig(analysis.FetchConfig, analysis.LoginRecipe, creds), nil
 }
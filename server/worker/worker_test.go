@@ -0,0 +1,163 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/saqibroy/web-crawler-dashboard/server/models"
+	"github.com/saqibroy/web-crawler-dashboard/server/repository"
+)
+
+func crawlTarget(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `<!DOCTYPE html><html lang="en"><head><title>Target</title></head>
+<body><h1>Hello</h1><a href="/about">About</a><a href="/missing">Missing</a></body></html>`)
+	})
+	mux.HandleFunc("/about", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "<html><body>About</body></html>")
+	})
+	mux.HandleFunc("/error", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func queue(t *testing.T, repo repository.AnalysisRepository, url string) *models.Analysis {
+	t.Helper()
+	a := &models.Analysis{URL: url, Status: models.Queued, RenderMode: "http"}
+	if err := repo.Create(context.Background(), a); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	return a
+}
+
+func TestProcessNextCompletesAnalysis(t *testing.T) {
+	target := crawlTarget(t)
+	repo := repository.NewMemoryAnalysisRepository()
+	a := queue(t, repo, target.URL)
+
+	w := New(repo)
+	if !w.ProcessNext(context.Background()) {
+		t.Fatal("expected a queued analysis to be processed")
+	}
+
+	got, err := repo.Get(context.Background(), a.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != models.Completed || got.CompletedAt == nil {
+		t.Fatalf("expected completed, got %s", got.Status)
+	}
+	if got.Title != "Target" || got.HTMLVersion != "HTML5" {
+		t.Errorf("unexpected title %q and version %q", got.Title, got.HTMLVersion)
+	}
+	if got.InternalLinks != 2 || len(got.BrokenLinks) != 1 {
+		t.Errorf("expected 2 internal links with 1 broken, got %d and %v", got.InternalLinks, got.BrokenLinks)
+	}
+	if len(got.Results) == 0 {
+		t.Error("expected analyzer results to be saved")
+	}
+
+	if w.ProcessNext(context.Background()) {
+		t.Error("expected nothing left to process")
+	}
+}
+
+func TestProcessNextMarksFailures(t *testing.T) {
+	target := crawlTarget(t)
+	repo := repository.NewMemoryAnalysisRepository()
+	a := queue(t, repo, target.URL+"/error")
+
+	New(repo).ProcessNext(context.Background())
+
+	got, _ := repo.Get(context.Background(), a.ID)
+	if got.Status != models.Failed {
+		t.Errorf("expected failed, got %s", got.Status)
+	}
+	if got.FailureReason != "http_500" || got.ErrorMessage != "non-200 status code 500" {
+		t.Errorf("unexpected failure %q: %q", got.FailureReason, got.ErrorMessage)
+	}
+
+	// Refused connections are told apart from other errors
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+	b := queue(t, repo, closed.URL)
+	New(repo).ProcessNext(context.Background())
+	if got, _ := repo.Get(context.Background(), b.ID); got.FailureReason != "connection_refused" {
+		t.Errorf("expected connection_refused, got %q", got.FailureReason)
+	}
+}
+
+func TestProcessNextFailsOnUndecryptableCredentials(t *testing.T) {
+	repo := repository.NewMemoryAnalysisRepository()
+	a := &models.Analysis{URL: "http://127.0.0.1:1", Status: models.Queued, FetchCredentials: "not-sealed"}
+	if err := repo.Create(context.Background(), a); err != nil {
+		t.Fatal(err)
+	}
+
+	New(repo).ProcessNext(context.Background())
+
+	got, _ := repo.Get(context.Background(), a.ID)
+	if got.Status != models.Failed || got.FailureReason != "invalid_config" {
+		t.Errorf("expected failed with invalid_config, got %s (%q)", got.Status, got.FailureReason)
+	}
+}
+
+func TestOnFinishCalledAfterSave(t *testing.T) {
+	target := crawlTarget(t)
+	repo := repository.NewMemoryAnalysisRepository()
+	queue(t, repo, target.URL)
+	queue(t, repo, target.URL+"/error")
+
+	w := New(repo)
+	var finished []models.AnalysisStatus
+	w.OnFinish(func(ctx context.Context, a *models.Analysis) {
+		stored, err := repo.Get(ctx, a.ID)
+		if err != nil || stored.Status != a.Status {
+			t.Errorf("expected the analysis to be saved first, got %+v (%v)", stored, err)
+		}
+		finished = append(finished, a.Status)
+	})
+	for w.ProcessNext(context.Background()) {
+	}
+
+	if len(finished) != 2 || finished[0] != models.Completed || finished[1] != models.Failed {
+		t.Errorf("unexpected finished analyses: %v", finished)
+	}
+}
+
+func TestStopAnalysisCancelsCrawl(t *testing.T) {
+	started := make(chan struct{})
+	var once sync.Once
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		once.Do(func() { close(started) })
+		<-r.Context().Done()
+	}))
+	defer target.Close()
+
+	repo := repository.NewMemoryAnalysisRepository()
+	a := queue(t, repo, target.URL)
+	w := New(repo)
+
+	go func() {
+		<-started
+		w.StopAnalysis(a.ID)
+	}()
+	w.ProcessNext(context.Background())
+
+	got, _ := repo.Get(context.Background(), a.ID)
+	if got.Status != models.Cancelled {
+		t.Errorf("expected cancelled, got %s", got.Status)
+	}
+}